@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+	"github.com/renlin-code/mock-shop-api/pkg/service"
+	"github.com/renlin-code/mock-shop-api/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// app holds the dependencies shared by every command.
+type app struct {
+	db       *sqlx.DB
+	repos    *repository.Repository
+	services *service.Service
+}
+
+func dbConfig() repository.Config {
+	return repository.Config{
+		Host:     os.Getenv("POSTGRES_HOST"),
+		Port:     os.Getenv("POSTGRES_PORT"),
+		Username: os.Getenv("POSTGRES_USERNAME"),
+		Password: os.Getenv("POSTGRES_PASSWORD"),
+		DBName:   os.Getenv("POSTGRES_NAME"),
+		SSLMode:  os.Getenv("POSTGRES_SSLMODE"),
+	}
+}
+
+func storageConfig() storage.Config {
+	return storage.Config{
+		MediaBaseUrl: os.Getenv("APP_MEDIA_BASE_URL"),
+	}
+}
+
+func openDB() (*sqlx.DB, error) {
+	db, err := repository.NewPostgresDB(dbConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	return db, nil
+}
+
+func newApp() (*app, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+
+	storage := storage.NewStorage(storage.NewFileSystemStorage(storageConfig()))
+	repos := repository.NewRepository(db, storage)
+
+	services, err := service.NewService(repos)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize services: %w", err)
+	}
+
+	return &app{
+		db:       db,
+		repos:    repos,
+		services: services,
+	}, nil
+}
+
+func (a *app) close() {
+	if err := a.db.Close(); err != nil {
+		logrus.Errorf("Error occurred on db connection while closing: %s", err.Error())
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// startPeriodicJob runs job in the background every interval set in the
+// intervalEnv environment variable, a duration such as "1h", until stop is
+// closed. Nothing is started when the variable is not set, the job command
+// can be run by cron instead.
+func startPeriodicJob(name, intervalEnv string, stop <-chan struct{}, job func() error) error {
+	value := os.Getenv(intervalEnv)
+	if value == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid %s %q", intervalEnv, value)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := job(); err != nil {
+					logrus.Errorf("Error occurred while running %s: %s", name, err.Error())
+				}
+			}
+		}
+	}()
+	return nil
+}
@@ -1,20 +1,33 @@
 package main
 
 import (
-	"context"
+	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"sort"
 
 	_ "github.com/lib/pq"
 
-	"github.com/renlin-code/mock-shop-api/pkg/handler"
-	"github.com/renlin-code/mock-shop-api/pkg/repository"
-	"github.com/renlin-code/mock-shop-api/pkg/service"
-	"github.com/renlin-code/mock-shop-api/pkg/storage"
 	"github.com/sirupsen/logrus"
 )
 
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"serve":    {"serve                             run the http server", runServe},
+	"migrate":  {"migrate up|down|status            manage the database schema", runMigrate},
+	"seed":     {"seed [-file fixture] [-reset]     load a fixture, the demo catalog by default", runSeed},
+	"user":     {"user create|disable|enable        manage customer accounts", runUser},
+	"admin":    {"admin create                      create or promote an admin account", runAdmin},
+	"token":    {"token issue                       issue a sign in token for debugging", runToken},
+	"media":    {"media gc [-dry-run]               remove media files of deleted entities", runMedia},
+	"wishlist": {"wishlist notify                   email the wishlist price drops and restocks", runWishlist},
+	"related":  {"related refresh                   recompute the products bought together", runRelated},
+	"sales":    {"sales run                         start and end the scheduled sales", runSales},
+}
+
 // @title Mock Shop API
 // @version 1.0
 // @description API Service for a Mock Online Shop
@@ -27,46 +40,33 @@ import (
 // @name Authorization
 func main() {
 	logrus.SetFormatter(new(logrus.JSONFormatter))
-	db, err := repository.NewPostgresDB(repository.Config{
-		Host:     os.Getenv("POSTGRES_HOST"),
-		Port:     os.Getenv("POSTGRES_PORT"),
-		Username: os.Getenv("POSTGRES_USERNAME"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		DBName:   os.Getenv("POSTGRES_NAME"),
-		SSLMode:  os.Getenv("POSTGRES_SSLMODE"),
-	})
-	if err != nil {
-		logrus.Fatalf("Failed to initialize database: %s", err.Error())
-	}
-
-	fsStorage := storage.NewFileSystemStorage(storage.Config{
-		MediaBaseUrl: os.Getenv("APP_MEDIA_BASE_URL"),
-	})
-	storage := storage.NewStorage(fsStorage)
-	repos := repository.NewRepository(db, storage)
-	services := service.NewService(repos)
-	handlers := handler.NewHandler(services)
 
-	srv := new(handler.Server)
-
-	go func() {
-		if err := srv.Run(os.Getenv("APP_PORT"), handlers.InitRoutes()); err != nil {
-			logrus.Fatalf("Error occurred while running http server: %s", err.Error())
-		}
-	}()
+	name := "serve"
+	args := os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
 
-	logrus.Print("App started...")
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
-	<-quit
-	logrus.Print("App shutting down...")
+	cmd, ok := commands[name]
+	if !ok {
+		printUsage()
+		os.Exit(2)
+	}
 
-	if err := srv.ShutDown(context.Background()); err != nil {
-		logrus.Errorf("Error occurred on server while shutting down: %s", err.Error())
+	if err := cmd.run(args); err != nil {
+		logrus.Fatalf("%s: %s", name, err.Error())
 	}
+}
 
-	if err := db.Close(); err != nil {
-		logrus.Errorf("Error occurred on db connection while closing: %s", err.Error())
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [arguments]\n\nCommands:\n", os.Args[0])
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
 }
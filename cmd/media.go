@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+func runMedia(args []string) error {
+	if len(args) == 0 || args[0] != "gc" {
+		return errors.New("expected: gc")
+	}
+
+	flags := flag.NewFlagSet("media gc", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only list the directories that would be removed")
+	flags.Parse(args[1:])
+
+	a, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	removed, err := a.services.Media.CollectGarbage(*dryRun)
+	for _, dir := range removed {
+		if *dryRun {
+			fmt.Printf("would remove %s\n", dir)
+		} else {
+			fmt.Printf("removed %s\n", dir)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		fmt.Println("nothing to remove")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+	"github.com/renlin-code/mock-shop-api/schema"
+)
+
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := flags.String("dir", "", "directory holding the migration files, the embedded ones are used by default")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("expected one of: up, down, status")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var source fs.FS = schema.Migrations
+	if *dir != "" {
+		source = os.DirFS(*dir)
+	}
+	migrator := repository.NewMigrator(db, source)
+
+	switch flags.Arg(0) {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("applied %06d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		migration, reverted, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		if !reverted {
+			fmt.Println("no applied migrations")
+			return nil
+		}
+		fmt.Printf("reverted %06d_%s\n", migration.Version, migration.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%06d_%-30s %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q", flags.Arg(0))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/renlin-code/mock-shop-api/pkg/service"
+	"github.com/sirupsen/logrus"
+)
+
+func runRelated(args []string) error {
+	if len(args) == 0 || args[0] != "refresh" {
+		return errors.New("expected: refresh")
+	}
+
+	a, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	kept, err := a.services.Related.Refresh()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("kept %d related products\n", kept)
+	return nil
+}
+
+// refreshRelatedProducts is the periodic job recomputing the products bought
+// together.
+func refreshRelatedProducts(related service.Related) func() error {
+	return func() error {
+		kept, err := related.Refresh()
+		if err == nil {
+			logrus.Printf("Refreshed related products, kept %d", kept)
+		}
+		return err
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/renlin-code/mock-shop-api/pkg/service"
+	"github.com/sirupsen/logrus"
+)
+
+func runSales(args []string) error {
+	if len(args) == 0 || args[0] != "run" {
+		return errors.New("expected: run")
+	}
+
+	a, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	run, err := a.services.Sale.Run()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("started %d sales, ended %d\n", run.Started, run.Ended)
+	return nil
+}
+
+// runScheduledSales is the periodic job starting and ending the scheduled
+// sales.
+func runScheduledSales(sale service.Sale) func() error {
+	return func() error {
+		run, err := sale.Run()
+		if err == nil && run.Started+run.Ended > 0 {
+			logrus.Printf("Started %d sales, ended %d", run.Started, run.Ended)
+		}
+		return err
+	}
+}
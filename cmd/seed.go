@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/renlin-code/mock-shop-api/fixtures"
+	"github.com/renlin-code/mock-shop-api/pkg/seed"
+)
+
+// runSeed applies a fixture, the bundled demo catalog by default.
+func runSeed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	file := flags.String("file", "", "YAML or JSON fixture to load instead of the demo catalog")
+	reset := flags.Bool("reset", false, "remove every user, category, product and order before seeding")
+	flags.Parse(args)
+
+	var fsys fs.FS = fixtures.Files
+	name := "demo.yaml"
+	if *file != "" {
+		fsys = os.DirFS(filepath.Dir(*file))
+		name = filepath.Base(*file)
+	}
+
+	fixture, err := seed.Load(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	a, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	loader := seed.NewLoader(a.services)
+	if *reset {
+		if err := loader.Reset(); err != nil {
+			return err
+		}
+		fmt.Println("removed existing data")
+	}
+
+	result, err := loader.Apply(fixture)
+	fmt.Println(result)
+	return err
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/renlin-code/mock-shop-api/pkg/handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	port := flags.String("port", os.Getenv("APP_PORT"), "port to listen on")
+	migrate := flags.Bool("migrate", os.Getenv("APP_AUTO_MIGRATE") == "true", "apply pending migrations before starting")
+	flags.Parse(args)
+
+	a, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	if *migrate {
+		applied, err := a.repos.Schema.Up()
+		for _, migration := range applied {
+			logrus.Printf("Applied migration %06d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := checkSchemaVersion(a.repos.Schema); err != nil {
+		return err
+	}
+
+	// The import jobs left running by a previous server are never finished.
+	expired, err := a.services.Import.ExpireJobs()
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		logrus.Printf("Failed %d interrupted import jobs", expired)
+	}
+
+	handlers := handler.NewHandler(a.services)
+
+	srv := new(handler.Server)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := startPeriodicJob("wishlist notify", "WISHLIST_NOTIFY_INTERVAL", stop, notifyWishlistChanges(a.services.Wishlist)); err != nil {
+		return err
+	}
+	if err := startPeriodicJob("related refresh", "RELATED_REFRESH_INTERVAL", stop, refreshRelatedProducts(a.services.Related)); err != nil {
+		return err
+	}
+	if err := startPeriodicJob("sales run", "SALES_RUN_INTERVAL", stop, runScheduledSales(a.services.Sale)); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := srv.Run(*port, handlers.InitRoutes()); err != nil {
+			logrus.Fatalf("Error occurred while running http server: %s", err.Error())
+		}
+	}()
+
+	logrus.Print("App started...")
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
+	<-quit
+	logrus.Print("App shutting down...")
+
+	if err := srv.ShutDown(context.Background()); err != nil {
+		logrus.Errorf("Error occurred on server while shutting down: %s", err.Error())
+	}
+
+	return nil
+}
+
+// checkSchemaVersion refuses to start against a database migrated by a newer
+// binary and warns when migrations are pending.
+func checkSchemaVersion(schema repository.Schema) error {
+	version, err := schema.Version()
+	if err != nil {
+		return err
+	}
+	latest, err := schema.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	if version > latest {
+		return fmt.Errorf("%w: database is at version %d, binary supports up to %d", repository.ErrSchemaAhead, version, latest)
+	}
+	if version < latest {
+		logrus.Warnf("Database schema is at version %d, latest is %d. Run \"migrate up\" or set APP_AUTO_MIGRATE=true", version, latest)
+	}
+	return nil
+}
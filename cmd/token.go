@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+func runToken(args []string) error {
+	if len(args) == 0 || args[0] != "issue" {
+		return errors.New("expected: issue")
+	}
+
+	flags := flag.NewFlagSet("token issue", flag.ExitOnError)
+	email := flags.String("email", "", "e-mail of the user the token is issued for")
+	flags.Parse(args[1:])
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	a, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	token, err := a.services.Authorization.IssueAuthToken(*email)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+func runUser(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected one of: create, disable, enable")
+	}
+
+	switch args[0] {
+	case "create":
+		input, err := parseCreateUserFlags("user create", args[1:])
+		if err != nil {
+			return err
+		}
+		a, err := newApp()
+		if err != nil {
+			return err
+		}
+		defer a.close()
+
+		id, err := a.services.Authorization.RegisterUser(input)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created user %d\n", id)
+	case "disable", "enable":
+		flags := flag.NewFlagSet("user "+args[0], flag.ExitOnError)
+		email := flags.String("email", "", "user e-mail")
+		flags.Parse(args[1:])
+		if *email == "" {
+			return errors.New("-email is required")
+		}
+
+		a, err := newApp()
+		if err != nil {
+			return err
+		}
+		defer a.close()
+
+		if err := a.services.Authorization.SetUserDisabled(*email, args[0] == "disable"); err != nil {
+			return err
+		}
+		fmt.Printf("user %s %sd\n", *email, args[0])
+	default:
+		return fmt.Errorf("unknown user command %q", args[0])
+	}
+	return nil
+}
+
+func runAdmin(args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return errors.New("expected: create")
+	}
+
+	flags := flag.NewFlagSet("admin create", flag.ExitOnError)
+	name := flags.String("name", "", "admin name, required when the account does not exist")
+	email := flags.String("email", "", "admin e-mail")
+	password := flags.String("password", "", "admin password, required when the account does not exist")
+	flags.Parse(args[1:])
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	a, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	_, err = a.repos.Authorization.GetUserByEmail(*email)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		input := domain.CreateUserInput{Name: *name, Email: *email, Password: *password}
+		if err := input.Validate(); err != nil {
+			return err
+		}
+		if _, err := a.services.Authorization.RegisterUser(input); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if err := a.services.Authorization.GrantAdmin(*email); err != nil {
+		return err
+	}
+	fmt.Printf("user %s is now an admin\n", *email)
+	return nil
+}
+
+func parseCreateUserFlags(name string, args []string) (domain.CreateUserInput, error) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	var input domain.CreateUserInput
+	flags.StringVar(&input.Name, "name", "", "user name")
+	flags.StringVar(&input.Email, "email", "", "user e-mail")
+	flags.StringVar(&input.Password, "password", "", "user password")
+	flags.Parse(args)
+
+	return input, input.Validate()
+}
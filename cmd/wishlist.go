@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/renlin-code/mock-shop-api/pkg/service"
+	"github.com/sirupsen/logrus"
+)
+
+func runWishlist(args []string) error {
+	if len(args) == 0 || args[0] != "notify" {
+		return errors.New("expected: notify")
+	}
+
+	a, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	sent, err := a.services.Wishlist.NotifyChanges()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("notified %d users\n", sent)
+	return nil
+}
+
+// notifyWishlistChanges is the periodic job emailing the wishlist changes.
+func notifyWishlistChanges(wishlist service.Wishlist) func() error {
+	return func() error {
+		sent, err := wishlist.NotifyChanges()
+		if err == nil && sent > 0 {
+			logrus.Printf("Notified wishlist changes to %d users", sent)
+		}
+		return err
+	}
+}
@@ -0,0 +1,9 @@
+// Package fixtures embeds the bundled seed fixtures into the binary.
+package fixtures
+
+import "embed"
+
+// Files holds the bundled fixtures, demo.yaml being the default demo catalog.
+//
+//go:embed *.yaml
+var Files embed.FS
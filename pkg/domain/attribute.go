@@ -0,0 +1,78 @@
+package domain
+
+// Attribute types. Values of every type are stored as text, numbers and
+// booleans in a canonical form so they can be compared and counted.
+const (
+	AttributeText    = "text"
+	AttributeNumber  = "number"
+	AttributeBoolean = "boolean"
+)
+
+var attributeTypes = []interface{}{AttributeText, AttributeNumber, AttributeBoolean}
+
+// Attribute is a specification (material, weight, brand...) the products of
+// a category can have a value for.
+type Attribute struct {
+	Id         int    `json:"id" db:"id"`
+	CategoryId int    `json:"category_id" db:"category_id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Unit       string `json:"unit"`
+}
+
+type ProductAttribute struct {
+	AttributeId int    `json:"attribute_id" db:"attribute_id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Unit        string `json:"unit"`
+	Value       string `json:"value"`
+}
+
+// Facets counts the products of a listing by attribute value, price range,
+// category and availability. Each count ignores the filter on its own facet,
+// so the other values remain selectable.
+type Facets struct {
+	Attributes   []Facet           `json:"attributes"`
+	Price        []PriceBucket     `json:"price"`
+	Categories   []CategoryCount   `json:"categories"`
+	Availability AvailabilityCount `json:"availability"`
+}
+
+// PriceBucket counts the products priced from Min (included) to Max
+// (excluded). The last bucket has no Max.
+type PriceBucket struct {
+	Min   Money  `json:"min" swaggertype:"number"`
+	Max   *Money `json:"max" swaggertype:"number"`
+	Count int    `json:"count"`
+}
+
+type CategoryCount struct {
+	Id    int    `json:"id" db:"id"`
+	Name  string `json:"name" db:"name"`
+	Count int    `json:"count" db:"count"`
+}
+
+type AvailabilityCount struct {
+	InStock    int `json:"in_stock" db:"in_stock"`
+	OutOfStock int `json:"out_of_stock" db:"out_of_stock"`
+}
+
+// Facet counts the products of a listing having each value of an attribute.
+type Facet struct {
+	Attribute string       `json:"attribute"`
+	Values    []FacetValue `json:"values"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// ProductFilter narrows and orders the product listings. Attributes maps an
+// attribute name to the accepted values: a product matches when it has one of
+// the values for every attribute.
+type ProductFilter struct {
+	ProductFilterParams
+	Search     string
+	Attributes map[string][]string
+}
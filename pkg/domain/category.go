@@ -1,9 +1,32 @@
 package domain
 
+import "time"
+
 type Category struct {
 	Id          int    `json:"id"`
+	ParentId    *int   `json:"parent_id" db:"parent_id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Available   bool   `json:"available"`
 	ImageUrl    string `json:"image_url" db:"image_url"`
+	// TaxClassId is the tax class of the products of the category without
+	// one of their own.
+	TaxClassId *int `json:"tax_class_id,omitempty" db:"tax_class_id"`
+	// ArchivedAt is set when the category is archived, archived categories
+	// are hidden from the shop.
+	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs,omitempty" db:"-"`
+}
+
+// Breadcrumb is a category on the path from a top level category.
+type Breadcrumb struct {
+	Id   int    `json:"id" db:"id"`
+	Name string `json:"name"`
+}
+
+// CategoryNode is a category of the category tree with its subcategories.
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
 }
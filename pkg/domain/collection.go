@@ -0,0 +1,10 @@
+package domain
+
+// Collection is a curated list of products ("Summer picks", "Gifts under
+// 20"...), shown in the order set by the admins.
+type Collection struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Available   bool   `json:"available"`
+}
@@ -0,0 +1,221 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// rateDecimals is the precision of the exchange rates, stored as
+// NUMERIC(18, 8).
+const rateDecimals = 8
+
+const rateScale = 1e8
+
+// maxRate is the largest rate a NUMERIC(18, 8) column holds.
+const maxRate Rate = 1e18 - 1
+
+// OneRate converts the store currency to itself.
+const OneRate Rate = rateScale
+
+// Rate is an exact exchange rate with eight decimals: the amount of a
+// currency one unit of the store currency is worth. 0.92 is Rate(92000000).
+type Rate int64
+
+// ParseRate parses a decimal rate with at most eight decimals, such as
+// "0.92150000", exactly.
+func ParseRate(s string) (Rate, error) {
+	return parseRate(s, false)
+}
+
+func parseRate(s string, round bool) (Rate, error) {
+	r, err := parseDecimal(s, rateDecimals, int64(maxRate), round)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: %w", s, err)
+	}
+	return Rate(r), nil
+}
+
+// String formats the rate with its eight decimals.
+func (r Rate) String() string {
+	return formatDecimal(int64(r), rateDecimals)
+}
+
+// Convert converts an amount of the store currency to the rate currency,
+// rounded half away from zero to the cent.
+func (r Rate) Convert(m Money) Money {
+	return Money(mulDivRound(int64(m), int64(r), rateScale))
+}
+
+// Revert converts an amount of the rate currency back to the store currency,
+// rounded half away from zero to the cent.
+func (r Rate) Revert(m Money) Money {
+	if r == 0 {
+		return m
+	}
+	return Money(mulDivRound(int64(m), rateScale, int64(r)))
+}
+
+// mulDivRound returns a * b / c rounded half away from zero, computed
+// without overflowing.
+func mulDivRound(a, b, c int64) int64 {
+	product := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	divisor := big.NewInt(c)
+	quotient, remainder := new(big.Int).QuoRem(product, divisor, new(big.Int))
+
+	remainder.Abs(remainder).Lsh(remainder, 1)
+	if remainder.Cmp(divisor.Abs(divisor)) >= 0 {
+		if (product.Sign() < 0) != (c < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient.Int64()
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, or a string holding one, without
+// going through a float.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		return nil
+	}
+	return r.UnmarshalText([]byte(value))
+}
+
+// UnmarshalText decodes a decimal rate, from an imported file for example.
+func (r *Rate) UnmarshalText(text []byte) error {
+	parsed, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Scan reads a NUMERIC column, rounded to the eighth decimal.
+func (r *Rate) Scan(src interface{}) error {
+	v, err := scanDecimal(src, rateDecimals, int64(maxRate))
+	if err != nil {
+		return err
+	}
+	*r = Rate(v)
+	return nil
+}
+
+// Value stores the rate as a decimal string, read exactly by a NUMERIC
+// column.
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// rateRule validates an exchange rate: greater than 0 and within the range
+// of a NUMERIC(18, 8) column. See moneyRule for why it is not a Min rule.
+type rateRule struct{}
+
+var validRate = rateRule{}
+
+func (rateRule) Validate(value interface{}) error {
+	r, ok := value.(Rate)
+	if !ok {
+		return errors.New("must be a rate")
+	}
+	if r <= 0 {
+		return errors.New("must be greater than 0")
+	}
+	if r > maxRate {
+		return fmt.Errorf("must be no greater than %s", maxRate)
+	}
+	return nil
+}
+
+// ExchangeRate is the rate the prices are converted with to show them in
+// another currency than the store one.
+type ExchangeRate struct {
+	Currency  string    `json:"currency" db:"currency"`
+	Rate      Rate      `json:"rate" db:"rate" swaggertype:"number"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// StoreExchangeRate is the rate of the store currency, which converts the
+// prices to themselves.
+func StoreExchangeRate(currency string) ExchangeRate {
+	return ExchangeRate{Currency: currency, Rate: OneRate}
+}
+
+// ConvertProduct converts the prices of a product and of its variants.
+func (e ExchangeRate) ConvertProduct(product Product) Product {
+	product.Currency = e.Currency
+	if e.Rate == OneRate {
+		return product
+	}
+	product.Price = e.Rate.Convert(product.Price)
+	product.UndiscountedPrice = e.Rate.Convert(product.UndiscountedPrice)
+	if product.ActiveSale != nil {
+		sale := *product.ActiveSale
+		sale.RegularPrice = e.Rate.Convert(sale.RegularPrice)
+		product.ActiveSale = &sale
+	}
+
+	if product.Variants != nil {
+		variants := make([]ProductVariant, len(product.Variants))
+		for i, variant := range product.Variants {
+			variant.Price = e.Rate.Convert(variant.Price)
+			variant.UndiscountedPrice = e.Rate.Convert(variant.UndiscountedPrice)
+			variants[i] = variant
+		}
+		product.Variants = variants
+	}
+	return product
+}
+
+// ConvertProducts converts the prices of a product listing.
+func (e ExchangeRate) ConvertProducts(products []Product) []Product {
+	for i := range products {
+		products[i] = e.ConvertProduct(products[i])
+	}
+	return products
+}
+
+// ConvertFacets converts the bounds of the price ranges.
+func (e ExchangeRate) ConvertFacets(facets Facets) Facets {
+	if e.Rate == OneRate {
+		return facets
+	}
+	buckets := make([]PriceBucket, len(facets.Price))
+	for i, bucket := range facets.Price {
+		bucket.Min = e.Rate.Convert(bucket.Min)
+		if bucket.Max != nil {
+			max := e.Rate.Convert(*bucket.Max)
+			bucket.Max = &max
+		}
+		buckets[i] = bucket
+	}
+	facets.Price = buckets
+	return facets
+}
+
+// RevertFilter converts the price bounds of a filter, given in the rate
+// currency, to the store currency the products are filtered in.
+func (e ExchangeRate) RevertFilter(filter ProductFilter) ProductFilter {
+	if e.Rate == OneRate {
+		return filter
+	}
+	if filter.MinPrice != nil {
+		min := e.Rate.Revert(*filter.MinPrice)
+		filter.MinPrice = &min
+	}
+	if filter.MaxPrice != nil {
+		max := e.Rate.Revert(*filter.MaxPrice)
+		filter.MaxPrice = &max
+	}
+	return filter
+}
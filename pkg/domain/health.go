@@ -0,0 +1,7 @@
+package domain
+
+type Health struct {
+	Status              string `json:"status"`
+	SchemaVersion       int    `json:"schema_version"`
+	LatestSchemaVersion int    `json:"latest_schema_version"`
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Catalog import and export formats.
+const (
+	CatalogFormatCSV   = "csv"
+	CatalogFormatJSONL = "jsonl"
+	// CatalogFormatZip is an import archive holding a CSV or JSON lines
+	// file with the images it refers to.
+	CatalogFormatZip = "zip"
+)
+
+// Import job statuses.
+const (
+	ImportStatusRunning = "running"
+	ImportStatusDone    = "done"
+	ImportStatusFailed  = "failed"
+)
+
+// ProductRecord is a product row of a catalog import or export. Products are
+// matched by SKU. Image is the path of the product image inside the import
+// archive, ImageUrl is only exported.
+type ProductRecord struct {
+	Sku               string `json:"sku"`
+	CategoryId        int    `json:"category_id" db:"category_id"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	Price             Money  `json:"price"`
+	UndiscountedPrice Money  `json:"undiscounted_price" db:"undiscounted_price"`
+	Stock             int    `json:"stock"`
+	Available         bool   `json:"available"`
+	Image             string `json:"image,omitempty" db:"-"`
+	ImageUrl          string `json:"image_url,omitempty" db:"image_url"`
+}
+
+// Validate validates the record with the rules of CreateProductInput. The
+// image can only be left out when the product exists, it is then kept.
+func (r ProductRecord) Validate(image *multipart.FileHeader, exists bool) error {
+	err := validation.ValidateStruct(&r,
+		validation.Field(&r.Sku, validation.Required, validation.Length(1, skuMaxLength)),
+	)
+	if err != nil {
+		return err
+	}
+
+	input := r.CreateInput(image)
+	if exists && image == nil {
+		err = input.ValidateFields()
+	} else {
+		err = input.Validate()
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.UndiscountedPrice < r.Price {
+		return errors.New("undiscounted_price: must not be less than price")
+	}
+	return nil
+}
+
+// CreateInput returns the product input of the record.
+func (r ProductRecord) CreateInput(image *multipart.FileHeader) CreateProductInput {
+	return CreateProductInput{
+		CategoryId:        r.CategoryId,
+		Sku:               r.Sku,
+		Name:              r.Name,
+		Description:       r.Description,
+		ImgFile:           image,
+		Available:         r.Available,
+		Price:             r.Price,
+		UndiscountedPrice: r.UndiscountedPrice,
+		Stock:             r.Stock,
+	}
+}
+
+// ImportJob is the state of a catalog import, processed in the background.
+type ImportJob struct {
+	Id            int             `json:"id"`
+	Status        string          `json:"status"`
+	Format        string          `json:"format"`
+	DryRun        bool            `json:"dry_run" db:"dry_run"`
+	TotalRows     int             `json:"total_rows" db:"total_rows"`
+	ProcessedRows int             `json:"processed_rows" db:"processed_rows"`
+	CreatedRows   int             `json:"created_rows" db:"created_rows"`
+	UpdatedRows   int             `json:"updated_rows" db:"updated_rows"`
+	FailedRows    int             `json:"failed_rows" db:"failed_rows"`
+	Errors        ImportRowErrors `json:"errors"`
+	// Error is set when the job failed, not because of a row.
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is when the job last recorded its progress, a running job
+	// not updated for long has been interrupted.
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at" db:"finished_at"`
+}
+
+// ImportRowError is the reason a row of an import was rejected. Rows are
+// numbered from 1, the CSV header being row 1.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Sku   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportRowErrors is stored as a JSON array.
+type ImportRowErrors []ImportRowError
+
+func (e ImportRowErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(e)
+	return string(b), err
+}
+
+func (e *ImportRowErrors) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*e = nil
+		return nil
+	default:
+		return errors.New("incompatible type for ImportRowErrors")
+	}
+	return json.Unmarshal(b, e)
+}
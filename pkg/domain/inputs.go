@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"mime/multipart"
+	"path"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
@@ -29,6 +32,46 @@ const (
 	productNameMaxLength        = 100
 	productDescriptionMinLength = 0
 	productDescriptionMaxLength = 200
+
+	maxProductOptions          = 3
+	productOptionNameMaxLength = 50
+	skuMaxLength               = 64
+
+	imageAltTextMaxLength = 255
+
+	attributeNameMaxLength  = 50
+	attributeUnitMaxLength  = 20
+	attributeValueMaxLength = 255
+	maxFilterAttributes     = 10
+	maxFilterValues         = 20
+
+	maxImportFileSize = 50 << 20 //50 MB
+
+	maxExchangeRates         = 200
+	maxExchangeRatesFileSize = 1 << 20 //1 MB
+
+	taxClassNameMaxLength = 50
+	maxTaxClassRates      = 500
+
+	promoCodeMinLength       = 3
+	promoCodeMaxLength       = 50
+	maxPromoCodeRestrictions = 500
+
+	priceScheduleNameMaxLength = 100
+
+	maxProductCategories           = 20
+	collectionNameMaxLength        = 100
+	collectionDescriptionMaxLength = 500
+	maxCollectionProducts          = 500
+
+	minReviewRating     = 1
+	maxReviewRating     = 5
+	reviewTextMaxLength = 2000
+
+	reviewReportReasonMaxLength = 500
+
+	// MaxBulkProducts is the number of products a bulk update can change.
+	MaxBulkProducts = 1000
 )
 
 var allowedFileExtensions = [3]string{"jpg", "jpeg", "png"}
@@ -45,6 +88,20 @@ func (i SignUpInput) Validate() error {
 	)
 }
 
+type CreateUserInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (i CreateUserInput) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Name, validation.Required, validation.Length(userNameMinLength, userNameMaxLength)),
+		validation.Field(&i.Email, validation.Required, is.Email),
+		validation.Field(&i.Password, validation.Required, validation.Length(passwordMinLength, passwordMaxLength)),
+	)
+}
+
 type ConfirmEmailInput struct {
 	Token    string `json:"token"`
 	Password string `json:"password"`
@@ -120,28 +177,50 @@ func (i DeleteProfileInput) Validate() error {
 
 type CreateOrderInput struct {
 	Products []CreateOrderInputProduct `json:"products"`
+	// Currency is the currency the order is placed in, the store currency
+	// when empty.
+	Currency string `json:"currency"`
+	// TaxRegion is the region the order is taxed for, the default region
+	// when empty.
+	TaxRegion string `json:"tax_region"`
+	// PromoCode discounts the order, it is not case sensitive.
+	PromoCode string `json:"promo_code"`
 }
 type CreateOrderInputProduct struct {
-	Id       int `json:"id"`
-	Quantity int `json:"quantity"`
+	Id        int  `json:"id"`
+	VariantId *int `json:"variant_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+// variantKey returns the variant id, or 0 when no variant is ordered.
+func (p CreateOrderInputProduct) variantKey() int {
+	if p.VariantId == nil {
+		return 0
+	}
+	return *p.VariantId
 }
 
 func (i CreateOrderInput) Validate() error {
 	err := validation.ValidateStruct(&i,
 		validation.Field(&i.Products, validation.Required),
+		validation.Field(&i.Currency, validation.Match(currencyCodeRegexp)),
+		validation.Field(&i.TaxRegion, validation.Match(taxRegionRegexp)),
+		validation.Field(&i.PromoCode, validation.Length(0, promoCodeMaxLength)),
 	)
 	if err != nil {
 		return err
 	}
-	uniqueIDs := make(map[int]struct{})
+	uniqueIDs := make(map[[2]int]struct{})
 
 	for _, product := range i.Products {
-		if _, found := uniqueIDs[product.Id]; found {
-			return errors.New("product id must be unique")
+		key := [2]int{product.Id, product.variantKey()}
+		if _, found := uniqueIDs[key]; found {
+			return errors.New("product id and variant id must be unique")
 		}
-		uniqueIDs[product.Id] = struct{}{}
+		uniqueIDs[key] = struct{}{}
 		err := validation.ValidateStruct(&product,
 			validation.Field(&product.Id, validation.Required, validation.Min(1)),
+			validation.Field(&product.VariantId, validation.NilOrNotEmpty, validation.Min(1)),
 			validation.Field(&product.Quantity, validation.Required, validation.Min(1)),
 		)
 		if err != nil {
@@ -153,9 +232,14 @@ func (i CreateOrderInput) Validate() error {
 
 type ById []CreateOrderInputProduct
 
-func (a ById) Len() int           { return len(a) }
-func (a ById) Less(i, j int) bool { return a[i].Id < a[j].Id }
-func (a ById) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a ById) Len() int { return len(a) }
+func (a ById) Less(i, j int) bool {
+	if a[i].Id != a[j].Id {
+		return a[i].Id < a[j].Id
+	}
+	return a[i].variantKey() < a[j].variantKey()
+}
+func (a ById) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 
 func (i *CreateOrderInput) Sort() {
 	sort.Sort(ById(i.Products))
@@ -180,6 +264,7 @@ func validateFile(file *multipart.FileHeader, maxSize int64, allowedExtensions [
 }
 
 type CreateCategoryInput struct {
+	ParentId    *int                  `json:"parent_id"`
 	Name        string                `json:"name"`
 	Description string                `json:"description"`
 	ImgFile     *multipart.FileHeader `json:"image_file"`
@@ -187,10 +272,7 @@ type CreateCategoryInput struct {
 }
 
 func (i CreateCategoryInput) Validate() error {
-	err := validation.ValidateStruct(&i,
-		validation.Field(&i.Name, validation.Required, validation.Length(categoryNameMinLength, categoryNameMaxLength)),
-		validation.Field(&i.Description, validation.Length(categoryDescriptionMinLength, categoryDescriptionMaxLength)),
-	)
+	err := i.ValidateFields()
 	if err != nil {
 		return err
 	}
@@ -206,7 +288,19 @@ func (i CreateCategoryInput) Validate() error {
 	return nil
 }
 
+// ValidateFields validates every field but the image file.
+func (i CreateCategoryInput) ValidateFields() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.ParentId, validation.Min(1)),
+		validation.Field(&i.Name, validation.Required, validation.Length(categoryNameMinLength, categoryNameMaxLength)),
+		validation.Field(&i.Description, validation.Length(categoryDescriptionMinLength, categoryDescriptionMaxLength)),
+	)
+}
+
+// UpdateCategoryInput changes a category. A ParentId of 0 moves the category
+// to the top level.
 type UpdateCategoryInput struct {
+	ParentId    *int                  `json:"parent_id"`
 	Name        *string               `json:"name"`
 	Description *string               `json:"description"`
 	ImgFile     *multipart.FileHeader `json:"image_file"`
@@ -214,7 +308,7 @@ type UpdateCategoryInput struct {
 }
 
 func (i UpdateCategoryInput) Validate() error {
-	if i.Name == nil && i.Description == nil && i.ImgFile == nil && i.Available == nil {
+	if i.ParentId == nil && i.Name == nil && i.Description == nil && i.ImgFile == nil && i.Available == nil {
 		return errors.New("no fields provided")
 	}
 	if i.ImgFile != nil {
@@ -222,6 +316,7 @@ func (i UpdateCategoryInput) Validate() error {
 	}
 
 	err := validation.ValidateStruct(&i,
+		validation.Field(&i.ParentId, validation.Min(0)),
 		validation.Field(&i.Name, validation.Length(categoryNameMinLength, categoryNameMaxLength)),
 		validation.Field(&i.Description, validation.Length(categoryDescriptionMinLength, categoryDescriptionMaxLength)),
 	)
@@ -233,24 +328,18 @@ func (i UpdateCategoryInput) Validate() error {
 
 type CreateProductInput struct {
 	CategoryId        int                   `json:"category_id"`
+	Sku               string                `json:"sku"`
 	Name              string                `json:"name"`
 	Description       string                `json:"description"`
 	ImgFile           *multipart.FileHeader `json:"image_file"`
 	Available         bool                  `json:"available"`
-	Price             float32               `json:"price"`
-	UndiscountedPrice float32               `json:"undiscounted_price"`
+	Price             Money                 `json:"price"`
+	UndiscountedPrice Money                 `json:"undiscounted_price"`
 	Stock             int                   `json:"stock"`
 }
 
 func (i CreateProductInput) Validate() error {
-	err := validation.ValidateStruct(&i,
-		validation.Field(&i.CategoryId, validation.Required, validation.Min(1)),
-		validation.Field(&i.Name, validation.Required, validation.Length(productNameMinLength, productNameMaxLength)),
-		validation.Field(&i.Description, validation.Length(productDescriptionMinLength, productDescriptionMaxLength)),
-		validation.Field(&i.Price, validation.Required, validation.Min(0.0)),
-		validation.Field(&i.UndiscountedPrice, validation.Required, validation.Min(0.0)),
-		validation.Field(&i.Stock, validation.Required, validation.Min(0)),
-	)
+	err := i.ValidateFields()
 	if err != nil {
 		return err
 	}
@@ -266,19 +355,34 @@ func (i CreateProductInput) Validate() error {
 	return nil
 }
 
+// ValidateFields validates every field but the image file.
+func (i CreateProductInput) ValidateFields() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.CategoryId, validation.Required, validation.Min(1)),
+		validation.Field(&i.Sku, validation.Length(0, skuMaxLength)),
+		validation.Field(&i.Name, validation.Required, validation.Length(productNameMinLength, productNameMaxLength)),
+		validation.Field(&i.Description, validation.Length(productDescriptionMinLength, productDescriptionMaxLength)),
+		validation.Field(&i.Price, requiredMoney),
+		validation.Field(&i.UndiscountedPrice, requiredMoney),
+		validation.Field(&i.Stock, validation.Required, validation.Min(0)),
+	)
+}
+
 type UpdateProductInput struct {
 	CategoryId        *int                  `json:"category_id"`
+	Sku               *string               `json:"sku"`
 	Name              *string               `json:"name"`
 	Description       *string               `json:"description"`
 	ImgFile           *multipart.FileHeader `json:"image_file"`
 	Available         *bool                 `json:"available"`
-	Price             *float32              `json:"price"`
-	UndiscountedPrice *float32              `json:"undiscounted_price"`
+	Price             *Money                `json:"price"`
+	UndiscountedPrice *Money                `json:"undiscounted_price"`
 	Stock             *int                  `json:"stock"`
 }
 
 func (i UpdateProductInput) Validate() error {
 	if i.CategoryId == nil &&
+		i.Sku == nil &&
 		i.Name == nil &&
 		i.Description == nil &&
 		i.ImgFile == nil &&
@@ -293,10 +397,11 @@ func (i UpdateProductInput) Validate() error {
 	}
 	err := validation.ValidateStruct(&i,
 		validation.Field(&i.CategoryId, validation.Min(1)),
+		validation.Field(&i.Sku, validation.Length(0, skuMaxLength)),
 		validation.Field(&i.Name, validation.Length(productNameMinLength, productNameMaxLength)),
 		validation.Field(&i.Description, validation.Length(productDescriptionMinLength, productDescriptionMaxLength)),
-		validation.Field(&i.Price, validation.Min(0.0)),
-		validation.Field(&i.UndiscountedPrice, validation.Min(0.0)),
+		validation.Field(&i.Price, validMoney),
+		validation.Field(&i.UndiscountedPrice, validMoney),
 		validation.Field(&i.Stock, validation.Min(0)),
 	)
 	if err != nil {
@@ -305,18 +410,778 @@ func (i UpdateProductInput) Validate() error {
 	return nil
 }
 
+type SetProductOptionsInput struct {
+	Options []string `json:"options"`
+}
+
+func (i SetProductOptionsInput) Validate() error {
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.Options, validation.NotNil, validation.Length(0, maxProductOptions),
+			validation.Each(validation.Required, validation.Length(1, productOptionNameMaxLength))),
+	)
+	if err != nil {
+		return err
+	}
+	unique := make(map[string]struct{})
+	for _, option := range i.Options {
+		if _, found := unique[option]; found {
+			return errors.New("options: must be unique")
+		}
+		unique[option] = struct{}{}
+	}
+	return nil
+}
+
+type CreateVariantInput struct {
+	Sku               string                `json:"sku"`
+	Options           VariantOptions        `json:"options"`
+	ImgFile           *multipart.FileHeader `json:"image_file"`
+	Available         bool                  `json:"available"`
+	Price             Money                 `json:"price"`
+	UndiscountedPrice Money                 `json:"undiscounted_price"`
+	Stock             int                   `json:"stock"`
+}
+
+func (i CreateVariantInput) Validate() error {
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.Sku, validation.Required, validation.Length(1, skuMaxLength)),
+		validation.Field(&i.Options, validation.Required),
+		validation.Field(&i.Price, requiredMoney),
+		validation.Field(&i.UndiscountedPrice, requiredMoney),
+		validation.Field(&i.Stock, validation.Min(0)),
+	)
+	if err != nil {
+		return err
+	}
+	if i.ImgFile != nil {
+		return validateFile(i.ImgFile, maxFileSize, allowedFileExtensions[:])
+	}
+	return nil
+}
+
+type UpdateVariantInput struct {
+	Sku               *string               `json:"sku"`
+	Options           VariantOptions        `json:"options"`
+	ImgFile           *multipart.FileHeader `json:"image_file"`
+	Available         *bool                 `json:"available"`
+	Price             *Money                `json:"price"`
+	UndiscountedPrice *Money                `json:"undiscounted_price"`
+	Stock             *int                  `json:"stock"`
+}
+
+func (i UpdateVariantInput) Validate() error {
+	if i.Sku == nil &&
+		i.Options == nil &&
+		i.ImgFile == nil &&
+		i.Available == nil &&
+		i.Price == nil &&
+		i.UndiscountedPrice == nil &&
+		i.Stock == nil {
+		return errors.New("no fields provided")
+	}
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.Sku, validation.Length(1, skuMaxLength)),
+		validation.Field(&i.Price, validMoney),
+		validation.Field(&i.UndiscountedPrice, validMoney),
+		validation.Field(&i.Stock, validation.Min(0)),
+	)
+	if err != nil {
+		return err
+	}
+	if i.ImgFile != nil {
+		return validateFile(i.ImgFile, maxFileSize, allowedFileExtensions[:])
+	}
+	return nil
+}
+
+type AddProductImageInput struct {
+	ImgFile *multipart.FileHeader `json:"image_file"`
+	AltText string                `json:"alt_text"`
+}
+
+func (i AddProductImageInput) Validate() error {
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.ImgFile, validation.Required),
+		validation.Field(&i.AltText, validation.Length(0, imageAltTextMaxLength)),
+	)
+	if err != nil {
+		return err
+	}
+	return validateFile(i.ImgFile, maxFileSize, allowedFileExtensions[:])
+}
+
+type UpdateProductImageInput struct {
+	AltText *string `json:"alt_text"`
+}
+
+func (i UpdateProductImageInput) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.AltText, validation.NotNil, validation.Length(0, imageAltTextMaxLength)),
+	)
+}
+
+type ReorderProductImagesInput struct {
+	Ids []int `json:"ids"`
+}
+
+func (i ReorderProductImagesInput) Validate() error {
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.Ids, validation.Required, validation.Each(validation.Required, validation.Min(1))),
+	)
+	if err != nil {
+		return err
+	}
+	return validateUniqueIds("ids", i.Ids)
+}
+
+// validateUniqueIds makes sure a list of ids has no duplicates.
+func validateUniqueIds(field string, ids []int) error {
+	unique := make(map[int]struct{})
+	for _, id := range ids {
+		if _, found := unique[id]; found {
+			return fmt.Errorf("%s: must be unique", field)
+		}
+		unique[id] = struct{}{}
+	}
+	return nil
+}
+
+type SetProductCategoriesInput struct {
+	CategoryIds []int `json:"category_ids"`
+}
+
+func (i SetProductCategoriesInput) Validate() error {
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.CategoryIds, validation.NotNil, validation.Length(0, maxProductCategories), validation.Each(validation.Min(1))),
+	)
+	if err != nil {
+		return err
+	}
+	return validateUniqueIds("category_ids", i.CategoryIds)
+}
+
+type CreateAttributeInput struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Unit string `json:"unit"`
+}
+
+func (i CreateAttributeInput) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Name, validation.Required, validation.Length(1, attributeNameMaxLength)),
+		validation.Field(&i.Type, validation.Required, validation.In(attributeTypes...)),
+		validation.Field(&i.Unit, validation.Length(0, attributeUnitMaxLength)),
+	)
+}
+
+// UpdateAttributeInput does not allow changing the type, the existing values
+// would not be valid anymore.
+type UpdateAttributeInput struct {
+	Name *string `json:"name"`
+	Unit *string `json:"unit"`
+}
+
+func (i UpdateAttributeInput) Validate() error {
+	if i.Name == nil && i.Unit == nil {
+		return errors.New("no fields provided")
+	}
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Name, validation.NilOrNotEmpty, validation.Length(1, attributeNameMaxLength)),
+		validation.Field(&i.Unit, validation.Length(0, attributeUnitMaxLength)),
+	)
+}
+
+type SetProductAttributesInput struct {
+	Values []ProductAttributeValue `json:"values"`
+}
+
+type ProductAttributeValue struct {
+	AttributeId int    `json:"attribute_id"`
+	Value       string `json:"value"`
+}
+
+func (i SetProductAttributesInput) Validate() error {
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.Values, validation.NotNil),
+	)
+	if err != nil {
+		return err
+	}
+	unique := make(map[int]struct{})
+	for _, value := range i.Values {
+		if _, found := unique[value.AttributeId]; found {
+			return errors.New("attribute_id must be unique")
+		}
+		unique[value.AttributeId] = struct{}{}
+		err := validation.ValidateStruct(&value,
+			validation.Field(&value.AttributeId, validation.Required, validation.Min(1)),
+			validation.Field(&value.Value, validation.Required, validation.Length(1, attributeValueMaxLength)),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f ProductFilter) Validate() error {
+	if err := f.ProductFilterParams.Validate(); err != nil {
+		return err
+	}
+	if err := (SearchParams{Search: f.Search}).Validate(); err != nil {
+		return err
+	}
+	if len(f.Attributes) > maxFilterAttributes {
+		return fmt.Errorf("attr: at most %d attributes can be filtered", maxFilterAttributes)
+	}
+	for name, values := range f.Attributes {
+		if name == "" || len(values) == 0 {
+			return errors.New("attr: must have a name and a value")
+		}
+		if len(values) > maxFilterValues {
+			return fmt.Errorf("attr[%s]: at most %d values can be filtered", name, maxFilterValues)
+		}
+	}
+	return nil
+}
+
 type PaginationParams struct {
-	Page     int `form:"page"`
-	PageSize int `form:"pageSize"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"pageSize"`
+	Cursor   string `form:"cursor"`
 }
 
 func (p PaginationParams) Validate() error {
-	return validation.ValidateStruct(&p,
+	err := validation.ValidateStruct(&p,
 		validation.Field(&p.Page, validation.Min(1)),
 		validation.Field(&p.PageSize, validation.Min(1), validation.Max(100)),
 	)
+	if err != nil {
+		return err
+	}
+	if p.Page != 0 && p.Cursor != "" {
+		return errors.New("page: can not be used with cursor")
+	}
+	return nil
+}
+
+// Product listing sorts.
+const (
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortNameAsc   = "name_asc"
+	SortNameDesc  = "name_desc"
+	SortNewest    = "newest"
+	SortDiscount  = "discount"
+	SortRelevance = "relevance"
+	SortRating    = "rating"
+)
+
+var productSorts = []interface{}{SortPriceAsc, SortPriceDesc, SortNameAsc, SortNameDesc, SortNewest, SortDiscount, SortRelevance, SortRating}
+
+type ProductFilterParams struct {
+	MinPrice    *Money `form:"min_price"`
+	MaxPrice    *Money `form:"max_price"`
+	InStock     bool   `form:"in_stock"`
+	Discounted  bool   `form:"discounted"`
+	CategoryIds []int  `form:"category_ids"`
+	Sort        string `form:"sort"`
+	// IncludeSubcategories lists the products of the subcategories too in a
+	// category listing.
+	IncludeSubcategories bool `form:"include_subcategories"`
+}
+
+func (p ProductFilterParams) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.MinPrice, validMoney),
+		validation.Field(&p.MaxPrice, validMoney),
+		validation.Field(&p.CategoryIds, validation.Length(0, maxFilterValues), validation.Each(validation.Min(1))),
+		validation.Field(&p.Sort, validation.In(productSorts...)),
+	)
+	if err != nil {
+		return err
+	}
+	if p.MinPrice != nil && p.MaxPrice != nil && *p.MinPrice > *p.MaxPrice {
+		return errors.New("min_price: must not be greater than max_price")
+	}
+	return nil
+}
+
+type CreateCollectionInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Available   bool   `json:"available"`
+}
+
+func (i CreateCollectionInput) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Name, validation.Required, validation.Length(1, collectionNameMaxLength)),
+		validation.Field(&i.Description, validation.Length(0, collectionDescriptionMaxLength)),
+	)
+}
+
+type UpdateCollectionInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Available   *bool   `json:"available"`
+}
+
+func (i UpdateCollectionInput) Validate() error {
+	if i.Name == nil && i.Description == nil && i.Available == nil {
+		return errors.New("no fields provided")
+	}
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Name, validation.NilOrNotEmpty, validation.Length(1, collectionNameMaxLength)),
+		validation.Field(&i.Description, validation.Length(0, collectionDescriptionMaxLength)),
+	)
+}
+
+// SetCollectionProductsInput lists the products of a collection in the
+// order they are shown.
+type SetCollectionProductsInput struct {
+	ProductIds []int `json:"product_ids"`
+}
+
+func (i SetCollectionProductsInput) Validate() error {
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.ProductIds, validation.NotNil, validation.Length(0, maxCollectionProducts), validation.Each(validation.Min(1))),
+	)
+	if err != nil {
+		return err
+	}
+	return validateUniqueIds("product_ids", i.ProductIds)
+}
+
+type CreateReviewInput struct {
+	ProductId int    `json:"product_id"`
+	Rating    int    `json:"rating"`
+	Text      string `json:"text"`
+}
+
+func (i CreateReviewInput) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.ProductId, validation.Required, validation.Min(1)),
+		validation.Field(&i.Rating, validation.Required, validation.Min(minReviewRating), validation.Max(maxReviewRating)),
+		validation.Field(&i.Text, validation.Length(0, reviewTextMaxLength)),
+	)
+}
+
+type UpdateReviewInput struct {
+	Rating *int    `json:"rating"`
+	Text   *string `json:"text"`
+}
+
+func (i UpdateReviewInput) Validate() error {
+	if i.Rating == nil && i.Text == nil {
+		return errors.New("no fields provided")
+	}
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Rating, validation.Min(minReviewRating), validation.Max(maxReviewRating)),
+		validation.Field(&i.Text, validation.Length(0, reviewTextMaxLength)),
+	)
+}
+
+// ReportReviewInput reports an abusive review.
+type ReportReviewInput struct {
+	Reason string `json:"reason"`
+}
+
+func (i ReportReviewInput) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Reason, validation.Length(0, reviewReportReasonMaxLength)),
+	)
+}
+
+// AdminReviewFilterParams filters the admin review listing, the moderation
+// queue being the pending reviews.
+type AdminReviewFilterParams struct {
+	Status    string `form:"status"`
+	ProductId int    `form:"product_id"`
+	Reported  bool   `form:"reported"`
+}
+
+func (p AdminReviewFilterParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Status, validation.In(reviewStatuses...)),
+		validation.Field(&p.ProductId, validation.Min(1)),
+	)
+}
+
+// ImportProductsInput is a catalog import upload, a CSV or JSON lines file
+// or a zip archive holding one with the product images.
+type ImportProductsInput struct {
+	File   *multipart.FileHeader `json:"file"`
+	DryRun bool                  `json:"dry_run"`
+}
+
+func (i ImportProductsInput) Validate() error {
+	if i.File == nil || i.File.Size == 0 {
+		return errors.New("file: can not be blank")
+	}
+	if i.File.Size > maxImportFileSize {
+		return fmt.Errorf("file size exceeds max size (%d bytes)", maxImportFileSize)
+	}
+	if i.Format() == "" {
+		return fmt.Errorf("file extension must be .%s/.%s/.%s", CatalogFormatCSV, CatalogFormatJSONL, CatalogFormatZip)
+	}
+	return nil
+}
+
+// Format returns the format of the upload given by its extension, empty when
+// it is not supported.
+func (i ImportProductsInput) Format() string {
+	switch strings.ToLower(strings.TrimPrefix(path.Ext(i.File.Filename), ".")) {
+	case CatalogFormatCSV:
+		return CatalogFormatCSV
+	case CatalogFormatJSONL:
+		return CatalogFormatJSONL
+	case CatalogFormatZip:
+		return CatalogFormatZip
+	}
+	return ""
+}
+
+type ExportParams struct {
+	Format string `form:"format"`
+}
+
+func (p ExportParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Format, validation.In(CatalogFormatCSV, CatalogFormatJSONL)),
+	)
 }
 
 type SearchParams struct {
 	Search string `form:"search"`
 }
+
+func (p SearchParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Search, validation.Length(0, 100)),
+	)
+}
+
+// Admin product listing stock levels.
+const (
+	StockLevelInStock    = "in_stock"
+	StockLevelLow        = "low"
+	StockLevelOutOfStock = "out_of_stock"
+)
+
+// LowStockThreshold is the stock at or under which a product in stock is low
+// on stock.
+const LowStockThreshold = 5
+
+var stockLevels = []interface{}{StockLevelInStock, StockLevelLow, StockLevelOutOfStock}
+
+// AdminProductFilterParams filters the admin product listing, which includes
+// unavailable and archived products unless filtered out.
+type AdminProductFilterParams struct {
+	Search     string `form:"search" json:"search"`
+	CategoryId int    `form:"category_id" json:"category_id"`
+	Available  *bool  `form:"available" json:"available"`
+	Archived   *bool  `form:"archived" json:"archived"`
+	Stock      string `form:"stock" json:"stock"`
+}
+
+func (p AdminProductFilterParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Search, validation.Length(0, 100)),
+		validation.Field(&p.CategoryId, validation.Min(1)),
+		validation.Field(&p.Stock, validation.In(stockLevels...)),
+	)
+}
+
+// AdminCategoryFilterParams filters the admin category listing, which
+// includes unavailable and archived categories unless filtered out.
+type AdminCategoryFilterParams struct {
+	Search    string `form:"search"`
+	Available *bool  `form:"available"`
+	Archived  *bool  `form:"archived"`
+}
+
+func (p AdminCategoryFilterParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Search, validation.Length(0, 100)),
+	)
+}
+
+type SuggestParams struct {
+	Query string `form:"q"`
+	Limit int    `form:"limit"`
+}
+
+func (p SuggestParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Query, validation.Required, validation.Length(1, 100)),
+		validation.Field(&p.Limit, validation.Min(1), validation.Max(10)),
+	)
+}
+
+// BulkUpdateProductsInput applies operations to the products listed by id or
+// matched by the admin listing filter, all at once. A preview reports the
+// changes without saving them.
+type BulkUpdateProductsInput struct {
+	ProductIds []int                     `json:"product_ids"`
+	Filter     *AdminProductFilterParams `json:"filter"`
+	Operations BulkProductOperations     `json:"operations"`
+	Preview    bool                      `json:"preview"`
+}
+
+func (i BulkUpdateProductsInput) Validate() error {
+	if (i.ProductIds == nil) == (i.Filter == nil) {
+		return errors.New("either product_ids or filter must be provided")
+	}
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.ProductIds, validation.Length(1, MaxBulkProducts), validation.Each(validation.Min(1))),
+		validation.Field(&i.Filter),
+		validation.Field(&i.Operations),
+	)
+	if err != nil {
+		return err
+	}
+	return validateUniqueIds("product_ids", i.ProductIds)
+}
+
+// BulkProductOperations are the changes of a bulk product update, the
+// operations left out keep the products as they are. The price is either set
+// or adjusted by a percent, -20 taking 20% off, and rounded to cents. The
+// stock adjustment applies to the stock of the product, not of its variants.
+type BulkProductOperations struct {
+	Price        *Money   `json:"price" swaggertype:"number"`
+	PricePercent *Percent `json:"price_percent" swaggertype:"number"`
+	Available    *bool    `json:"available"`
+	CategoryId   *int     `json:"category_id"`
+	StockDelta   *int     `json:"stock_delta"`
+}
+
+func (o BulkProductOperations) Validate() error {
+	if o.Price == nil && o.PricePercent == nil && o.Available == nil && o.CategoryId == nil && o.StockDelta == nil {
+		return errors.New("at least one operation must be provided")
+	}
+	if o.Price != nil && o.PricePercent != nil {
+		return errors.New("price and price_percent can not be combined")
+	}
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Price, requiredMoney),
+		validation.Field(&o.PricePercent, validPercent),
+		validation.Field(&o.CategoryId, validation.NilOrNotEmpty, validation.Min(1)),
+		validation.Field(&o.StockDelta, validation.NilOrNotEmpty),
+	)
+}
+
+// AddWishlistItemInput saves a product to the wishlist, adding it again
+// updates the notification setting.
+type AddWishlistItemInput struct {
+	ProductId int  `json:"product_id"`
+	Notify    bool `json:"notify"`
+}
+
+func (i AddWishlistItemInput) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.ProductId, validation.Required, validation.Min(1)),
+	)
+}
+
+// currencyCodeRegexp matches an ISO 4217 currency code.
+var currencyCodeRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ExchangeRateInput sets the rate of a currency against the store currency.
+type ExchangeRateInput struct {
+	Currency string `json:"currency"`
+	Rate     Rate   `json:"rate" swaggertype:"number"`
+}
+
+func (i ExchangeRateInput) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Currency, validation.Required, validation.Match(currencyCodeRegexp)),
+		validation.Field(&i.Rate, validRate),
+	)
+}
+
+// SetExchangeRatesInput creates or updates rates, the rates of the other
+// currencies are kept.
+type SetExchangeRatesInput struct {
+	Rates []ExchangeRateInput `json:"rates"`
+}
+
+func (i SetExchangeRatesInput) Validate() error {
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.Rates, validation.Required, validation.Length(1, maxExchangeRates)),
+	)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(i.Rates))
+	for _, rate := range i.Rates {
+		if seen[rate.Currency] {
+			return errors.New("rates: currencies must be unique")
+		}
+		seen[rate.Currency] = true
+		if err := rate.Validate(); err != nil {
+			return fmt.Errorf("rates: %s: %w", rate.Currency, err)
+		}
+	}
+	return nil
+}
+
+// ImportExchangeRatesInput is a CSV file with a currency and a rate column.
+type ImportExchangeRatesInput struct {
+	File *multipart.FileHeader `json:"file"`
+}
+
+func (i ImportExchangeRatesInput) Validate() error {
+	if i.File == nil || i.File.Size == 0 {
+		return errors.New("file: can not be blank")
+	}
+	if i.File.Size > maxExchangeRatesFileSize {
+		return fmt.Errorf("file size exceeds max size (%d bytes)", maxExchangeRatesFileSize)
+	}
+	if strings.ToLower(path.Ext(i.File.Filename)) != "."+CatalogFormatCSV {
+		return fmt.Errorf("file extension must be .%s", CatalogFormatCSV)
+	}
+	return nil
+}
+
+// TaxClassInput creates or updates a tax class, its rates replace the
+// previous ones.
+type TaxClassInput struct {
+	Name  string               `json:"name"`
+	Rates []TaxRegionRateInput `json:"rates"`
+}
+
+type TaxRegionRateInput struct {
+	Region string  `json:"region"`
+	Rate   TaxRate `json:"rate" swaggertype:"number"`
+}
+
+func (i TaxClassInput) Validate() error {
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.Name, validation.Required, validation.Length(1, taxClassNameMaxLength)),
+		validation.Field(&i.Rates, validation.Length(0, maxTaxClassRates)),
+	)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(i.Rates))
+	for _, rate := range i.Rates {
+		if seen[rate.Region] {
+			return errors.New("rates: regions must be unique")
+		}
+		seen[rate.Region] = true
+		err := validation.ValidateStruct(&rate,
+			validation.Field(&rate.Region, validation.Required, validation.Match(taxRegionRegexp)),
+			validation.Field(&rate.Rate, validTaxRate),
+		)
+		if err != nil {
+			return fmt.Errorf("rates: %s: %w", rate.Region, err)
+		}
+	}
+	return nil
+}
+
+// SetTaxClassInput assigns a tax class to a product or a category, a null
+// tax_class_id removes it.
+type SetTaxClassInput struct {
+	TaxClassId *int `json:"tax_class_id"`
+}
+
+func (i SetTaxClassInput) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.TaxClassId, validation.NilOrNotEmpty, validation.Min(1)),
+	)
+}
+
+// promoCodeRegexp matches the characters of a promo code, it is stored in
+// upper case.
+var promoCodeRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// PromoCodeInput creates or replaces a promo code, with either a percent or
+// an amount discount. A new code is active unless Active is false, a
+// replaced one keeps its state unless Active is set.
+type PromoCodeInput struct {
+	Code          string     `json:"code"`
+	Percent       *int       `json:"percent"`
+	Amount        *Money     `json:"amount" swaggertype:"number"`
+	MinOrderValue Money      `json:"min_order_value" swaggertype:"number"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	UsageLimit    *int       `json:"usage_limit"`
+	PerUserLimit  *int       `json:"per_user_limit"`
+	Active        *bool      `json:"active"`
+	ProductIds    []int      `json:"product_ids"`
+	CategoryIds   []int      `json:"category_ids"`
+}
+
+func (i PromoCodeInput) Validate() error {
+	if (i.Percent == nil) == (i.Amount == nil) {
+		return errors.New("either percent or amount must be provided")
+	}
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.Code, validation.Required, validation.Length(promoCodeMinLength, promoCodeMaxLength), validation.Match(promoCodeRegexp)),
+		validation.Field(&i.Percent, validation.NilOrNotEmpty, validation.Min(1), validation.Max(100)),
+		validation.Field(&i.Amount, requiredMoney),
+		validation.Field(&i.MinOrderValue, validMoney),
+		validation.Field(&i.UsageLimit, validation.NilOrNotEmpty, validation.Min(1)),
+		validation.Field(&i.PerUserLimit, validation.NilOrNotEmpty, validation.Min(1)),
+		validation.Field(&i.ProductIds, validation.Length(0, maxPromoCodeRestrictions), validation.Each(validation.Min(1))),
+		validation.Field(&i.CategoryIds, validation.Length(0, maxPromoCodeRestrictions), validation.Each(validation.Min(1))),
+	)
+	if err != nil {
+		return err
+	}
+	if i.StartsAt != nil && i.EndsAt != nil && !i.EndsAt.After(*i.StartsAt) {
+		return errors.New("ends_at: must be after starts_at")
+	}
+	if err := validateUniqueIds("product_ids", i.ProductIds); err != nil {
+		return err
+	}
+	return validateUniqueIds("category_ids", i.CategoryIds)
+}
+
+// PriceScheduleInput schedules a sale of a product or of the products listed
+// in a category.
+type PriceScheduleInput struct {
+	Name       string    `json:"name"`
+	ProductId  *int      `json:"product_id"`
+	CategoryId *int      `json:"category_id"`
+	Percent    int       `json:"percent"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+}
+
+func (i PriceScheduleInput) Validate() error {
+	if (i.ProductId == nil) == (i.CategoryId == nil) {
+		return errors.New("either product_id or category_id must be provided")
+	}
+	err := validation.ValidateStruct(&i,
+		validation.Field(&i.Name, validation.Required, validation.Length(1, priceScheduleNameMaxLength)),
+		validation.Field(&i.ProductId, validation.NilOrNotEmpty, validation.Min(1)),
+		validation.Field(&i.CategoryId, validation.NilOrNotEmpty, validation.Min(1)),
+		validation.Field(&i.Percent, validation.Required, validation.Min(1), validation.Max(99)),
+		validation.Field(&i.StartsAt, validation.Required),
+		validation.Field(&i.EndsAt, validation.Required),
+	)
+	if err != nil {
+		return err
+	}
+	if !i.EndsAt.After(i.StartsAt) {
+		return errors.New("ends_at: must be after starts_at")
+	}
+	return nil
+}
+
+// PriceScheduleFilterParams filters the admin price schedule listing.
+type PriceScheduleFilterParams struct {
+	Status     string `form:"status"`
+	ProductId  int    `form:"product_id"`
+	CategoryId int    `form:"category_id"`
+}
+
+func (p PriceScheduleFilterParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Status, validation.In(priceScheduleStatuses...)),
+		validation.Field(&p.ProductId, validation.Min(1)),
+		validation.Field(&p.CategoryId, validation.Min(1)),
+	)
+}
@@ -0,0 +1,326 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the store currency when APP_CURRENCY is not set.
+const DefaultCurrency = "USD"
+
+// moneyScale is the number of minor units in a major unit, prices are
+// stored as NUMERIC(12, 2).
+const moneyScale = 100
+
+// maxMoney is the largest amount a NUMERIC(12, 2) column holds.
+const maxMoney Money = 1e12 - 1
+
+// Money is an exact amount of the store currency in minor units, cents. It
+// is encoded in JSON and stored as a decimal number with two decimals, so
+// 12.34 is Money(1234).
+type Money int64
+
+// ParseMoney parses a decimal amount with at most two decimals, such as
+// "12.34", exactly.
+func ParseMoney(s string) (Money, error) {
+	return parseMoney(s, false)
+}
+
+// parseMoney parses a decimal amount. Extra decimals are an error unless
+// round is set, then the amount is rounded half away from zero.
+func parseMoney(s string, round bool) (Money, error) {
+	m, err := parseDecimal(s, 2, int64(maxMoney), round)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	return Money(m), nil
+}
+
+// parseDecimal parses a decimal number into an integer of its smallest
+// units, with the given number of decimals. The whole part is limited so
+// that the result stays within max.
+func parseDecimal(s string, decimals int, max int64, round bool) (int64, error) {
+	value := strings.TrimSpace(s)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return 0, errors.New("not a number")
+	}
+	for _, digits := range []string{whole, fraction} {
+		if strings.Trim(digits, "0123456789") != "" {
+			return 0, errors.New("not a number")
+		}
+	}
+
+	roundUp := false
+	if len(fraction) > decimals {
+		if !round {
+			return 0, fmt.Errorf("more than %d decimals", decimals)
+		}
+		roundUp = fraction[decimals] >= '5'
+		fraction = fraction[:decimals]
+	}
+	fraction += strings.Repeat("0", decimals-len(fraction))
+
+	if whole == "" {
+		whole = "0"
+	}
+	scale := pow10(decimals)
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > max/scale {
+		return 0, errors.New("out of range")
+	}
+	var minor int64
+	if fraction != "" {
+		minor, _ = strconv.ParseInt(fraction, 10, 64)
+	}
+
+	v := units*scale + minor
+	if roundUp {
+		v++
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// formatDecimal formats an integer of smallest units with the given number
+// of decimals.
+func formatDecimal(v int64, decimals int) string {
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	scale := pow10(decimals)
+	return fmt.Sprintf("%s%d.%0*d", sign, v/scale, decimals, v%scale)
+}
+
+// scanDecimal reads a NUMERIC column into an integer of its smallest units,
+// rounded to the given number of decimals.
+func scanDecimal(src interface{}, decimals int, max int64) (int64, error) {
+	switch v := src.(type) {
+	case nil:
+		return 0, nil
+	case []byte:
+		return parseDecimal(string(v), decimals, max, true)
+	case string:
+		return parseDecimal(v, decimals, max, true)
+	case int64:
+		return v * pow10(decimals), nil
+	}
+	return 0, fmt.Errorf("unsupported type %T for a decimal", src)
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// MoneyFromFloat converts a float amount, rounded to the nearest cent.
+func MoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * moneyScale))
+}
+
+// String formats the amount with two decimals, such as "12.34".
+func (m Money) String() string {
+	return formatDecimal(int64(m), 2)
+}
+
+// Mul returns the amount times a quantity.
+func (m Money) Mul(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// AddPercent returns the amount increased, or decreased when percent is
+// negative, by a percentage, rounded half away from zero to the cent.
+func (m Money) AddPercent(percent Percent) Money {
+	return Money(mulDivRound(int64(m), int64(hundredPercent+percent), int64(hundredPercent)))
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, or a string holding one, without
+// going through a float.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		return nil
+	}
+	return m.UnmarshalText([]byte(value))
+}
+
+// UnmarshalText decodes a decimal amount, from a fixture file for example.
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// UnmarshalParam decodes a form or query parameter.
+func (m *Money) UnmarshalParam(param string) error {
+	return m.UnmarshalText([]byte(param))
+}
+
+// Scan reads a NUMERIC column, rounded to the cent.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case []byte:
+		parsed, err := parseMoney(string(v), true)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := parseMoney(v, true)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case int64:
+		*m = Money(v * moneyScale)
+	case float64:
+		*m = MoneyFromFloat(v)
+	default:
+		return errors.New("unsupported type for money")
+	}
+	return nil
+}
+
+// Value stores the amount as a decimal string, read exactly by a NUMERIC
+// column.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// moneyRule validates an amount: not negative, within the range of a
+// NUMERIC(12, 2) column, and not zero when required. Nil pointers are valid.
+// ozzo-validation reads a Money as the decimal string it is stored as, so its
+// Required and Min rules do not apply to it.
+type moneyRule struct {
+	required bool
+}
+
+var (
+	validMoney    = moneyRule{}
+	requiredMoney = moneyRule{required: true}
+)
+
+func (r moneyRule) Validate(value interface{}) error {
+	var m Money
+	switch v := value.(type) {
+	case Money:
+		m = v
+	case *Money:
+		if v == nil {
+			return nil
+		}
+		m = *v
+	default:
+		return errors.New("must be an amount")
+	}
+
+	if m == 0 && r.required {
+		return errors.New("cannot be blank")
+	}
+	if m < 0 {
+		return errors.New("must be no less than 0")
+	}
+	if m > maxMoney {
+		return fmt.Errorf("must be no greater than %s", maxMoney)
+	}
+	return nil
+}
+
+// percentDecimals is the precision of the price adjustments.
+const percentDecimals = 4
+
+// hundredPercent is 100%.
+const hundredPercent Percent = 100 * 1e4
+
+// maxPercent is the largest price increase, 1000%.
+const maxPercent Percent = 10 * hundredPercent
+
+// Percent is an exact percentage with four decimals, negative for a
+// decrease, so -12.5% is Percent(-125000).
+type Percent int64
+
+// ParsePercent parses a percentage with at most four decimals, such as "-20"
+// or "12.5", exactly.
+func ParsePercent(s string) (Percent, error) {
+	p, err := parseDecimal(s, percentDecimals, int64(maxPercent), false)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percent %q: %w", s, err)
+	}
+	return Percent(p), nil
+}
+
+// String formats the percentage with its four decimals.
+func (p Percent) String() string {
+	return formatDecimal(int64(p), percentDecimals)
+}
+
+func (p Percent) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, or a string holding one, without
+// going through a float.
+func (p *Percent) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		return nil
+	}
+	parsed, err := ParsePercent(value)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// percentRule validates a price adjustment: not zero, from -100% to 1000%.
+// Nil pointers are valid.
+type percentRule struct{}
+
+var validPercent = percentRule{}
+
+func (percentRule) Validate(value interface{}) error {
+	var p Percent
+	switch v := value.(type) {
+	case Percent:
+		p = v
+	case *Percent:
+		if v == nil {
+			return nil
+		}
+		p = *v
+	default:
+		return errors.New("must be a percent")
+	}
+
+	if p == 0 {
+		return errors.New("cannot be blank")
+	}
+	if p < -hundredPercent || p > maxPercent {
+		return errors.New("must be between -100 and 1000")
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		round   bool
+		want    int64
+		wantErr string
+	}{
+		{name: "Whole", input: "12", want: 1200},
+		{name: "Decimals", input: "12.34", want: 1234},
+		{name: "One decimal", input: "12.5", want: 1250},
+		{name: "No whole part", input: ".5", want: 50},
+		{name: "Spaces and sign", input: " +7.00 ", want: 700},
+		{name: "Negative", input: "-0.05", want: -5},
+		{name: "Rounded up", input: "12.345", round: true, want: 1235},
+		{name: "Rounded down", input: "12.344", round: true, want: 1234},
+		{name: "Negative rounded away from zero", input: "-12.345", round: true, want: -1235},
+		{name: "Too many decimals", input: "12.345", wantErr: "more than 2 decimals"},
+		{name: "Empty", input: "", wantErr: "not a number"},
+		{name: "Letters", input: "12a", wantErr: "not a number"},
+		{name: "Exponent", input: "1e3", wantErr: "not a number"},
+		{name: "Largest", input: "9999999999.99", want: int64(maxMoney)},
+		{name: "Out of range", input: "10000000000", wantErr: "out of range"},
+		{name: "Overflow", input: "99999999999999999999", wantErr: "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDecimal(tt.input, 2, int64(maxMoney), tt.round)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	for _, m := range []Money{0, 5, 1234, -1234, maxMoney} {
+		data, err := json.Marshal(m)
+		assert.NoError(t, err)
+
+		var got Money
+		assert.NoError(t, json.Unmarshal(data, &got))
+		assert.Equal(t, m, got)
+	}
+
+	data, err := json.Marshal(Money(-5))
+	assert.NoError(t, err)
+	assert.Equal(t, "-0.05", string(data))
+
+	var m Money
+	assert.NoError(t, json.Unmarshal([]byte(`"12.34"`), &m))
+	assert.Equal(t, Money(1234), m)
+	assert.Error(t, json.Unmarshal([]byte(`12.345`), &m))
+	assert.Error(t, json.Unmarshal([]byte(`1e3`), &m))
+}
+
+func TestMoneySQL(t *testing.T) {
+	for _, m := range []Money{0, 5, 1234, -1234, maxMoney} {
+		value, err := m.Value()
+		assert.NoError(t, err)
+
+		var got Money
+		assert.NoError(t, got.Scan(value))
+		assert.Equal(t, m, got)
+	}
+
+	tests := []struct {
+		name string
+		src  interface{}
+		want Money
+	}{
+		{name: "Null", src: nil, want: 0},
+		{name: "Bytes", src: []byte("12.34"), want: 1234},
+		{name: "Rounded", src: []byte("12.345"), want: 1235},
+		{name: "Negative rounded", src: "-12.345", want: -1235},
+		{name: "Integer", src: int64(12), want: 1200},
+		{name: "Float", src: 0.1, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			assert.NoError(t, got.Scan(tt.src))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	var m Money
+	assert.Error(t, m.Scan(true))
+	assert.Error(t, m.Scan("99999999999999999999"))
+}
+
+func TestMulDivRound(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b, c int64
+		want    int64
+	}{
+		{name: "Exact", a: 6, b: 1, c: 2, want: 3},
+		{name: "Below half", a: 1, b: 1, c: 3, want: 0},
+		{name: "Above half", a: 2, b: 1, c: 3, want: 1},
+		{name: "Half up", a: 5, b: 1, c: 2, want: 3},
+		{name: "Negative half away from zero", a: -5, b: 1, c: 2, want: -3},
+		{name: "Negative divisor", a: 5, b: 1, c: -2, want: -3},
+		{name: "Negative below half", a: -1, b: 1, c: 3, want: 0},
+		{name: "Intermediate overflow", a: math.MaxInt64, b: 4, c: 8, want: math.MaxInt64/2 + 1},
+		{name: "Large product", a: 1 << 62, b: 1 << 10, c: 1 << 11, want: 1 << 61},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mulDivRound(tt.a, tt.b, tt.c))
+		})
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Percent
+		wantErr bool
+	}{
+		{input: "-20", want: -200000},
+		{input: "12.5", want: 125000},
+		{input: "0.0001", want: 1},
+		{input: "1000", want: maxPercent},
+		{input: "0.00001", wantErr: true},
+		{input: "1001", wantErr: true},
+		{input: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePercent(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMoneyAddPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		percent Percent
+		want    Money
+	}{
+		{name: "Decrease", money: 1000, percent: -200000, want: 800},
+		{name: "Increase", money: 1000, percent: 125000, want: 1125},
+		{name: "Half cent up", money: 1, percent: 500000, want: 2},
+		{name: "Rounded", money: 999, percent: 125000, want: 1124},
+		{name: "Negative amount", money: -999, percent: 125000, want: -1124},
+		{name: "Free", money: 1999, percent: -hundredPercent, want: 0},
+		{name: "Fraction of a percent", money: 100000, percent: 150, want: 100015},
+		{name: "Largest", money: maxMoney, percent: maxPercent, want: maxMoney * 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.money.AddPercent(tt.percent))
+		})
+	}
+}
+
+func TestPercentRule(t *testing.T) {
+	percent := func(p Percent) *Percent { return &p }
+
+	assert.NoError(t, validPercent.Validate((*Percent)(nil)))
+	assert.NoError(t, validPercent.Validate(percent(-hundredPercent)))
+	assert.NoError(t, validPercent.Validate(percent(maxPercent)))
+	assert.Error(t, validPercent.Validate(percent(0)))
+	assert.Error(t, validPercent.Validate(percent(-hundredPercent-1)))
+	assert.Error(t, validPercent.Validate(percent(maxPercent+1)))
+}
@@ -1,21 +1,65 @@
 package domain
 
 type Order struct {
-	Id        int              `json:"id" db:"id"`
-	UserId    int              `json:"user_id" db:"user_id"`
-	Date      string           `json:"date"`
-	Products  []OrderedProduct `json:"products" db:"products"`
-	TotalCost float32          `json:"total_cost" db:"total_cost"`
+	Id       int              `json:"id" db:"id"`
+	UserId   int              `json:"user_id" db:"user_id"`
+	Date     string           `json:"date"`
+	Products []OrderedProduct `json:"products" db:"products"`
+	// NetAmount and TaxAmount add up the lines, TotalCost is their sum: the
+	// gross amount of the order. DiscountAmount is the discount of the
+	// promo code, already taken from the lines.
+	DiscountAmount Money `json:"discount_amount" db:"discount_amount"`
+	NetAmount      Money `json:"net_amount" db:"net_amount"`
+	TaxAmount      Money `json:"tax_amount" db:"tax_amount"`
+	TotalCost      Money `json:"total_cost" db:"total_cost"`
+	// Currency is the currency the order was placed in, its prices are in
+	// it. ExchangeRate converted the store prices to it at checkout.
+	Currency     string `json:"currency"`
+	ExchangeRate Rate   `json:"exchange_rate" db:"exchange_rate" swaggertype:"number"`
+	// TaxRegion is the region the taxes were computed for. PricesIncludeTax
+	// tells whether the prices of the lines include the tax or had it added.
+	TaxRegion        string `json:"tax_region" db:"tax_region"`
+	PricesIncludeTax bool   `json:"prices_include_tax" db:"prices_include_tax"`
+	// PromoCode is the promo code the order was placed with.
+	PromoCode *string `json:"promo_code,omitempty" db:"promo_code"`
 }
 
 type OrderedProduct struct {
-	Id                int     `json:"id" db:"id"`
-	OrderId           int     `json:"-" db:"order_id"`
-	ProductId         int     `json:"product_id" db:"product_id"`
-	Name              string  `json:"name"`
-	Description       string  `json:"description"`
-	Price             float32 `json:"price"`
-	UndiscountedPrice float32 `json:"undiscounted_price" db:"undiscounted_price"`
-	ImageUrl          string  `json:"image_url" db:"image_url"`
-	Quantity          int     `json:"quantity"`
+	Id                int            `json:"id" db:"id"`
+	OrderId           int            `json:"-" db:"order_id"`
+	ProductId         int            `json:"product_id" db:"product_id"`
+	VariantId         *int           `json:"variant_id,omitempty" db:"variant_id"`
+	Sku               string         `json:"sku,omitempty"`
+	VariantOptions    VariantOptions `json:"variant_options,omitempty" db:"variant_options"`
+	Name              string         `json:"name"`
+	Description       string         `json:"description"`
+	Price             Money          `json:"price"`
+	UndiscountedPrice Money          `json:"undiscounted_price" db:"undiscounted_price"`
+	ImageUrl          string         `json:"image_url" db:"image_url"`
+	Quantity          int            `json:"quantity"`
+	// TaxRate is the percentage the line was taxed at. The amounts are for
+	// the whole quantity: the price times the quantity less DiscountAmount
+	// is split into NetAmount and TaxAmount, which add up to GrossAmount.
+	TaxRate        TaxRate `json:"tax_rate" db:"tax_rate" swaggertype:"number"`
+	DiscountAmount Money   `json:"discount_amount" db:"discount_amount"`
+	NetAmount      Money   `json:"net_amount" db:"net_amount"`
+	TaxAmount      Money   `json:"tax_amount" db:"tax_amount"`
+	GrossAmount    Money   `json:"gross_amount" db:"gross_amount"`
+}
+
+// Checkout is how an order is priced: the rate converting the prices to its
+// currency, how it is taxed and the promo code discounting it.
+type Checkout struct {
+	ExchangeRate     ExchangeRate
+	TaxRegion        string
+	PricesIncludeTax bool
+	PromoCode        string
+}
+
+// PriceLine sets the amounts of an ordered line, its price already converted
+// to the order currency and its discount set, taxed at a rate.
+func (c Checkout) PriceLine(line *OrderedProduct, rate TaxRate) {
+	line.TaxRate = rate
+	line.NetAmount, line.TaxAmount = rate.Split(line.Price.Mul(line.Quantity)-line.DiscountAmount, c.PricesIncludeTax)
+	line.GrossAmount = line.NetAmount + line.TaxAmount
 }
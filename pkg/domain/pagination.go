@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// PageRequest selects a page of a listing, by offset or from a cursor. The
+// offset is ignored when a cursor is given.
+type PageRequest struct {
+	Limit  int
+	Offset int
+	Cursor *Cursor
+}
+
+// Cursor is the position of a row in a listing: the value of the sort key
+// and the id of the row. Before selects the page ending at the position
+// instead of the one starting after it.
+type Cursor struct {
+	Sort   string `json:"s,omitempty"`
+	Key    string `json:"k,omitempty"`
+	Id     int    `json:"i"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// Encode returns the cursor in the opaque form clients send back.
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("cursor: invalid value")
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Id < 1 {
+		return nil, errors.New("cursor: invalid value")
+	}
+	return &c, nil
+}
+
+// Pagination describes the page of a listing returned. Page is only set when
+// the page was selected by number.
+type Pagination struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
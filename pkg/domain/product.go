@@ -1,13 +1,125 @@
 package domain
 
+import (
+	"errors"
+	"time"
+)
+
 type Product struct {
-	Id                int     `json:"id" db:"id"`
-	CategoryId        int     `json:"category_id" db:"category_id"`
-	Name              string  `json:"name"`
-	Description       string  `json:"description"`
-	Price             float32 `json:"price"`
-	UndiscountedPrice float32 `json:"undiscounted_price" db:"undiscounted_price"`
-	ImageUrl          string  `json:"image_url" db:"image_url"`
-	Available         bool    `json:"available"`
-	Stock             int     `json:"stock"`
+	Id         int `json:"id" db:"id"`
+	CategoryId int `json:"category_id" db:"category_id"`
+	// TaxClassId is the tax class of the product, it overrides the class of
+	// its primary category.
+	TaxClassId *int `json:"tax_class_id,omitempty" db:"tax_class_id"`
+	// Sku identifies the product in catalog imports, it is optional.
+	Sku               *string   `json:"sku,omitempty" db:"sku"`
+	Name              string    `json:"name"`
+	Description       string    `json:"description"`
+	Price             Money     `json:"price"`
+	UndiscountedPrice Money     `json:"undiscounted_price" db:"undiscounted_price"`
+	ImageUrl          string    `json:"image_url" db:"image_url"`
+	Available         bool      `json:"available"`
+	Stock             int       `json:"stock"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	// RatingAverage and RatingCount aggregate the reviews of the product,
+	// the average is 0 until it is reviewed.
+	RatingAverage float32 `json:"rating_average" db:"rating_average"`
+	RatingCount   int     `json:"rating_count" db:"rating_count"`
+	// ArchivedAt is set when the product is archived, archived products are
+	// hidden from the shop but kept for the orders they appear in.
+	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+	// Highlight is set in search results, it holds the fragments of the name
+	// and description matching the search.
+	Highlight string `json:"highlight,omitempty" db:"highlight"`
+	// Currency is the currency the prices are shown in, it is set in the
+	// catalog responses.
+	Currency string `json:"currency,omitempty" db:"-"`
+	// ActiveSale is set while the product is in a scheduled sale.
+	ActiveSale *ActiveSale `json:"active_sale,omitempty" db:"active_sale"`
+
+	Images     []ProductImage     `json:"images,omitempty" db:"-"`
+	Attributes []ProductAttribute `json:"attributes,omitempty" db:"-"`
+	Options    []string           `json:"options,omitempty" db:"-"`
+	Variants   []ProductVariant   `json:"variants,omitempty" db:"-"`
+	// CategoryIds are the categories the product is listed in, its primary
+	// category first.
+	CategoryIds []int `json:"category_ids,omitempty" db:"-"`
+	// Breadcrumbs is the path to the product category, from its top level
+	// category.
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs,omitempty" db:"-"`
+}
+
+// ProductImage is an image of the product gallery. The image in the first
+// position is the primary one, also exposed as the product image_url.
+type ProductImage struct {
+	Id        int    `json:"id" db:"id"`
+	ProductId int    `json:"-" db:"product_id"`
+	FileName  string `json:"-" db:"file_name"`
+	Url       string `json:"url"`
+	AltText   string `json:"alt_text" db:"alt_text"`
+	Position  int    `json:"position"`
+}
+
+// ProductState holds the fields of a product a bulk update can change.
+type ProductState struct {
+	CategoryId        int   `json:"category_id" db:"category_id"`
+	Price             Money `json:"price"`
+	UndiscountedPrice Money `json:"undiscounted_price" db:"undiscounted_price"`
+	Stock             int   `json:"stock"`
+	Available         bool  `json:"available"`
+}
+
+// Apply returns the state of a product after the operations.
+func (o BulkProductOperations) Apply(s ProductState) ProductState {
+	if o.Price != nil {
+		s.Price = *o.Price
+	}
+	if o.PricePercent != nil {
+		s.Price = s.Price.AddPercent(*o.PricePercent)
+	}
+	if o.Available != nil {
+		s.Available = *o.Available
+	}
+	if o.CategoryId != nil {
+		s.CategoryId = *o.CategoryId
+	}
+	if o.StockDelta != nil {
+		s.Stock += *o.StockDelta
+	}
+	return s
+}
+
+// Validate checks the state with the constraints of the products table.
+func (s ProductState) Validate() error {
+	if s.Price < 0 {
+		return errors.New("price: must be no less than 0")
+	}
+	if s.UndiscountedPrice < s.Price {
+		return errors.New("price: must not exceed undiscounted_price")
+	}
+	if s.Stock < 0 {
+		return errors.New("stock: must be no less than 0")
+	}
+	return nil
+}
+
+// ProductChange is the change of a product by a bulk update. Error is set
+// when the change would leave the product invalid.
+type ProductChange struct {
+	Id     int          `json:"id"`
+	Name   string       `json:"name"`
+	Before ProductState `json:"before"`
+	After  ProductState `json:"after"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// BulkUpdateResult is the outcome of a bulk product update. Changes lists the
+// products the update changes, the others matched are left as they are. No
+// product is saved in a preview or when a change is invalid.
+type BulkUpdateResult struct {
+	Preview bool            `json:"preview"`
+	Matched int             `json:"matched"`
+	Changed int             `json:"changed"`
+	Failed  int             `json:"failed"`
+	Changes []ProductChange `json:"changes"`
 }
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// PromoCode discounts the orders using it, by a percentage of the eligible
+// lines or by a fixed amount of the store currency shared between them. The
+// lines are eligible when the code has no product nor category restriction,
+// or when their product or one of its categories is listed.
+type PromoCode struct {
+	Id            int        `json:"id" db:"id"`
+	Code          string     `json:"code" db:"code"`
+	Percent       *int       `json:"percent,omitempty" db:"percent"`
+	Amount        *Money     `json:"amount,omitempty" db:"amount" swaggertype:"number"`
+	MinOrderValue Money      `json:"min_order_value" db:"min_order_value" swaggertype:"number"`
+	StartsAt      *time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at" db:"ends_at"`
+	// UsageLimit caps the orders of all the users using the code and
+	// PerUserLimit the orders of each user, there is no cap when nil.
+	UsageLimit   *int      `json:"usage_limit" db:"usage_limit"`
+	PerUserLimit *int      `json:"per_user_limit" db:"per_user_limit"`
+	UsedCount    int       `json:"used_count" db:"used_count"`
+	Active       bool      `json:"active" db:"active"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	ProductIds  []int `json:"product_ids" db:"-"`
+	CategoryIds []int `json:"category_ids" db:"-"`
+}
+
+// Usable returns why the code can not be used at a time by a user who
+// already used it uses times, nil when it can.
+func (p PromoCode) Usable(now time.Time, uses int) error {
+	switch {
+	case !p.Active:
+		return errors.New("is not active")
+	case p.StartsAt != nil && now.Before(*p.StartsAt):
+		return errors.New("is not valid yet")
+	case p.EndsAt != nil && !now.Before(*p.EndsAt):
+		return errors.New("has expired")
+	case p.UsageLimit != nil && p.UsedCount >= *p.UsageLimit:
+		return errors.New("has reached its usage limit")
+	case p.PerUserLimit != nil && uses >= *p.PerUserLimit:
+		return errors.New("has already been used")
+	}
+	return nil
+}
+
+// Apply sets the discount of the eligible lines of an order, their prices
+// converted to the order currency with rate, and returns the discount of
+// the order. The minimum order value and the fixed amount are converted
+// too. A fixed amount is capped to the eligible lines and shared between them
+// in proportion to their amount, the cents left over going to the first
+// lines.
+func (p PromoCode) Apply(lines []OrderedProduct, eligible map[int]bool, rate Rate) (Money, error) {
+	var subtotal, eligibleTotal Money
+	for _, line := range lines {
+		amount := line.Price.Mul(line.Quantity)
+		subtotal += amount
+		if eligible[line.ProductId] {
+			eligibleTotal += amount
+		}
+	}
+	if minValue := rate.Convert(p.MinOrderValue); subtotal < minValue {
+		return 0, fmt.Errorf("requires an order of at least %s", minValue)
+	}
+	if eligibleTotal == 0 {
+		return 0, errors.New("does not apply to the ordered products")
+	}
+
+	var discount Money
+	if p.Percent != nil {
+		for i, line := range lines {
+			if eligible[line.ProductId] {
+				lines[i].DiscountAmount = Money(mulDivRound(int64(line.Price.Mul(line.Quantity)), int64(*p.Percent), 100))
+				discount += lines[i].DiscountAmount
+			}
+		}
+		return discount, nil
+	}
+
+	total := rate.Convert(*p.Amount)
+	if total > eligibleTotal {
+		total = eligibleTotal
+	}
+	for i, line := range lines {
+		if eligible[line.ProductId] {
+			lines[i].DiscountAmount = share(total, line.Price.Mul(line.Quantity), eligibleTotal)
+			discount += lines[i].DiscountAmount
+		}
+	}
+	for i := 0; discount < total; i = (i + 1) % len(lines) {
+		if eligible[lines[i].ProductId] && lines[i].DiscountAmount < lines[i].Price.Mul(lines[i].Quantity) {
+			lines[i].DiscountAmount++
+			discount++
+		}
+	}
+	return discount, nil
+}
+
+// share returns the part of total in proportion to part of whole, rounded
+// down to the cent.
+func share(total, part, whole Money) Money {
+	product := new(big.Int).Mul(big.NewInt(int64(total)), big.NewInt(int64(part)))
+	return Money(product.Quo(product, big.NewInt(int64(whole))).Int64())
+}
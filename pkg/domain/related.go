@@ -0,0 +1,5 @@
+package domain
+
+// RelatedProductsLimit is the number of products bought together with a
+// product kept for it, and the number of related products shown on its page.
+const RelatedProductsLimit = 10
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Review moderation statuses. Only approved reviews are shown and count
+// towards the product rating.
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+var reviewStatuses = []interface{}{ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected}
+
+// Review is the review of a product by a customer who purchased it. A
+// customer reviews a product once, and can edit the review afterwards.
+type Review struct {
+	Id        int       `json:"id"`
+	ProductId int       `json:"product_id" db:"product_id"`
+	UserId    int       `json:"user_id" db:"user_id"`
+	UserName  string    `json:"user_name" db:"user_name"`
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// ModeratedAt is set once an admin has approved or rejected the review.
+	ModeratedAt *time.Time `json:"moderated_at,omitempty" db:"moderated_at"`
+	// ReportCount is the number of users who reported the review since it
+	// was last approved, only set in the admin listing.
+	ReportCount int `json:"report_count,omitempty" db:"report_count"`
+}
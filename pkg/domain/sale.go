@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	PriceScheduleScheduled = "scheduled"
+	PriceScheduleActive    = "active"
+	PriceScheduleEnded     = "ended"
+	PriceScheduleCancelled = "cancelled"
+)
+
+var priceScheduleStatuses = []interface{}{PriceScheduleScheduled, PriceScheduleActive, PriceScheduleEnded, PriceScheduleCancelled}
+
+// PriceSchedule is a sale taking a percentage off the price of a product, or
+// of the products listed in a category, between two times. The scheduler
+// lowers the prices when it starts and restores them when it ends. A product
+// is in one sale at a time, the sale started first, and joins the next
+// overlapping sale when it ends.
+type PriceSchedule struct {
+	Id         int       `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	ProductId  *int      `json:"product_id,omitempty" db:"product_id"`
+	CategoryId *int      `json:"category_id,omitempty" db:"category_id"`
+	Percent    int       `json:"percent" db:"percent"`
+	StartsAt   time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time `json:"ends_at" db:"ends_at"`
+	Status     string    `json:"status" db:"status"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// PriceScheduleRun is the outcome of a run of the scheduler: the sales it
+// started and the sales it ended.
+type PriceScheduleRun struct {
+	Started int `json:"started"`
+	Ended   int `json:"ended"`
+}
+
+// ActiveSale is the sale a product is in. Its price is the sale price, the
+// regular price is restored when the sale ends.
+type ActiveSale struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name"`
+	Percent      int       `json:"percent"`
+	RegularPrice Money     `json:"regular_price" swaggertype:"number"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+}
+
+// Scan reads the sale selected as a JSON object.
+func (s *ActiveSale) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	}
+	return errors.New("incompatible type for ActiveSale")
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "strings"
+
+// Suggestions are the completions of a search being typed.
+type Suggestions struct {
+	Products   []ProductSuggestion  `json:"products"`
+	Categories []CategorySuggestion `json:"categories"`
+	Queries    []string             `json:"queries"`
+}
+
+type ProductSuggestion struct {
+	Id       int    `json:"id" db:"id"`
+	Name     string `json:"name"`
+	ImageUrl string `json:"image_url" db:"image_url"`
+}
+
+type CategorySuggestion struct {
+	Id   int    `json:"id" db:"id"`
+	Name string `json:"name"`
+}
+
+// NormalizeQuery returns the form searches are recorded and matched in:
+// lower case, with single spaces between words.
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// taxRateDecimals is the precision of the tax rates, stored as a percentage
+// in NUMERIC(7, 4).
+const taxRateDecimals = 4
+
+const taxRateScale = 1e4
+
+// maxTaxRate is 100%.
+const maxTaxRate TaxRate = 100 * taxRateScale
+
+// AnyTaxRegion is the region of the rate applied when a tax class has no
+// rate for the region of the order.
+const AnyTaxRegion = "*"
+
+// taxRegionRegexp matches AnyTaxRegion, an ISO 3166-1 country code such as
+// "FR" or an ISO 3166-2 subdivision code such as "US-CA".
+var taxRegionRegexp = regexp.MustCompile(`^(\*|[A-Z]{2}(-[A-Z0-9]{1,3})?)$`)
+
+// TaxRate is an exact tax percentage with four decimals, 8.875% is
+// TaxRate(88750).
+type TaxRate int64
+
+// ParseTaxRate parses a percentage with at most four decimals, such as "20"
+// or "8.875", exactly.
+func ParseTaxRate(s string) (TaxRate, error) {
+	r, err := parseDecimal(s, taxRateDecimals, int64(maxTaxRate), false)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tax rate %q: %w", s, err)
+	}
+	return TaxRate(r), nil
+}
+
+// String formats the percentage with its four decimals.
+func (r TaxRate) String() string {
+	return formatDecimal(int64(r), taxRateDecimals)
+}
+
+// Split splits the amount of an order line into its net amount and its tax.
+// With inclusive set the amount includes the tax, otherwise the tax is added
+// to it. The tax is rounded half away from zero to the cent, on the line.
+func (r TaxRate) Split(amount Money, inclusive bool) (net, tax Money) {
+	if inclusive {
+		net = Money(mulDivRound(int64(amount), int64(maxTaxRate), int64(maxTaxRate+r)))
+		return net, amount - net
+	}
+	return amount, Money(mulDivRound(int64(amount), int64(r), int64(maxTaxRate)))
+}
+
+func (r TaxRate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, or a string holding one, without
+// going through a float.
+func (r *TaxRate) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		return nil
+	}
+	parsed, err := ParseTaxRate(value)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Scan reads a NUMERIC column, rounded to the fourth decimal.
+func (r *TaxRate) Scan(src interface{}) error {
+	v, err := scanDecimal(src, taxRateDecimals, int64(maxTaxRate))
+	if err != nil {
+		return err
+	}
+	*r = TaxRate(v)
+	return nil
+}
+
+// Value stores the percentage as a decimal string, read exactly by a NUMERIC
+// column.
+func (r TaxRate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// taxRateRule validates a tax rate: from 0 to 100%. See moneyRule for why it
+// is not a Min rule.
+type taxRateRule struct{}
+
+var validTaxRate = taxRateRule{}
+
+func (taxRateRule) Validate(value interface{}) error {
+	r, ok := value.(TaxRate)
+	if !ok {
+		return errors.New("must be a tax rate")
+	}
+	if r < 0 || r > maxTaxRate {
+		return errors.New("must be between 0 and 100")
+	}
+	return nil
+}
+
+// TaxRegions returns the regions whose rate applies to an order of a
+// region, the most specific first: the region, its country, then any
+// region.
+func TaxRegions(region string) []string {
+	regions := make([]string, 0, 3)
+	if region != "" && region != AnyTaxRegion {
+		regions = append(regions, region)
+		if country, _, found := strings.Cut(region, "-"); found {
+			regions = append(regions, country)
+		}
+	}
+	return append(regions, AnyTaxRegion)
+}
+
+// TaxClass groups the products taxed alike, with a rate for each region.
+// Products without a class, on themselves or on their primary category, are
+// not taxed.
+type TaxClass struct {
+	Id    int             `json:"id" db:"id"`
+	Name  string          `json:"name" db:"name"`
+	Rates []TaxRegionRate `json:"rates" db:"-"`
+}
+
+type TaxRegionRate struct {
+	TaxClassId int     `json:"-" db:"tax_class_id"`
+	Region     string  `json:"region" db:"region"`
+	Rate       TaxRate `json:"rate" db:"rate" swaggertype:"number"`
+}
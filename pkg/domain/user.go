@@ -6,4 +6,6 @@ type User struct {
 	Email      string `json:"email"`
 	Password   string `json:"-"`
 	ProfileImg string `json:"profile_image" db:"profile_image"`
+	Disabled   bool   `json:"-" db:"disabled"`
+	IsAdmin    bool   `json:"-" db:"is_admin"`
 }
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+type ProductVariant struct {
+	Id                int            `json:"id" db:"id"`
+	ProductId         int            `json:"-" db:"product_id"`
+	Sku               string         `json:"sku"`
+	Options           VariantOptions `json:"options"`
+	Price             Money          `json:"price"`
+	UndiscountedPrice Money          `json:"undiscounted_price" db:"undiscounted_price"`
+	ImageUrl          string         `json:"image_url" db:"image_url"`
+	Available         bool           `json:"available"`
+	Stock             int            `json:"stock"`
+}
+
+// VariantOptions maps an option type of the product (size, colour...) to the
+// value the variant has for it. It is stored as a JSON object.
+type VariantOptions map[string]string
+
+func (o VariantOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(o)
+	return string(b), err
+}
+
+func (o *VariantOptions) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*o = nil
+		return nil
+	default:
+		return errors.New("incompatible type for VariantOptions")
+	}
+	return json.Unmarshal(b, o)
+}
+
+// Matches reports whether the variant has a value for each option type
+// and for nothing else.
+func (o VariantOptions) Matches(optionTypes []string) bool {
+	if len(o) != len(optionTypes) {
+		return false
+	}
+	for _, optionType := range optionTypes {
+		if value, ok := o[optionType]; !ok || value == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// WishlistItem is a product saved to the wishlist of a user, with its
+// current price and stock.
+type WishlistItem struct {
+	Product
+	// InStock is true when the product or one of its available variants
+	// has stock.
+	InStock bool `json:"in_stock" db:"in_stock"`
+	// Notify is set when the user is notified of price drops and of the
+	// product coming back in stock.
+	Notify  bool      `json:"notify"`
+	AddedAt time.Time `json:"added_at" db:"added_at"`
+}
+
+// WishlistAlert is a change of a wishlisted product the user asked to be
+// notified of: its price dropped or it came back in stock.
+type WishlistAlert struct {
+	UserId        int    `db:"user_id"`
+	UserName      string `db:"user_name"`
+	UserEmail     string `db:"user_email"`
+	ProductId     int    `db:"product_id"`
+	ProductName   string `db:"product_name"`
+	Price         Money  `db:"price"`
+	PreviousPrice Money  `db:"previous_price"`
+	BackInStock   bool   `db:"back_in_stock"`
+}
+
+// PriceDropped reports whether the alert is for a price drop.
+func (a WishlistAlert) PriceDropped() bool {
+	return a.Price < a.PreviousPrice
+}
@@ -14,6 +14,8 @@ const (
 	TypeForbidden Type = "forbidden"
 	// TypeUnauthorized is used for HTTP 401-like errors.
 	TypeUnauthorized Type = "unauthorized"
+	// TypeConflict is used for HTTP 409-like errors.
+	TypeConflict Type = "conflict"
 
 	// TypeNoRows is used for DB errors when query response is empty.
 	TypeNoRows Type = "no_rows"
@@ -82,6 +84,14 @@ func Unauthorized(text string) error {
 	}
 }
 
+// Conflict returns an AppError with a TypeConflict type.
+func Conflict(text string) error {
+	return &AppError{
+		text:    text,
+		errType: TypeConflict,
+	}
+}
+
 // NoRows returns an AppError with a TypeNoRows type.
 func NoRows() error {
 	return &AppError{
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Get Category Attributes
+// @Tags Сategories
+// @Description Get the attributes the products of a category can be filtered by.
+// @ID get-category-attributes
+// @Accept json
+// @Produce json
+// @Param id path int true "Category id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /api/categories/{id}/attributes [get]
+func (h *Handler) getCategoryAttributes(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	attributes, err := h.services.Attribute.GetAttributes(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, attributes)
+}
+
+// @Summary Create Attribute
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Define an attribute for the products of a category. The type is text, number or boolean.
+// @ID create-attribute
+// @Accept json
+// @Produce json
+// @Param id path int true "Category id"
+// @Param input body domain.CreateAttributeInput true "Attribute definition"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/categories/{id}/attributes [post]
+func (h *Handler) adminCreateAttribute(c *gin.Context) {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.CreateAttributeInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.services.Attribute.CreateAttribute(categoryId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OKId(c, id)
+}
+
+// @Summary Update Attribute
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Rename an attribute or change its unit.
+// @ID update-attribute
+// @Accept json
+// @Produce json
+// @Param id path int true "Category id"
+// @Param attributeId path int true "Attribute id"
+// @Param input body domain.UpdateAttributeInput true "Attribute data"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/categories/{id}/attributes/{attributeId} [put]
+func (h *Handler) adminUpdateAttribute(c *gin.Context) {
+	categoryId, attributeId, ok := categoryAttributeIds(c)
+	if !ok {
+		return
+	}
+
+	var input domain.UpdateAttributeInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.services.Attribute.UpdateAttribute(categoryId, attributeId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Attribute
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Delete an attribute and the values products have for it.
+// @ID delete-attribute
+// @Produce json
+// @Param id path int true "Category id"
+// @Param attributeId path int true "Attribute id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/categories/{id}/attributes/{attributeId} [delete]
+func (h *Handler) adminDeleteAttribute(c *gin.Context) {
+	categoryId, attributeId, ok := categoryAttributeIds(c)
+	if !ok {
+		return
+	}
+
+	err := h.services.Attribute.DeleteAttribute(categoryId, attributeId)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Set Product Attributes
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Replace the attribute values of a product. The attributes must be defined for the product category.
+// @ID set-product-attributes
+// @Accept json
+// @Produce json
+// @Param id path int true "Product id"
+// @Param input body domain.SetProductAttributesInput true "Attribute values"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/attributes [put]
+func (h *Handler) adminSetProductAttributes(c *gin.Context) {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.SetProductAttributesInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Attribute.SetProductAttributes(productId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// categoryAttributeIds parses the category and attribute ids of the path. It
+// sends a failed response when they are invalid.
+func categoryAttributeIds(c *gin.Context) (int, int, bool) {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	attributeId, err := strconv.Atoi(c.Param("attributeId"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return categoryId, attributeId, true
+}
@@ -16,6 +16,7 @@ import (
 // @Produce json
 // @Param page query string false "Pagination: page number"
 // @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
 // @Param search query string false "Search query param"
 // @Success 200 {object} response
 // @Failure 400,404 {object} response
@@ -38,20 +39,48 @@ func (h *Handler) getAllCategories(c *gin.Context) {
 		Fail(c, bindSearchParamErrorText, http.StatusBadRequest)
 		return
 	}
+	if err := searchParams.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	limit, offset := computePaginationParams(paginationParams)
-	categories, err := h.services.Category.GetAll(limit, offset, searchParams.Search)
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categories, pagination, err := h.services.Category.GetAll(page, searchParams.Search)
 	if err != nil {
 		FailAndHandleErr(c, err)
 		return
 	}
 
-	Response(c, categories)
+	ResponsePage(c, categories, pagination, nil)
+}
+
+// @Summary Get Category Tree
+// @Tags Сategories
+// @Description Get the available top level categories with their available subcategories, at any depth.
+// @ID get-category-tree
+// @Accept json
+// @Produce json
+// @Success 200 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /api/categories/tree [get]
+func (h *Handler) getCategoryTree(c *gin.Context) {
+	tree, err := h.services.Category.GetTree()
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, tree)
 }
 
 // @Summary Get Category By Id
 // @Tags Сategories
-// @Description Get category by id.
+// @Description Get category by id, with the breadcrumbs from its top level category.
 // @ID get-category-by-id
 // @Accept json
 // @Produce json
@@ -79,14 +108,24 @@ func (h *Handler) getCategoryById(c *gin.Context) {
 
 // @Summary Get Category Products
 // @Tags Products
-// @Description Get all products in a category.
+// @Description Get all products in a category. Searches are sorted by relevance unless another sort is set, with the matching fragments in highlight.
 // @ID get-category-products
 // @Accept json
 // @Produce json
 // @Param id path int true "Category id"
 // @Param page query string false "Pagination: page number"
 // @Param pageSize query string false "Pagination: amount of items per page"
-// @Param search query string false "Search query param"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Param search query string false "Full-text search on name and description, tolerant to typos in the name"
+// @Param attr[name] query string false "Attribute filter, comma separated values, e.g. attr[material]=cotton,wool"
+// @Param min_price query number false "Minimum price, in the currency the prices are shown in"
+// @Param max_price query number false "Maximum price, in the currency the prices are shown in"
+// @Param in_stock query boolean false "Only products in stock"
+// @Param discounted query boolean false "Only discounted products"
+// @Param category_ids query []int false "Only products of these categories" collectionFormat(multi)
+// @Param sort query string false "Sort" Enums(price_asc, price_desc, name_asc, name_desc, newest, discount, relevance, rating)
+// @Param include_subcategories query boolean false "Also list the products of the subcategories, at any depth"
+// @Param currency query string false "Currency the prices are shown in, the store currency by default. Also read from the X-Currency header"
 // @Success 200 {object} response
 // @Failure 400,404 {object} response
 // @Failure 500 {object} response
@@ -103,13 +142,16 @@ func (h *Handler) getCategoryProducts(c *gin.Context) {
 		return
 	}
 
-	var searchParams domain.SearchParams
-	if err := c.BindQuery(&searchParams); err != nil {
-		Fail(c, bindSearchParamErrorText, http.StatusBadRequest)
+	filter, ok := bindProductFilter(c)
+	if !ok {
 		return
 	}
 
-	limit, offset := computePaginationParams(paginationParams)
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	catId, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -117,13 +159,23 @@ func (h *Handler) getCategoryProducts(c *gin.Context) {
 		return
 	}
 
-	products, err := h.services.Category.GetProducts(catId, limit, offset, searchParams.Search)
+	products, pagination, err := h.services.Category.GetProducts(catId, page, filter)
 	if err != nil {
 		FailAndHandleErr(c, err)
 		return
 	}
 
-	Response(c, products)
+	facets, err := h.services.Product.GetFacets(catId, filter)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	h.recordSearch(filter, page, pagination)
+
+	rate := getExchangeRate(c)
+	facets = rate.ConvertFacets(facets)
+	ResponsePage(c, rate.ConvertProducts(products), pagination, &facets)
 }
 
 // @Summary Create Category
@@ -133,6 +185,7 @@ func (h *Handler) getCategoryProducts(c *gin.Context) {
 // @ID create-category
 // @Accept  multipart/form-data
 // @Produce json
+// @Param parent_id formData int false "Parent category id"
 // @Param name formData string true "Category name"
 // @Param description formData string true "Category description"
 // @Param available formData boolean true "Category is available"
@@ -151,6 +204,16 @@ func (h *Handler) adminCreateCategory(c *gin.Context) {
 	available := r.FormValue("available")
 	input.Available = available == "true"
 
+	parentIdField := r.FormValue("parent_id")
+	if parentIdField != "" {
+		parentId, err := strconv.Atoi(parentIdField)
+		if err != nil {
+			Fail(c, "invalid parent_id value", http.StatusBadRequest)
+			return
+		}
+		input.ParentId = &parentId
+	}
+
 	file, handler, err := r.FormFile("image_file")
 	if err != nil {
 		if err == http.ErrMissingFile {
@@ -185,6 +248,7 @@ func (h *Handler) adminCreateCategory(c *gin.Context) {
 // @Accept  multipart/form-data
 // @Produce json
 // @Param id path int true "Category id"
+// @Param parent_id formData int false "Parent category id, 0 to move the category to the top level"
 // @Param name formData string false "Category name"
 // @Param description formData string false "Category description"
 // @Param available formData boolean false "Category is available"
@@ -214,6 +278,16 @@ func (h *Handler) adminUpdateCategory(c *gin.Context) {
 		input.Description = &description
 	}
 
+	parentIdField := r.FormValue("parent_id")
+	if parentIdField != "" {
+		parentId, err := strconv.Atoi(parentIdField)
+		if err != nil {
+			Fail(c, "invalid parent_id value", http.StatusBadRequest)
+			return
+		}
+		input.ParentId = &parentId
+	}
+
 	availableField := r.FormValue("available")
 	available := availableField != "false"
 	input.Available = &available
@@ -255,3 +329,169 @@ func (h *Handler) mediaGetCategoryImage(c *gin.Context) {
 
 	c.File(filePath)
 }
+
+// @Summary Archive Category
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Archive a category, it is hidden from the shop until restored.
+// @ID archive-category
+// @Produce json
+// @Param id path int true "Category id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/categories/{id}/archive [put]
+func (h *Handler) adminArchiveCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Category.ArchiveCategory(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Restore Category
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Restore an archived category.
+// @ID restore-category
+// @Produce json
+// @Param id path int true "Category id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/categories/{id}/restore [put]
+func (h *Handler) adminRestoreCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Category.RestoreCategory(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Category
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Delete a category with the products it is the primary category of, and their media. Refused when any of these products appears in an order, archive the category instead. Its subcategories become top level categories.
+// @ID delete-category
+// @Produce json
+// @Param id path int true "Category id"
+// @Success 200 {object} response
+// @Failure 400,404,409 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/categories/{id} [delete]
+func (h *Handler) adminDeleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Category.DeleteCategory(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Admin Get Categories
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get all categories, including the unavailable and archived ones.
+// @ID admin-get-categories
+// @Accept json
+// @Produce json
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Param search query string false "Search on the name"
+// @Param available query boolean false "Only available categories when true, only unavailable ones when false"
+// @Param archived query boolean false "Only archived categories when true, only not archived ones when false"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/categories [get]
+func (h *Handler) adminGetAllCategories(c *gin.Context) {
+	var paginationParams domain.PaginationParams
+	if err := c.BindQuery(&paginationParams); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := paginationParams.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var filter domain.AdminCategoryFilterParams
+	if err := c.BindQuery(&filter); err != nil {
+		Fail(c, bindFilterParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := filter.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categories, pagination, err := h.services.Category.AdminGetAll(page, filter)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, categories, pagination, nil)
+}
+
+// @Summary Admin Get Category By Id
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get a category by id, whether available or archived.
+// @ID admin-get-category-by-id
+// @Accept json
+// @Produce json
+// @Param id path int true "Category id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/categories/{id} [get]
+func (h *Handler) adminGetCategoryById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.services.Category.AdminGetById(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, category)
+}
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Get Collections
+// @Tags Collections
+// @Description Get all available collections.
+// @ID get-collections
+// @Accept json
+// @Produce json
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /api/collections [get]
+func (h *Handler) getAllCollections(c *gin.Context) {
+	var paginationParams domain.PaginationParams
+	if err := c.BindQuery(&paginationParams); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := paginationParams.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	collections, pagination, err := h.services.Collection.GetAll(page)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, collections, pagination, nil)
+}
+
+// @Summary Get Collection By Id
+// @Tags Collections
+// @Description Get an available collection by id.
+// @ID get-collection-by-id
+// @Accept json
+// @Produce json
+// @Param id path int true "Collection id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /api/collections/{id} [get]
+func (h *Handler) getCollectionById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.services.Collection.GetById(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, collection)
+}
+
+// @Summary Get Collection Products
+// @Tags Collections
+// @Description Get the available products of a collection, in the order set by the admins.
+// @ID get-collection-products
+// @Accept json
+// @Produce json
+// @Param id path int true "Collection id"
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Param currency query string false "Currency the prices are shown in, the store currency by default. Also read from the X-Currency header"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /api/collections/{id}/products [get]
+func (h *Handler) getCollectionProducts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var paginationParams domain.PaginationParams
+	if err := c.BindQuery(&paginationParams); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := paginationParams.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	products, pagination, err := h.services.Collection.GetProducts(id, page)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, getExchangeRate(c).ConvertProducts(products), pagination, nil)
+}
+
+// @Summary Create Collection
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Create a new collection.
+// @ID create-collection
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateCollectionInput true "Collection info"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/collections [post]
+func (h *Handler) adminCreateCollection(c *gin.Context) {
+	var input domain.CreateCollectionInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.services.Collection.CreateCollection(input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OKId(c, id)
+}
+
+// @Summary Update Collection
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Update a collection.
+// @ID update-collection
+// @Accept json
+// @Produce json
+// @Param id path int true "Collection id"
+// @Param input body domain.UpdateCollectionInput true "Collection info"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/collections/{id} [put]
+func (h *Handler) adminUpdateCollection(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.UpdateCollectionInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Collection.UpdateCollection(id, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Collection
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Delete a collection. Its products are not affected.
+// @ID delete-collection
+// @Produce json
+// @Param id path int true "Collection id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/collections/{id} [delete]
+func (h *Handler) adminDeleteCollection(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Collection.DeleteCollection(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Set Collection Products
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Replace the products of a collection, they are shown in the order given.
+// @ID set-collection-products
+// @Accept json
+// @Produce json
+// @Param id path int true "Collection id"
+// @Param input body domain.SetCollectionProductsInput true "Product ids in display order"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/collections/{id}/products [put]
+func (h *Handler) adminSetCollectionProducts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.SetCollectionProductsInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Collection.SetProducts(id, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Get Currencies
+// @Tags Products
+// @Description Get the currencies the catalog prices can be shown in with their exchange rate, the store currency first.
+// @ID get-currencies
+// @Accept json
+// @Produce json
+// @Success 200 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /api/currencies [get]
+func (h *Handler) getCurrencies(c *gin.Context) {
+	rates, err := h.services.Currency.GetRates()
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, rates)
+}
+
+// @Summary Set Exchange Rates
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Create or update the exchange rates of currencies: the amount of the currency one unit of the store currency is worth. The rates of the other currencies are kept.
+// @ID set-exchange-rates
+// @Accept json
+// @Produce json
+// @Param input body domain.SetExchangeRatesInput true "Exchange rates"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/exchange-rates [put]
+func (h *Handler) adminSetExchangeRates(c *gin.Context) {
+	var input domain.SetExchangeRatesInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.services.Currency.SetRates(input); err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Import Exchange Rates
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Create or update exchange rates from a CSV file with a currency and a rate column. No rate is saved when a row is invalid.
+// @ID import-exchange-rates
+// @Accept  multipart/form-data
+// @Produce json
+// @Param file formData file true "Exchange rates file (.csv)"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/exchange-rates/import [post]
+func (h *Handler) adminImportExchangeRates(c *gin.Context) {
+	var input domain.ImportExchangeRatesInput
+
+	file, handler, err := c.Request.FormFile("file")
+	if err != nil {
+		if err == http.ErrMissingFile {
+			Fail(c, "file: can not be blank", http.StatusBadRequest)
+			return
+		}
+		FailAndHandleErr(c, err)
+		return
+	}
+	defer file.Close()
+
+	input.File = handler
+
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.services.Currency.ImportRates(file); err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Exchange Rate
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Delete the exchange rate of a currency, the prices can no longer be shown or ordered in it.
+// @ID delete-exchange-rate
+// @Produce json
+// @Param currency path string true "Currency code"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/exchange-rates/{currency} [delete]
+func (h *Handler) adminDeleteExchangeRate(c *gin.Context) {
+	if err := h.services.Currency.DeleteRate(c.Param("currency")); err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
@@ -23,9 +23,11 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"*"}
 	config.AllowMethods = []string{"GET", "PUT", "POST", "DELETE"}
-	config.AllowHeaders = []string{"Authorization"}
+	config.AllowHeaders = []string{authorizationHeader, currencyHeader}
 	router.Use(cors.New(config))
 
+	router.GET("/health", h.health)
+
 	auth := router.Group("/auth")
 	{
 		auth.POST("/sign-up", h.userSignUp)
@@ -46,14 +48,35 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			orders.GET("/", h.userGetAllOrder)
 			orders.GET("/:id", h.userGetOrderById)
 		}
+
+		reviews := profile.Group("/reviews")
+		{
+			reviews.GET("/", h.userGetAllReviews)
+			reviews.POST("/", h.userCreateReview)
+			reviews.PUT("/:id", h.userUpdateReview)
+			reviews.DELETE("/:id", h.userDeleteReview)
+			reviews.POST("/:id/report", h.userReportReview)
+		}
+
+		wishlist := profile.Group("/wishlist")
+		{
+			wishlist.GET("/", h.userGetWishlist)
+			wishlist.POST("/", h.userAddToWishlist)
+			wishlist.DELETE("/:productId", h.userRemoveFromWishlist)
+			wishlist.POST("/order", h.userOrderFromWishlist)
+		}
 	}
 
-	api := router.Group("/api")
+	api := router.Group("/api", h.displayCurrency)
 	{
+		api.GET("/currencies", h.getCurrencies)
+
 		categories := api.Group("/categories")
 		{
 			categories.GET("/", h.getAllCategories)
+			categories.GET("/tree", h.getCategoryTree)
 			categories.GET("/:id", h.getCategoryById)
+			categories.GET("/:id/attributes", h.getCategoryAttributes)
 
 			products := categories.Group(":id/products")
 			{
@@ -61,6 +84,18 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			}
 		}
 
+		collections := api.Group("/collections")
+		{
+			collections.GET("/", h.getAllCollections)
+			collections.GET("/:id", h.getCollectionById)
+			collections.GET("/:id/products", h.getCollectionProducts)
+		}
+
+		search := api.Group("/search")
+		{
+			search.GET("/suggest", h.getSearchSuggestions)
+		}
+
 		products := api.Group("/products")
 		{
 			products.GET("/", h.getAllProducts)
@@ -68,6 +103,8 @@ func (h *Handler) InitRoutes() *gin.Engine {
 
 		{
 			products.GET("/:id", h.getProductById)
+			products.GET("/:id/reviews", h.getProductReviews)
+			products.GET("/:id/related", h.getRelatedProducts)
 		}
 	}
 
@@ -75,13 +112,84 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	{
 		categories := admin.Group("/categories")
 		{
+			categories.GET("/", h.adminGetAllCategories)
+			categories.GET("/:id", h.adminGetCategoryById)
 			categories.POST("/", h.adminCreateCategory)
 			categories.PUT("/:id", h.adminUpdateCategory)
+			categories.DELETE("/:id", h.adminDeleteCategory)
+			categories.PUT("/:id/archive", h.adminArchiveCategory)
+			categories.PUT("/:id/restore", h.adminRestoreCategory)
+			categories.PUT("/:id/tax-class", h.adminSetCategoryTaxClass)
+			categories.POST("/:id/attributes", h.adminCreateAttribute)
+			categories.PUT("/:id/attributes/:attributeId", h.adminUpdateAttribute)
+			categories.DELETE("/:id/attributes/:attributeId", h.adminDeleteAttribute)
 		}
 		products := admin.Group("/products")
 		{
+			products.GET("/", h.adminGetAllProducts)
+			products.GET("/export", h.adminExportProducts)
+			products.POST("/import", h.adminImportProducts)
+			products.POST("/bulk", h.adminBulkUpdateProducts)
+			products.GET("/import/:jobId", h.adminGetImportJob)
+			products.GET("/:id", h.adminGetProductById)
 			products.POST("/", h.adminCreateProduct)
 			products.PUT("/:id", h.adminUpdateProduct)
+			products.DELETE("/:id", h.adminDeleteProduct)
+			products.PUT("/:id/archive", h.adminArchiveProduct)
+			products.PUT("/:id/restore", h.adminRestoreProduct)
+			products.PUT("/:id/attributes", h.adminSetProductAttributes)
+			products.PUT("/:id/categories", h.adminSetProductCategories)
+			products.PUT("/:id/tax-class", h.adminSetProductTaxClass)
+			products.POST("/:id/images", h.adminAddProductImage)
+			products.PUT("/:id/images/order", h.adminReorderProductImages)
+			products.PUT("/:id/images/:imageId", h.adminUpdateProductImage)
+			products.PUT("/:id/images/:imageId/primary", h.adminSetPrimaryProductImage)
+			products.DELETE("/:id/images/:imageId", h.adminDeleteProductImage)
+			products.PUT("/:id/options", h.adminSetProductOptions)
+			products.POST("/:id/variants", h.adminCreateVariant)
+			products.PUT("/:id/variants/:variantId", h.adminUpdateVariant)
+			products.DELETE("/:id/variants/:variantId", h.adminDeleteVariant)
+		}
+		collections := admin.Group("/collections")
+		{
+			collections.POST("/", h.adminCreateCollection)
+			collections.PUT("/:id", h.adminUpdateCollection)
+			collections.DELETE("/:id", h.adminDeleteCollection)
+			collections.PUT("/:id/products", h.adminSetCollectionProducts)
+		}
+		reviews := admin.Group("/reviews")
+		{
+			reviews.GET("/", h.adminGetAllReviews)
+			reviews.PUT("/:id/approve", h.adminApproveReview)
+			reviews.PUT("/:id/reject", h.adminRejectReview)
+		}
+		exchangeRates := admin.Group("/exchange-rates")
+		{
+			exchangeRates.PUT("/", h.adminSetExchangeRates)
+			exchangeRates.POST("/import", h.adminImportExchangeRates)
+			exchangeRates.DELETE("/:currency", h.adminDeleteExchangeRate)
+		}
+		taxClasses := admin.Group("/tax-classes")
+		{
+			taxClasses.GET("/", h.adminGetTaxClasses)
+			taxClasses.POST("/", h.adminCreateTaxClass)
+			taxClasses.PUT("/:id", h.adminUpdateTaxClass)
+			taxClasses.DELETE("/:id", h.adminDeleteTaxClass)
+		}
+		promoCodes := admin.Group("/promo-codes")
+		{
+			promoCodes.GET("/", h.adminGetPromoCodes)
+			promoCodes.GET("/:id", h.adminGetPromoCodeById)
+			promoCodes.POST("/", h.adminCreatePromoCode)
+			promoCodes.PUT("/:id", h.adminUpdatePromoCode)
+			promoCodes.DELETE("/:id", h.adminDeletePromoCode)
+		}
+		priceSchedules := admin.Group("/price-schedules")
+		{
+			priceSchedules.GET("/", h.adminGetPriceSchedules)
+			priceSchedules.GET("/:id", h.adminGetPriceScheduleById)
+			priceSchedules.POST("/", h.adminCreatePriceSchedule)
+			priceSchedules.PUT("/:id/cancel", h.adminCancelPriceSchedule)
 		}
 	}
 
@@ -99,6 +207,10 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		{
 			products.GET("/:id/:file-name", h.mediaGetProductImage)
 		}
+		variants := media.Group("/variants")
+		{
+			variants.GET("/:id/:file-name", h.mediaGetVariantImage)
+		}
 	}
 	return router
 }
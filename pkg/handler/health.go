@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Health Check
+// @Tags Health
+// @Description Get the service status and the database schema version. The status is "schema_mismatch" when the schema version differs from the one the binary was built with.
+// @ID health
+// @Produce json
+// @Success 200 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /health [get]
+func (h *Handler) health(c *gin.Context) {
+	health, err := h.services.Health.Check()
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, health)
+}
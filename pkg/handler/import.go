@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Export Products
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Export the whole catalog, archived and unavailable products included, as a CSV or JSON lines file.
+// @ID export-products
+// @Produce text/csv,application/jsonl
+// @Param format query string false "Export format, csv by default" Enums(csv, jsonl)
+// @Success 200 {file} file
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/export [get]
+func (h *Handler) adminExportProducts(c *gin.Context) {
+	var params domain.ExportParams
+	if err := c.BindQuery(&params); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := params.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := params.Format
+	contentType := "text/csv"
+	if format == domain.CatalogFormatJSONL {
+		contentType = "application/jsonl"
+	} else {
+		format = domain.CatalogFormatCSV
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=products.%s", format))
+
+	if err := h.services.Import.ExportProducts(format, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			FailAndHandleErr(c, err)
+			return
+		}
+		logrus.Errorf("export products: %s", err.Error())
+	}
+}
+
+// @Summary Import Products
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Import products from a CSV or JSON lines file, or a zip archive holding one with the product images. Products are created or updated by SKU in the background, follow the returned job for the row errors.
+// @ID import-products
+// @Accept  multipart/form-data
+// @Produce json
+// @Param file formData file true "Import file (.csv, .jsonl or .zip)"
+// @Param dry_run formData boolean false "Only validate the rows, no product is saved"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/import [post]
+func (h *Handler) adminImportProducts(c *gin.Context) {
+	r := c.Request
+	var input domain.ImportProductsInput
+
+	input.DryRun = r.FormValue("dry_run") == "true"
+
+	file, handler, err := r.FormFile("file")
+	if err != nil {
+		if err == http.ErrMissingFile {
+			Fail(c, "file: can not be blank", http.StatusBadRequest)
+			return
+		}
+		FailAndHandleErr(c, err)
+		return
+	}
+	defer file.Close()
+
+	input.File = handler
+
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.services.Import.ImportProducts(input, file)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OKId(c, id)
+}
+
+// @Summary Get Import Job
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get the status of a product import, with its row errors.
+// @ID get-import-job
+// @Accept json
+// @Produce json
+// @Param jobId path int true "Import job id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/import/{jobId} [get]
+func (h *Handler) adminGetImportJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("jobId"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.services.Import.GetJob(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, job)
+}
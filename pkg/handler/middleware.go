@@ -12,7 +12,9 @@ import (
 
 const (
 	authorizationHeader = "Authorization"
+	currencyHeader      = "X-Currency"
 	userCtx             = "UserId"
+	currencyCtx         = "Currency"
 )
 
 func (h *Handler) adminIdentity(c *gin.Context) {
@@ -37,7 +39,22 @@ func (h *Handler) adminIdentity(c *gin.Context) {
 
 	adminSecret := os.Getenv("ADMIN_SECRET")
 
-	if headerParts[1] != adminSecret {
+	if headerParts[1] == adminSecret {
+		return
+	}
+
+	userId, err := h.services.Authorization.ParseAuthToken(headerParts[1])
+	if err != nil {
+		Fail(c, "invalid authorization header", http.StatusUnauthorized)
+		return
+	}
+
+	isAdmin, err := h.services.Authorization.IsAdmin(userId)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+	if !isAdmin {
 		Fail(c, "invalid authorization header", http.StatusUnauthorized)
 		return
 	}
@@ -89,13 +106,51 @@ func getUserId(c *gin.Context) (int, error) {
 	return idInt, nil
 }
 
-func computePaginationParams(params domain.PaginationParams) (limit, offset int) {
-	if params.Page == 0 || params.PageSize == 0 {
-		limit = 10
-		offset = 0
-	} else {
-		limit = params.PageSize
-		offset = (params.Page - 1) * params.PageSize
+// displayCurrency resolves the currency the catalog prices are shown in, given
+// by the currency query param or the X-Currency header. The store currency is
+// used when neither is set.
+func (h *Handler) displayCurrency(c *gin.Context) {
+	currency := c.Query("currency")
+	if currency == "" {
+		currency = c.GetHeader(currencyHeader)
+	}
+
+	rate, err := h.services.Currency.GetRate(currency)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	c.Set(currencyCtx, rate)
+}
+
+// getExchangeRate returns the rate resolved by displayCurrency, which
+// converts the prices to themselves when it did not run.
+func getExchangeRate(c *gin.Context) domain.ExchangeRate {
+	if rate, ok := c.Get(currencyCtx); ok {
+		if exchangeRate, ok := rate.(domain.ExchangeRate); ok {
+			return exchangeRate
+		}
+	}
+	return domain.ExchangeRate{Rate: domain.OneRate}
+}
+
+// computePageRequest returns the page selected by the pagination params, the
+// first 10 items by default.
+func computePageRequest(params domain.PaginationParams) (domain.PageRequest, error) {
+	page := domain.PageRequest{Limit: 10}
+	if params.PageSize != 0 {
+		page.Limit = params.PageSize
+	}
+	if params.Page != 0 {
+		page.Offset = (params.Page - 1) * page.Limit
+	}
+	if params.Cursor != "" {
+		cursor, err := domain.DecodeCursor(params.Cursor)
+		if err != nil {
+			return page, err
+		}
+		page.Cursor = cursor
 	}
-	return limit, offset
+	return page, nil
 }
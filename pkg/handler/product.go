@@ -3,20 +3,31 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/sirupsen/logrus"
 )
 
 // @Summary Get Products
 // @Tags Products
-// @Description Get all products.
+// @Description Get all products. Searches are sorted by relevance unless another sort is set, with the matching fragments in highlight.
 // @ID get-products
 // @Accept json
 // @Produce json
 // @Param page query string false "Pagination: page number"
 // @Param pageSize query string false "Pagination: amount of items per page"
-// @Param search query string false "Search query param"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Param search query string false "Full-text search on name and description, tolerant to typos in the name"
+// @Param attr[name] query string false "Attribute filter, comma separated values, e.g. attr[material]=cotton,wool"
+// @Param min_price query number false "Minimum price, in the currency the prices are shown in"
+// @Param max_price query number false "Maximum price, in the currency the prices are shown in"
+// @Param in_stock query boolean false "Only products in stock"
+// @Param discounted query boolean false "Only discounted products"
+// @Param category_ids query []int false "Only products of these categories" collectionFormat(multi)
+// @Param sort query string false "Sort" Enums(price_asc, price_desc, name_asc, name_desc, newest, discount, relevance, rating)
+// @Param currency query string false "Currency the prices are shown in, the store currency by default. Also read from the X-Currency header"
 // @Success 200 {object} response
 // @Failure 400,404 {object} response
 // @Failure 500 {object} response
@@ -33,20 +44,79 @@ func (h *Handler) getAllProducts(c *gin.Context) {
 		return
 	}
 
-	var searchParams domain.SearchParams
-	if err := c.BindQuery(&searchParams); err != nil {
-		Fail(c, bindSearchParamErrorText, http.StatusBadRequest)
+	filter, ok := bindProductFilter(c)
+	if !ok {
 		return
 	}
 
-	limit, offset := computePaginationParams(paginationParams)
-	categories, err := h.services.Product.GetAll(limit, offset, searchParams.Search)
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	products, pagination, err := h.services.Product.GetAll(page, filter)
 	if err != nil {
 		FailAndHandleErr(c, err)
 		return
 	}
 
-	Response(c, categories)
+	facets, err := h.services.Product.GetFacets(0, filter)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	h.recordSearch(filter, page, pagination)
+
+	rate := getExchangeRate(c)
+	facets = rate.ConvertFacets(facets)
+	ResponsePage(c, rate.ConvertProducts(products), pagination, &facets)
+}
+
+// recordSearch counts the search of a product listing when its first page
+// was requested and products were found, so it can be suggested to others.
+// Failures are only logged, they must not fail the listing.
+func (h *Handler) recordSearch(filter domain.ProductFilter, page domain.PageRequest, pagination domain.Pagination) {
+	if filter.Search == "" || page.Cursor != nil || page.Offset != 0 || pagination.Total == 0 {
+		return
+	}
+	if err := h.services.Search.RecordQuery(filter.Search); err != nil {
+		logrus.Errorf("record search: %s", err.Error())
+	}
+}
+
+// bindProductFilter reads the search, the filters and the sort of a product
+// listing. It sends a failed response when they are invalid.
+func bindProductFilter(c *gin.Context) (domain.ProductFilter, bool) {
+	var searchParams domain.SearchParams
+	if err := c.BindQuery(&searchParams); err != nil {
+		Fail(c, bindSearchParamErrorText, http.StatusBadRequest)
+		return domain.ProductFilter{}, false
+	}
+
+	var filterParams domain.ProductFilterParams
+	if err := c.BindQuery(&filterParams); err != nil {
+		Fail(c, bindFilterParamsErrorText, http.StatusBadRequest)
+		return domain.ProductFilter{}, false
+	}
+
+	filter := domain.ProductFilter{ProductFilterParams: filterParams, Search: searchParams.Search}
+	for name, values := range c.QueryMap("attr") {
+		if filter.Attributes == nil {
+			filter.Attributes = make(map[string][]string)
+		}
+		for _, value := range strings.Split(values, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				filter.Attributes[name] = append(filter.Attributes[name], value)
+			}
+		}
+	}
+
+	if err := filter.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return filter, false
+	}
+	return getExchangeRate(c).RevertFilter(filter), true
 }
 
 // @Summary Get Product By Id
@@ -56,6 +126,7 @@ func (h *Handler) getAllProducts(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Product id"
+// @Param currency query string false "Currency the prices are shown in, the store currency by default. Also read from the X-Currency header"
 // @Success 200 {object} response
 // @Failure 400,404 {object} response
 // @Failure 500 {object} response
@@ -74,7 +145,36 @@ func (h *Handler) getProductById(c *gin.Context) {
 		return
 	}
 
-	Response(c, category)
+	Response(c, getExchangeRate(c).ConvertProduct(category))
+}
+
+// @Summary Get Related Products
+// @Tags Products
+// @Description Get the products customers also bought with a product, completed with products of its category when there are not enough.
+// @ID get-related-products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product id"
+// @Param currency query string false "Currency the prices are shown in, the store currency by default. Also read from the X-Currency header"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /api/products/{id}/related [get]
+func (h *Handler) getRelatedProducts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	products, err := h.services.Related.GetRelated(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, getExchangeRate(c).ConvertProducts(products))
 }
 
 // @Summary Create Product
@@ -85,6 +185,7 @@ func (h *Handler) getProductById(c *gin.Context) {
 // @Accept  multipart/form-data
 // @Produce json
 // @Param category_id formData int true "Category id"
+// @Param sku formData string false "Product SKU, identifies the product in catalog imports"
 // @Param name formData string true "Product name"
 // @Param description formData string true "Product description"
 // @Param price formData number true "Product actual price"
@@ -108,6 +209,7 @@ func (h *Handler) adminCreateProduct(c *gin.Context) {
 	}
 	input.CategoryId = catIdInt
 
+	input.Sku = r.FormValue("sku")
 	input.Name = r.FormValue("name")
 	input.Description = r.FormValue("description")
 
@@ -121,19 +223,17 @@ func (h *Handler) adminCreateProduct(c *gin.Context) {
 	}
 	input.Stock = stock
 
-	price, err := strconv.ParseFloat(r.FormValue("price"), 32)
+	input.Price, err = domain.ParseMoney(r.FormValue("price"))
 	if err != nil {
 		Fail(c, "invalid price value", http.StatusBadRequest)
 		return
 	}
-	input.Price = float32(price)
 
-	price, err = strconv.ParseFloat(r.FormValue("undiscounted_price"), 32)
+	input.UndiscountedPrice, err = domain.ParseMoney(r.FormValue("undiscounted_price"))
 	if err != nil {
 		Fail(c, "invalid undiscounted_price value", http.StatusBadRequest)
 		return
 	}
-	input.UndiscountedPrice = float32(price)
 
 	file, handler, err := r.FormFile("image_file")
 	if err != nil {
@@ -171,6 +271,7 @@ func (h *Handler) adminCreateProduct(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Product id"
 // @Param category_id formData int false "Category id"
+// @Param sku formData string false "Product SKU, identifies the product in catalog imports"
 // @Param name formData string false "Product name"
 // @Param description formData string false "Product description"
 // @Param price formData number false "Product actual price"
@@ -202,6 +303,11 @@ func (h *Handler) adminUpdateProduct(c *gin.Context) {
 		input.CategoryId = &catIdInt
 	}
 
+	sku := r.FormValue("sku")
+	if sku != "" {
+		input.Sku = &sku
+	}
+
 	name := r.FormValue("name")
 	if name != "" {
 		input.Name = &name
@@ -227,23 +333,19 @@ func (h *Handler) adminUpdateProduct(c *gin.Context) {
 
 	price := r.FormValue("price")
 	if price != "" {
-		price64, err := strconv.ParseFloat(price, 32)
+		price, err := domain.ParseMoney(price)
 		if err != nil {
 			Fail(c, "invalid price value", http.StatusBadRequest)
 		}
-
-		price := float32(price64)
 		input.Price = &price
 	}
 
 	undiscountedPrice := r.FormValue("undiscounted_price")
 	if undiscountedPrice != "" {
-		price64, err := strconv.ParseFloat(undiscountedPrice, 32)
+		price, err := domain.ParseMoney(undiscountedPrice)
 		if err != nil {
 			Fail(c, "invalid price value", http.StatusBadRequest)
 		}
-
-		price := float32(price64)
 		input.UndiscountedPrice = &price
 	}
 
@@ -283,3 +385,244 @@ func (h *Handler) mediaGetProductImage(c *gin.Context) {
 
 	c.File(filePath)
 }
+
+// @Summary Set Product Categories
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Replace the additional categories a product is listed in, besides its primary category.
+// @ID set-product-categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Product id"
+// @Param input body domain.SetProductCategoriesInput true "Category ids"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/categories [put]
+func (h *Handler) adminSetProductCategories(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.SetProductCategoriesInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Product.SetCategories(id, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Bulk Update Products
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Set or adjust by percent the price, set the availability, move to a category or adjust the stock of the products listed by id or matched by a filter, all at once. A preview returns the changes without saving them, an update leaving any product invalid saves none.
+// @ID bulk-update-products
+// @Accept json
+// @Produce json
+// @Param input body domain.BulkUpdateProductsInput true "Products and operations"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/bulk [post]
+func (h *Handler) adminBulkUpdateProducts(c *gin.Context) {
+	var input domain.BulkUpdateProductsInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.services.Product.BulkUpdate(input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, result)
+}
+
+// @Summary Archive Product
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Archive a product, it is hidden from the shop and can no longer be ordered until restored.
+// @ID archive-product
+// @Produce json
+// @Param id path int true "Product id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/archive [put]
+func (h *Handler) adminArchiveProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Product.ArchiveProduct(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Restore Product
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Restore an archived product.
+// @ID restore-product
+// @Produce json
+// @Param id path int true "Product id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/restore [put]
+func (h *Handler) adminRestoreProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Product.RestoreProduct(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Product
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Delete a product and its media. Refused when the product appears in an order, archive it instead.
+// @ID delete-product
+// @Produce json
+// @Param id path int true "Product id"
+// @Success 200 {object} response
+// @Failure 400,404,409 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id} [delete]
+func (h *Handler) adminDeleteProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Product.DeleteProduct(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Admin Get Products
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get all products, including the unavailable, out of stock and archived ones.
+// @ID admin-get-products
+// @Accept json
+// @Produce json
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Param search query string false "Search on the name"
+// @Param category_id query int false "Only products of this category"
+// @Param stock query string false "Stock level, of the product and all its variants; low is 1 to 5 items" Enums(in_stock, low, out_of_stock)
+// @Param available query boolean false "Only available products when true, only unavailable ones when false"
+// @Param archived query boolean false "Only archived products when true, only not archived ones when false"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products [get]
+func (h *Handler) adminGetAllProducts(c *gin.Context) {
+	var paginationParams domain.PaginationParams
+	if err := c.BindQuery(&paginationParams); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := paginationParams.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var filter domain.AdminProductFilterParams
+	if err := c.BindQuery(&filter); err != nil {
+		Fail(c, bindFilterParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := filter.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	products, pagination, err := h.services.Product.AdminGetAll(page, filter)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, products, pagination, nil)
+}
+
+// @Summary Admin Get Product By Id
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get a product by id, whether available or archived, with all its variants.
+// @ID admin-get-product-by-id
+// @Accept json
+// @Produce json
+// @Param id path int true "Product id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id} [get]
+func (h *Handler) adminGetProductById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.services.Product.AdminGetById(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, product)
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Add Product Image
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Append an image to the product gallery. The first image of the gallery is the product primary image.
+// @ID add-product-image
+// @Accept  multipart/form-data
+// @Produce json
+// @Param id path int true "Product id"
+// @Param image_file formData file true "Product image"
+// @Param alt_text formData string false "Image alternative text"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/images [post]
+func (h *Handler) adminAddProductImage(c *gin.Context) {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	r := c.Request
+	var input domain.AddProductImageInput
+
+	input.AltText = r.FormValue("alt_text")
+
+	file, handler, err := r.FormFile("image_file")
+	if err != nil {
+		if err == http.ErrMissingFile {
+			Fail(c, "image_file: can not be blank", http.StatusBadRequest)
+			return
+		}
+		FailAndHandleErr(c, err)
+		return
+	}
+	defer file.Close()
+
+	input.ImgFile = handler
+
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.services.ProductImage.AddImage(productId, input, file)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OKId(c, id)
+}
+
+// @Summary Update Product Image
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Update the alternative text of a product image.
+// @ID update-product-image
+// @Accept json
+// @Produce json
+// @Param id path int true "Product id"
+// @Param imageId path int true "Image id"
+// @Param input body domain.UpdateProductImageInput true "Image data"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/images/{imageId} [put]
+func (h *Handler) adminUpdateProductImage(c *gin.Context) {
+	productId, imageId, ok := productImageIds(c)
+	if !ok {
+		return
+	}
+
+	var input domain.UpdateProductImageInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.services.ProductImage.UpdateImage(productId, imageId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Reorder Product Images
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Order the product gallery. Every image id of the product must be listed, the first one becomes the primary image.
+// @ID reorder-product-images
+// @Accept json
+// @Produce json
+// @Param id path int true "Product id"
+// @Param input body domain.ReorderProductImagesInput true "Image ids in the new order"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/images/order [put]
+func (h *Handler) adminReorderProductImages(c *gin.Context) {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.ReorderProductImagesInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.ProductImage.ReorderImages(productId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Set Primary Product Image
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Make an image the primary image of the product.
+// @ID set-primary-product-image
+// @Produce json
+// @Param id path int true "Product id"
+// @Param imageId path int true "Image id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/images/{imageId}/primary [put]
+func (h *Handler) adminSetPrimaryProductImage(c *gin.Context) {
+	productId, imageId, ok := productImageIds(c)
+	if !ok {
+		return
+	}
+
+	err := h.services.ProductImage.SetPrimaryImage(productId, imageId)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Product Image
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Delete an image of the product gallery.
+// @ID delete-product-image
+// @Produce json
+// @Param id path int true "Product id"
+// @Param imageId path int true "Image id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/images/{imageId} [delete]
+func (h *Handler) adminDeleteProductImage(c *gin.Context) {
+	productId, imageId, ok := productImageIds(c)
+	if !ok {
+		return
+	}
+
+	err := h.services.ProductImage.DeleteImage(productId, imageId)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// productImageIds parses the product and image ids of the path. It sends a
+// failed response when they are invalid.
+func productImageIds(c *gin.Context) (int, int, bool) {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	imageId, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return productId, imageId, true
+}
@@ -90,7 +90,7 @@ func (h *Handler) updateUserProfile(c *gin.Context) {
 // @Summary Create Order
 // @Security ApiKeyAuth
 // @Tags User Profile
-// @Description Create a new order. If the request is successful, a new order is added to the user's list of orders and the stock of products in the catalog is updated.
+// @Description Create a new order. If the request is successful, a new order is added to the user's list of orders and the stock of products in the catalog is updated. With a currency, the prices are converted with its current exchange rate, recorded in the order. Each line is taxed for the tax region, the default one when not given. A promo_code discounts the eligible lines before tax, it must be active, valid and within its usage limits.
 // @ID create-order
 // @Accept json
 // @Produce json
@@ -115,7 +115,7 @@ func (h *Handler) userCreateOrder(c *gin.Context) {
 		return
 	}
 	input.Sort()
-	id, err := h.services.Profile.CreateOrder(userId, input.Products)
+	id, err := h.services.Profile.CreateOrder(userId, input)
 
 	if err != nil {
 		FailAndHandleErr(c, err)
@@ -133,6 +133,7 @@ func (h *Handler) userCreateOrder(c *gin.Context) {
 // @Produce json
 // @Param page query string false "Pagination: page number"
 // @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
 // @Success 200 {object} response
 // @Failure 400,404 {object} response
 // @Failure 500 {object} response
@@ -154,14 +155,18 @@ func (h *Handler) userGetAllOrder(c *gin.Context) {
 		return
 	}
 
-	limit, offset := computePaginationParams(params)
-	orders, err := h.services.Profile.GetAllOrders(userId, limit, offset)
+	page, err := computePageRequest(params)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	orders, pagination, err := h.services.Profile.GetAllOrders(userId, page)
 
 	if err != nil {
 		FailAndHandleErr(c, err)
 		return
 	}
-	Response(c, orders)
+	ResponsePage(c, orders, pagination, nil)
 }
 
 // @Summary Get Order By Id
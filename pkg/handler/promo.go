@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Get Promo Codes
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get the promo codes, last created first, with their usage count.
+// @ID get-promo-codes
+// @Produce json
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/promo-codes [get]
+func (h *Handler) adminGetPromoCodes(c *gin.Context) {
+	var paginationParams domain.PaginationParams
+	if err := c.BindQuery(&paginationParams); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := paginationParams.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	codes, pagination, err := h.services.Promo.GetAll(page)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, codes, pagination, nil)
+}
+
+// @Summary Get Promo Code By Id
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get a promo code by id, with the products and categories it is restricted to.
+// @ID get-promo-code-by-id
+// @Produce json
+// @Param id path int true "Promo code id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/promo-codes/{id} [get]
+func (h *Handler) adminGetPromoCodeById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	promo, err := h.services.Promo.GetById(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, promo)
+}
+
+// @Summary Create Promo Code
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Create a promo code discounting a percent or a fixed amount, in the store currency, of the eligible products of an order. Without product_ids nor category_ids all the products are eligible. The code is not case sensitive.
+// @ID create-promo-code
+// @Accept json
+// @Produce json
+// @Param input body domain.PromoCodeInput true "Promo code"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/promo-codes [post]
+func (h *Handler) adminCreatePromoCode(c *gin.Context) {
+	var input domain.PromoCodeInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.services.Promo.CreatePromoCode(input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OKId(c, id)
+}
+
+// @Summary Update Promo Code
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Replace a promo code and its restrictions, its usage count is kept and the orders already placed keep their discount.
+// @ID update-promo-code
+// @Accept json
+// @Produce json
+// @Param id path int true "Promo code id"
+// @Param input body domain.PromoCodeInput true "Promo code"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/promo-codes/{id} [put]
+func (h *Handler) adminUpdatePromoCode(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.PromoCodeInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Promo.UpdatePromoCode(id, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Promo Code
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Delete a promo code, the orders placed with it keep its code and discount.
+// @ID delete-promo-code
+// @Produce json
+// @Param id path int true "Promo code id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/promo-codes/{id} [delete]
+func (h *Handler) adminDeletePromoCode(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Promo.DeletePromoCode(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
@@ -2,17 +2,23 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
 	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
 	"github.com/sirupsen/logrus"
 )
 
 type response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+	Success    bool               `json:"success"`
+	Message    string             `json:"message,omitempty"`
+	Data       interface{}        `json:"data,omitempty"`
+	Pagination *domain.Pagination `json:"pagination,omitempty"`
+	Facets     *domain.Facets     `json:"facets,omitempty"`
 }
 
 func newResponse(success bool, message string, data interface{}) *response {
@@ -28,6 +34,42 @@ func Response(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, newResponse(true, "", data))
 }
 
+// ResponsePage send a successful response with a page of a listing, its
+// pagination and, for product listings, its facet counts. The next, prev,
+// first and last pages are also linked in the Link header.
+func ResponsePage(c *gin.Context, data interface{}, pagination domain.Pagination, facets *domain.Facets) {
+	links := make([]string, 0, 4)
+	if pagination.NextCursor != "" {
+		links = append(links, pageLink(c, "cursor", pagination.NextCursor, "next"))
+	}
+	if pagination.PrevCursor != "" {
+		links = append(links, pageLink(c, "cursor", pagination.PrevCursor, "prev"))
+	}
+	links = append(links, pageLink(c, "page", "1", "first"))
+	if pagination.PageSize > 0 && pagination.Total > 0 {
+		last := (pagination.Total + pagination.PageSize - 1) / pagination.PageSize
+		links = append(links, pageLink(c, "page", strconv.Itoa(last), "last"))
+	}
+	c.Header("Link", strings.Join(links, ", "))
+
+	resp := newResponse(true, "", data)
+	resp.Pagination = &pagination
+	resp.Facets = facets
+	c.JSON(http.StatusOK, resp)
+}
+
+// pageLink returns a Link header value pointing to the current listing with
+// its page or cursor param replaced.
+func pageLink(c *gin.Context, param, value, rel string) string {
+	u := *c.Request.URL
+	query := u.Query()
+	query.Del("page")
+	query.Del("cursor")
+	query.Set(param, value)
+	u.RawQuery = query.Encode()
+	return fmt.Sprintf("<%s>; rel=\"%s\"", u.RequestURI(), rel)
+}
+
 // OK send a successful response without body.
 func OK(c *gin.Context) {
 	c.JSON(http.StatusOK, newResponse(true, "", nil))
@@ -75,6 +117,8 @@ func errTypeStatusCode(errType errors_handler.Type) int {
 		return http.StatusForbidden
 	case errors_handler.TypeUnauthorized:
 		return http.StatusUnauthorized
+	case errors_handler.TypeConflict:
+		return http.StatusConflict
 	default:
 		return http.StatusBadRequest
 	}
@@ -84,5 +128,6 @@ const (
 	bindJSONErrorText             = "invalid request body"
 	bindPaginationParamsErrorText = "invalid pagination param"
 	bindSearchParamErrorText      = "invalid search param"
+	bindFilterParamsErrorText     = "invalid filter param"
 	invalidIdErrorText            = "invalid id param"
 )
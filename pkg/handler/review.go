@@ -0,0 +1,372 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Get Product Reviews
+// @Tags Reviews
+// @Description Get the published reviews of a product, newest first.
+// @ID get-product-reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Product id"
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /api/products/{id}/reviews [get]
+func (h *Handler) getProductReviews(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var paginationParams domain.PaginationParams
+	if err := c.BindQuery(&paginationParams); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := paginationParams.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reviews, pagination, err := h.services.Review.GetProductReviews(id, page)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, reviews, pagination, nil)
+}
+
+// @Summary Get User Reviews
+// @Security ApiKeyAuth
+// @Tags User Profile
+// @Description Get the reviews written by the user, newest first.
+// @ID get-user-reviews
+// @Accept json
+// @Produce json
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /profile/reviews [get]
+func (h *Handler) userGetAllReviews(c *gin.Context) {
+	var params domain.PaginationParams
+	if err := c.BindQuery(&params); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := params.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	page, err := computePageRequest(params)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reviews, pagination, err := h.services.Review.GetUserReviews(userId, page)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, reviews, pagination, nil)
+}
+
+// @Summary Create Review
+// @Security ApiKeyAuth
+// @Tags User Profile
+// @Description Review a purchased product with a 1 to 5 star rating. A product is reviewed once, the review can be edited afterwards. Depending on the moderation policy the review is published right away or once approved by an admin.
+// @ID create-review
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateReviewInput true "Review"
+// @Success 200 {object} response
+// @Failure 400,403,404,409 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /profile/reviews [post]
+func (h *Handler) userCreateReview(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input domain.CreateReviewInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.services.Review.CreateReview(userId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OKId(c, id)
+}
+
+// @Summary Update Review
+// @Security ApiKeyAuth
+// @Tags User Profile
+// @Description Update a review written by the user, a new text is moderated again.
+// @ID update-review
+// @Accept json
+// @Produce json
+// @Param id path int true "Review id"
+// @Param input body domain.UpdateReviewInput true "Review"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /profile/reviews/{id} [put]
+func (h *Handler) userUpdateReview(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+	reviewId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.UpdateReviewInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Review.UpdateReview(userId, reviewId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Review
+// @Security ApiKeyAuth
+// @Tags User Profile
+// @Description Delete a review written by the user.
+// @ID delete-review
+// @Accept json
+// @Produce json
+// @Param id path int true "Review id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /profile/reviews/{id} [delete]
+func (h *Handler) userDeleteReview(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+	reviewId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Review.DeleteReview(userId, reviewId)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Report Review
+// @Security ApiKeyAuth
+// @Tags User Profile
+// @Description Report a review of another user as abusive. Reviews reported by several users are taken down until an admin moderates them.
+// @ID report-review
+// @Accept json
+// @Produce json
+// @Param id path int true "Review id"
+// @Param input body domain.ReportReviewInput true "Report"
+// @Success 200 {object} response
+// @Failure 400,404,409 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /profile/reviews/{id}/report [post]
+func (h *Handler) userReportReview(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+	reviewId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.ReportReviewInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Review.ReportReview(userId, reviewId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Admin Get Reviews
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get the reviews of any status, oldest first, with their report counts. The moderation queue is the pending reviews.
+// @ID admin-get-reviews
+// @Accept json
+// @Produce json
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Param status query string false "Review status" Enums(pending, approved, rejected)
+// @Param product_id query int false "Product id"
+// @Param reported query boolean false "Only the reported reviews"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/reviews [get]
+func (h *Handler) adminGetAllReviews(c *gin.Context) {
+	var paginationParams domain.PaginationParams
+	if err := c.BindQuery(&paginationParams); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := paginationParams.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var filter domain.AdminReviewFilterParams
+	if err := c.BindQuery(&filter); err != nil {
+		Fail(c, bindFilterParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := filter.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reviews, pagination, err := h.services.Review.AdminGetAll(page, filter)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, reviews, pagination, nil)
+}
+
+// @Summary Approve Review
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Approve a review, it is published and its reports are cleared.
+// @ID approve-review
+// @Accept json
+// @Produce json
+// @Param id path int true "Review id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/reviews/{id}/approve [put]
+func (h *Handler) adminApproveReview(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Review.ApproveReview(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Reject Review
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Reject a review, it is no longer shown.
+// @ID reject-review
+// @Accept json
+// @Produce json
+// @Param id path int true "Review id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/reviews/{id}/reject [put]
+func (h *Handler) adminRejectReview(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Review.RejectReview(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
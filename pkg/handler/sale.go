@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Get Price Schedules
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get the scheduled sales, the last starting first.
+// @ID get-price-schedules
+// @Produce json
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Param status query string false "Schedule status" Enums(scheduled, active, ended, cancelled)
+// @Param product_id query int false "Product id"
+// @Param category_id query int false "Category id"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/price-schedules [get]
+func (h *Handler) adminGetPriceSchedules(c *gin.Context) {
+	var paginationParams domain.PaginationParams
+	if err := c.BindQuery(&paginationParams); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := paginationParams.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var filter domain.PriceScheduleFilterParams
+	if err := c.BindQuery(&filter); err != nil {
+		Fail(c, bindFilterParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := filter.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := computePageRequest(paginationParams)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schedules, pagination, err := h.services.Sale.GetAll(page, filter)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, schedules, pagination, nil)
+}
+
+// @Summary Get Price Schedule By Id
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get a scheduled sale by id.
+// @ID get-price-schedule-by-id
+// @Produce json
+// @Param id path int true "Price schedule id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/price-schedules/{id} [get]
+func (h *Handler) adminGetPriceScheduleById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.services.Sale.GetById(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, schedule)
+}
+
+// @Summary Create Price Schedule
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Schedule a sale taking a percent off the price of a product, or of the products listed in a category, and of their variants. The prices are lowered when it starts and restored when it ends, a price changed by hand during the sale is kept. A product already in a sale when another one starts stays in the first one.
+// @ID create-price-schedule
+// @Accept json
+// @Produce json
+// @Param input body domain.PriceScheduleInput true "Price schedule"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/price-schedules [post]
+func (h *Handler) adminCreatePriceSchedule(c *gin.Context) {
+	var input domain.PriceScheduleInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.services.Sale.CreateSchedule(input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OKId(c, id)
+}
+
+// @Summary Cancel Price Schedule
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Cancel a scheduled sale. The prices of a sale already started are restored right away.
+// @ID cancel-price-schedule
+// @Produce json
+// @Param id path int true "Price schedule id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/price-schedules/{id}/cancel [put]
+func (h *Handler) adminCancelPriceSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Sale.CancelSchedule(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Get Search Suggestions
+// @Tags Search
+// @Description Get the product names, categories and popular searches completing a search being typed.
+// @ID get-search-suggestions
+// @Accept json
+// @Produce json
+// @Param q query string true "Search being typed"
+// @Param limit query int false "Maximum number of suggestions of each kind, 5 by default"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /api/search/suggest [get]
+func (h *Handler) getSearchSuggestions(c *gin.Context) {
+	var params domain.SuggestParams
+	if err := c.BindQuery(&params); err != nil {
+		Fail(c, bindSearchParamErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := params.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	suggestions, err := h.services.Search.Suggest(params)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	// Suggestions are requested on every keystroke, let clients and proxies
+	// reuse them for a moment.
+	c.Header("Cache-Control", "public, max-age=60")
+	Response(c, suggestions)
+}
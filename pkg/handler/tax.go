@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Get Tax Classes
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Get the tax classes with their rate for each region.
+// @ID get-tax-classes
+// @Produce json
+// @Success 200 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/tax-classes [get]
+func (h *Handler) adminGetTaxClasses(c *gin.Context) {
+	classes, err := h.services.Tax.GetClasses()
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	Response(c, classes)
+}
+
+// @Summary Create Tax Class
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Create a tax class with its rates. A rate applies to a country (FR), a subdivision (US-CA) or any region (*), the most specific one for the region of the order is used.
+// @ID create-tax-class
+// @Accept json
+// @Produce json
+// @Param input body domain.TaxClassInput true "Tax class"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/tax-classes [post]
+func (h *Handler) adminCreateTaxClass(c *gin.Context) {
+	var input domain.TaxClassInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.services.Tax.CreateClass(input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OKId(c, id)
+}
+
+// @Summary Update Tax Class
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Rename a tax class and replace its rates, the orders already placed keep the rates they were taxed at.
+// @ID update-tax-class
+// @Accept json
+// @Produce json
+// @Param id path int true "Tax class id"
+// @Param input body domain.TaxClassInput true "Tax class"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/tax-classes/{id} [put]
+func (h *Handler) adminUpdateTaxClass(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.TaxClassInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Tax.UpdateClass(id, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Tax Class
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Delete a tax class, the products and categories it was assigned to are no longer taxed by it.
+// @ID delete-tax-class
+// @Produce json
+// @Param id path int true "Tax class id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/tax-classes/{id} [delete]
+func (h *Handler) adminDeleteTaxClass(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Tax.DeleteClass(id)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Set Product Tax Class
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Assign a tax class to a product, it overrides the class of its primary category. A null tax_class_id removes it.
+// @ID set-product-tax-class
+// @Accept json
+// @Produce json
+// @Param id path int true "Product id"
+// @Param input body domain.SetTaxClassInput true "Tax class id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/tax-class [put]
+func (h *Handler) adminSetProductTaxClass(c *gin.Context) {
+	id, input, ok := bindTaxClassAssignment(c)
+	if !ok {
+		return
+	}
+
+	err := h.services.Tax.SetProductTaxClass(id, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Set Category Tax Class
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Assign a tax class to the products of a category without a class of their own. A null tax_class_id removes it.
+// @ID set-category-tax-class
+// @Accept json
+// @Produce json
+// @Param id path int true "Category id"
+// @Param input body domain.SetTaxClassInput true "Tax class id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/categories/{id}/tax-class [put]
+func (h *Handler) adminSetCategoryTaxClass(c *gin.Context) {
+	id, input, ok := bindTaxClassAssignment(c)
+	if !ok {
+		return
+	}
+
+	err := h.services.Tax.SetCategoryTaxClass(id, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// bindTaxClassAssignment reads the id of the product or category and the
+// tax class assigned to it. It sends a failed response when they are
+// invalid.
+func bindTaxClassAssignment(c *gin.Context) (int, domain.SetTaxClassInput, bool) {
+	var input domain.SetTaxClassInput
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return id, input, false
+	}
+
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return id, input, false
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return id, input, false
+	}
+	return id, input, true
+}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Set Product Options
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Set the option types (size, colour...) the variants of a product are described by.
+// @ID set-product-options
+// @Accept json
+// @Produce json
+// @Param id path int true "Product id"
+// @Param input body domain.SetProductOptionsInput true "Option types"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/options [put]
+func (h *Handler) adminSetProductOptions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.SetProductOptionsInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Variant.SetOptions(id, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Create Product Variant
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Create a new variant of a product.
+// @ID create-product-variant
+// @Accept  multipart/form-data
+// @Produce json
+// @Param id path int true "Product id"
+// @Param sku formData string true "Variant SKU"
+// @Param options formData string true "Variant options as a JSON object, e.g. {\"size\":\"M\"}"
+// @Param price formData number true "Variant actual price"
+// @Param undiscounted_price formData number true "Variant price without any discount"
+// @Param stock formData int true "Variant stock"
+// @Param available formData boolean true "Variant is available"
+// @Param image_file formData file false "Variant image"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/variants [post]
+func (h *Handler) adminCreateVariant(c *gin.Context) {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	r := c.Request
+	var input domain.CreateVariantInput
+
+	input.Sku = r.FormValue("sku")
+
+	if err := json.Unmarshal([]byte(r.FormValue("options")), &input.Options); err != nil {
+		Fail(c, "invalid options value", http.StatusBadRequest)
+		return
+	}
+
+	available := r.FormValue("available")
+	input.Available = available == "true"
+
+	stock, err := strconv.Atoi(r.FormValue("stock"))
+	if err != nil {
+		Fail(c, "invalid stock value", http.StatusBadRequest)
+		return
+	}
+	input.Stock = stock
+
+	input.Price, err = domain.ParseMoney(r.FormValue("price"))
+	if err != nil {
+		Fail(c, "invalid price value", http.StatusBadRequest)
+		return
+	}
+
+	input.UndiscountedPrice, err = domain.ParseMoney(r.FormValue("undiscounted_price"))
+	if err != nil {
+		Fail(c, "invalid undiscounted_price value", http.StatusBadRequest)
+		return
+	}
+
+	file, handler, err := r.FormFile("image_file")
+	if err != nil {
+		if err != http.ErrMissingFile {
+			Fail(c, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		defer file.Close()
+	}
+	input.ImgFile = handler
+
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.services.Variant.CreateVariant(productId, input, file)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OKId(c, id)
+}
+
+// @Summary Update Product Variant
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Update a variant of a product.
+// @ID update-product-variant
+// @Accept  multipart/form-data
+// @Produce json
+// @Param id path int true "Product id"
+// @Param variantId path int true "Variant id"
+// @Param sku formData string false "Variant SKU"
+// @Param options formData string false "Variant options as a JSON object, e.g. {\"size\":\"M\"}"
+// @Param price formData number false "Variant actual price"
+// @Param undiscounted_price formData number false "Variant price without any discount"
+// @Param stock formData int false "Variant stock"
+// @Param available formData boolean false "Variant is available"
+// @Param image_file formData file false "Variant image"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/variants/{variantId} [put]
+func (h *Handler) adminUpdateVariant(c *gin.Context) {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+	variantId, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	r := c.Request
+	var input domain.UpdateVariantInput
+
+	sku := r.FormValue("sku")
+	if sku != "" {
+		input.Sku = &sku
+	}
+
+	options := r.FormValue("options")
+	if options != "" {
+		if err := json.Unmarshal([]byte(options), &input.Options); err != nil {
+			Fail(c, "invalid options value", http.StatusBadRequest)
+			return
+		}
+	}
+
+	availableField := r.FormValue("available")
+	if availableField != "" {
+		available := availableField == "true"
+		input.Available = &available
+	}
+
+	stockField := r.FormValue("stock")
+	if stockField != "" {
+		stock, err := strconv.Atoi(stockField)
+		if err != nil {
+			Fail(c, "invalid stock value", http.StatusBadRequest)
+			return
+		}
+		input.Stock = &stock
+	}
+
+	price := r.FormValue("price")
+	if price != "" {
+		price, err := domain.ParseMoney(price)
+		if err != nil {
+			Fail(c, "invalid price value", http.StatusBadRequest)
+			return
+		}
+		input.Price = &price
+	}
+
+	undiscountedPrice := r.FormValue("undiscounted_price")
+	if undiscountedPrice != "" {
+		price, err := domain.ParseMoney(undiscountedPrice)
+		if err != nil {
+			Fail(c, "invalid undiscounted_price value", http.StatusBadRequest)
+			return
+		}
+		input.UndiscountedPrice = &price
+	}
+
+	file, handler, err := r.FormFile("image_file")
+	if err != nil {
+		if err != http.ErrMissingFile {
+			Fail(c, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		defer file.Close()
+	}
+	input.ImgFile = handler
+
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = h.services.Variant.UpdateVariant(productId, variantId, input, file)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Delete Product Variant
+// @Security ApiKeyAuth
+// @Tags Admin
+// @Description Delete a variant of a product. Orders keep the variant data they were placed with.
+// @ID delete-product-variant
+// @Produce json
+// @Param id path int true "Product id"
+// @Param variantId path int true "Variant id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admin/products/{id}/variants/{variantId} [delete]
+func (h *Handler) adminDeleteVariant(c *gin.Context) {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+	variantId, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Variant.DeleteVariant(productId, variantId)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+func (h *Handler) mediaGetVariantImage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+	fileName := c.Param("file-name")
+
+	filePath := h.services.Variant.GetFilePath(id, fileName)
+
+	c.File(filePath)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// @Summary Get Wishlist
+// @Security ApiKeyAuth
+// @Tags User Profile
+// @Description Get the products of the user's wishlist with their current price and stock, last added first.
+// @ID get-wishlist
+// @Accept json
+// @Produce json
+// @Param page query string false "Pagination: page number"
+// @Param pageSize query string false "Pagination: amount of items per page"
+// @Param cursor query string false "Pagination: next_cursor or prev_cursor of a previous page, instead of a page number"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /profile/wishlist [get]
+func (h *Handler) userGetWishlist(c *gin.Context) {
+	var params domain.PaginationParams
+	if err := c.BindQuery(&params); err != nil {
+		Fail(c, bindPaginationParamsErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := params.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	page, err := computePageRequest(params)
+	if err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, pagination, err := h.services.Wishlist.GetItems(userId, page)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	ResponsePage(c, items, pagination, nil)
+}
+
+// @Summary Add To Wishlist
+// @Security ApiKeyAuth
+// @Tags User Profile
+// @Description Add a product to the user's wishlist. With notify set the user is emailed when its price drops or it comes back in stock. Adding a product already in the wishlist updates notify.
+// @ID add-to-wishlist
+// @Accept json
+// @Produce json
+// @Param input body domain.AddWishlistItemInput true "Wishlist item"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /profile/wishlist [post]
+func (h *Handler) userAddToWishlist(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input domain.AddWishlistItemInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Wishlist.AddItem(userId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Remove From Wishlist
+// @Security ApiKeyAuth
+// @Tags User Profile
+// @Description Remove a product from the user's wishlist.
+// @ID remove-from-wishlist
+// @Accept json
+// @Produce json
+// @Param productId path int true "Product id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /profile/wishlist/{productId} [delete]
+func (h *Handler) userRemoveFromWishlist(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+	productId, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		Fail(c, invalidIdErrorText, http.StatusBadRequest)
+		return
+	}
+
+	err = h.services.Wishlist.RemoveItem(userId, productId)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+
+	OK(c)
+}
+
+// @Summary Order From Wishlist
+// @Security ApiKeyAuth
+// @Tags User Profile
+// @Description Create an order of products of the user's wishlist, they are removed from the wishlist once ordered. With a currency, the prices are converted with its current exchange rate. A promo_code discounts the eligible lines before tax.
+// @ID order-from-wishlist
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateOrderInput true "Order info"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /profile/wishlist/order [post]
+func (h *Handler) userOrderFromWishlist(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+	var input domain.CreateOrderInput
+	if err := c.BindJSON(&input); err != nil {
+		Fail(c, bindJSONErrorText, http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		Fail(c, err.Error(), http.StatusBadRequest)
+		return
+	}
+	input.Sort()
+
+	id, err := h.services.Wishlist.CreateOrder(userId, input)
+	if err != nil {
+		FailAndHandleErr(c, err)
+		return
+	}
+	OKId(c, id)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/storage"
+)
+
+// setArchived archives or restores a row of a table with an archived_at
+// column. Archiving an archived row keeps its archive date.
+func setArchived(db *sqlx.DB, table string, id int, archived bool) error {
+	value := "NULL"
+	if archived {
+		value = "COALESCE(archived_at, now())"
+	}
+	query := fmt.Sprintf("UPDATE %s SET archived_at=%s WHERE id=$1 RETURNING id", table, value)
+
+	err := db.QueryRow(query, id).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors_handler.NoRows()
+	}
+	return err
+}
+
+// selectIds returns the ids selected by a query in the transaction.
+func selectIds(tx *sql.Tx, query string, args ...interface{}) ([]int, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// prepareProductsDelete checks that none of the products, locked by the
+// caller, appears in an order and returns the ids of their variants, whose
+// media is removed with the products. Ordered products can only be archived,
+// the orders keep referencing them.
+func prepareProductsDelete(tx *sql.Tx, productIds []int) ([]int, error) {
+	var ordered bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE product_id = ANY($1))", orderedProductsTable)
+	if err := tx.QueryRow(query, pq.Array(productIds)).Scan(&ordered); err != nil {
+		return nil, err
+	}
+	if ordered {
+		return nil, errors_handler.ForeignKeyViolation()
+	}
+
+	variantsQuery := fmt.Sprintf("SELECT id FROM %s WHERE product_id = ANY($1)", productVariantsTable)
+	return selectIds(tx, variantsQuery, pq.Array(productIds))
+}
+
+// removeProductsMedia removes the media files of products, and of their
+// variants, whose deletion is committed. See removeMedia.
+func removeProductsMedia(s *storage.Storage, productIds, variantIds []int) {
+	for _, id := range productIds {
+		removeMedia(s, storage.ProductsMedia, id)
+	}
+	for _, id := range variantIds {
+		removeMedia(s, storage.VariantsMedia, id)
+	}
+}
+
+// deleteError translates the violation of the foreign key of the order lines,
+// which refuses to delete an ordered product.
+func deleteError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+		return errors_handler.ForeignKeyViolation()
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+type AttributePostgres struct {
+	db *sqlx.DB
+}
+
+func newAttributePostgres(db *sqlx.DB) *AttributePostgres {
+	return &AttributePostgres{db}
+}
+
+func (r *AttributePostgres) GetAttributes(categoryId int) ([]domain.Attribute, error) {
+	attributes := make([]domain.Attribute, 0)
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE category_id=$1 ORDER BY name", attributesTable)
+
+	err := r.db.Select(&attributes, query, categoryId)
+	return attributes, err
+}
+
+func (r *AttributePostgres) CreateAttribute(categoryId int, input domain.CreateAttributeInput) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (
+		category_id,
+		name,
+		type,
+		unit
+	) VALUES ($1, $2, $3, $4) RETURNING id`, attributesTable)
+
+	err := r.db.QueryRow(query, categoryId, input.Name, input.Type, input.Unit).Scan(&id)
+	return id, attributeError(err)
+}
+
+func (r *AttributePostgres) UpdateAttribute(categoryId, attributeId int, input domain.UpdateAttributeInput) error {
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.Name != nil {
+		setValues = append(setValues, fmt.Sprintf("name=$%d", argId))
+		args = append(args, *input.Name)
+		argId++
+	}
+
+	if input.Unit != nil {
+		setValues = append(setValues, fmt.Sprintf("unit=$%d", argId))
+		args = append(args, *input.Unit)
+		argId++
+	}
+
+	setQuery := strings.Join(setValues, ", ")
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id=$%d AND category_id=$%d RETURNING id", attributesTable, setQuery, argId, argId+1)
+	args = append(args, attributeId, categoryId)
+
+	var id int
+	err := r.db.QueryRow(query, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors_handler.NoRows()
+	}
+	return attributeError(err)
+}
+
+func (r *AttributePostgres) DeleteAttribute(categoryId, attributeId int) error {
+	var id int
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1 AND category_id=$2 RETURNING id", attributesTable)
+
+	err := r.db.QueryRow(query, attributeId, categoryId).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors_handler.NoRows()
+	}
+	return err
+}
+
+func (r *AttributePostgres) GetProductCategoryId(productId int) (int, error) {
+	var categoryId int
+	query := fmt.Sprintf("SELECT category_id FROM %s WHERE id=$1", productsTable)
+
+	err := r.db.Get(&categoryId, query, productId)
+	if err == sql.ErrNoRows {
+		return categoryId, errors_handler.NoRows()
+	}
+	return categoryId, err
+}
+
+// GetProductAttributes returns the values of a product for the attributes of
+// its current category.
+func (r *AttributePostgres) GetProductAttributes(productId int) ([]domain.ProductAttribute, error) {
+	attributes := make([]domain.ProductAttribute, 0)
+
+	query := fmt.Sprintf(`SELECT 
+		a.id AS attribute_id, 
+		a.name, 
+		a.type, 
+		a.unit, 
+		pa.value
+	FROM %s pa
+	INNER JOIN %s a ON a.id = pa.attribute_id
+	INNER JOIN %s p ON p.id = pa.product_id AND p.category_id = a.category_id
+	WHERE pa.product_id=$1
+	ORDER BY a.name`, productAttributesTable, attributesTable, productsTable)
+
+	err := r.db.Select(&attributes, query, productId)
+	return attributes, err
+}
+
+// SetProductAttributes replaces the attribute values of a product.
+func (r *AttributePostgres) SetProductAttributes(productId int, values []domain.ProductAttributeValue) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE product_id=$1", productAttributesTable)
+	if _, err := tx.Exec(deleteQuery, productId); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (product_id, attribute_id, value) VALUES ($1, $2, $3)", productAttributesTable)
+	for _, value := range values {
+		if _, err := tx.Exec(insertQuery, productId, value.AttributeId, value.Value); err != nil {
+			return attributeError(err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func attributeError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		return errors_handler.AlreadyExists("attribute")
+	case "foreign_key_violation":
+		return errors_handler.ForeignKeyViolation()
+	}
+	return err
+}
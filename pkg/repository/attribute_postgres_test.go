@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAttribute(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newAttributePostgres(sqlx.NewDb(db, "sqlmock"))
+
+	type args struct {
+		categoryId int
+		input      domain.CreateAttributeInput
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("INSERT INTO attributes").
+					WithArgs(1, "weight", "number", "kg").WillReturnRows(rows)
+			},
+			input: args{1, domain.CreateAttributeInput{Name: "weight", Type: "number", Unit: "kg"}},
+			want:  1,
+		},
+		{
+			name: "Already exists",
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO attributes").
+					WithArgs(1, "weight", "number", "kg").WillReturnError(&pq.Error{Code: "23505"})
+			},
+			input:   args{1, domain.CreateAttributeInput{Name: "weight", Type: "number", Unit: "kg"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.CreateAttribute(tt.input.categoryId, tt.input.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetProductAttributes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newAttributePostgres(sqlx.NewDb(db, "sqlmock"))
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   int
+		want    []domain.ProductAttribute
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"attribute_id", "name", "type", "unit", "value"}).
+					AddRow(1, "material", "text", "", "cotton").
+					AddRow(2, "weight", "number", "kg", "1.5")
+				mock.ExpectQuery("SELECT (.+) FROM product_attributes pa").
+					WithArgs(1).WillReturnRows(rows)
+			},
+			input: 1,
+			want: []domain.ProductAttribute{
+				{AttributeId: 1, Name: "material", Type: "text", Value: "cotton"},
+				{AttributeId: 2, Name: "weight", Type: "number", Unit: "kg", Value: "1.5"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetProductAttributes(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetProductAttributes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newAttributePostgres(sqlx.NewDb(db, "sqlmock"))
+
+	type args struct {
+		productId int
+		values    []domain.ProductAttributeValue
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM product_attributes").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("INSERT INTO product_attributes").
+					WithArgs(1, 1, "cotton").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO product_attributes").
+					WithArgs(1, 2, "1.5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			input: args{1, []domain.ProductAttributeValue{
+				{AttributeId: 1, Value: "cotton"},
+				{AttributeId: 2, Value: "1.5"},
+			}},
+		},
+		{
+			name: "Insert error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM product_attributes").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO product_attributes").
+					WithArgs(1, 1, "cotton").WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
+			},
+			input: args{1, []domain.ProductAttributeValue{
+				{AttributeId: 1, Value: "cotton"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.SetProductAttributes(tt.input.productId, tt.input.values)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -35,7 +35,7 @@ func (r *AuthPostgres) CreateUser(user domain.User) (int, error) {
 
 func (r *AuthPostgres) GetUser(email, password string) (domain.User, error) {
 	var user domain.User
-	query := fmt.Sprintf("SELECT id FROM %s WHERE email=$1 AND password_hash=$2", usersTable)
+	query := fmt.Sprintf("SELECT id FROM %s WHERE email=$1 AND password_hash=$2 AND disabled=false", usersTable)
 	err := r.db.Get(&user, query, email, password)
 	if err == sql.ErrNoRows {
 		return user, errors_handler.NoRows()
@@ -66,3 +66,41 @@ func (r *AuthPostgres) UpdatePassword(userId int, password string) error {
 	}
 	return nil
 }
+
+func (r *AuthPostgres) GetUserById(id int) (domain.User, error) {
+	var user domain.User
+	query := fmt.Sprintf("SELECT id, name, email, disabled, is_admin FROM %s WHERE id=$1", usersTable)
+	err := r.db.Get(&user, query, id)
+	if err == sql.ErrNoRows {
+		return user, errors_handler.NoRows()
+	}
+	return user, err
+}
+
+func (r *AuthPostgres) SetDisabled(userId int, disabled bool) error {
+	query := fmt.Sprintf("UPDATE %s SET disabled=$1 WHERE id=$2 RETURNING id", usersTable)
+
+	var id int
+	row := r.db.QueryRow(query, disabled, userId)
+	if err := row.Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *AuthPostgres) SetAdmin(userId int, isAdmin bool) error {
+	query := fmt.Sprintf("UPDATE %s SET is_admin=$1 WHERE id=$2 RETURNING id", usersTable)
+
+	var id int
+	row := r.db.QueryRow(query, isAdmin, userId)
+	if err := row.Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+	return nil
+}
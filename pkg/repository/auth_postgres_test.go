@@ -263,3 +263,120 @@ func TestUpdatePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUserById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newAuthPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   int
+		want    domain.User
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "name", "email", "disabled", "is_admin"}).
+					AddRow(1, "Alice", "alice@example.com", true, true)
+				mock.ExpectQuery("SELECT id, name, email, disabled, is_admin FROM users").
+					WithArgs(1).WillReturnRows(rows)
+			},
+			input: 1,
+			want: domain.User{
+				Id:       1,
+				Name:     "Alice",
+				Email:    "alice@example.com",
+				Disabled: true,
+				IsAdmin:  true,
+			},
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "name", "email", "disabled", "is_admin"})
+				mock.ExpectQuery("SELECT id, name, email, disabled, is_admin FROM users").
+					WithArgs(2).WillReturnRows(rows)
+			},
+			input:   2,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetUserById(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newAuthPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	type args struct {
+		id       int
+		disabled bool
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("UPDATE users SET disabled").
+					WithArgs(true, 1).WillReturnRows(rows)
+			},
+			input: args{1, true},
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"})
+				mock.ExpectQuery("UPDATE users SET disabled").
+					WithArgs(false, 2).WillReturnRows(rows)
+			},
+			input:   args{2, false},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.SetDisabled(tt.input.id, tt.input.disabled)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -22,27 +22,102 @@ func newCategoryPostgres(db *sqlx.DB, s *storage.Storage) *CategoryPostgres {
 	return &CategoryPostgres{db, s}
 }
 
-func (r *CategoryPostgres) GetAll(limit, offset int, search string) ([]domain.Category, error) {
-	var categories []domain.Category
-
-	query := fmt.Sprintf("SELECT * FROM %s WHERE available=true", categoriesTables)
+func (r *CategoryPostgres) GetAll(page domain.PageRequest, search string) ([]domain.Category, domain.Pagination, error) {
+	c := &queryConditions{}
+	c.add("c.available=true AND c.archived_at IS NULL")
 	if search != "" {
-		query += fmt.Sprintf(" AND name ILIKE '%%%s%%'", search)
+		c.add(fmt.Sprintf("(c.name ILIKE %s OR %s <%% c.name)", c.arg("%"+escapeLike(search)+"%"), c.arg(search)))
 	}
-	query += " ORDER BY id LIMIT $1 OFFSET $2"
 
-	err := r.db.Select(&categories, query, limit, offset)
-	if err == sql.ErrNoRows {
-		return categories, errors_handler.NoRows()
+	rows, pagination, err := selectPage[categoryRow](r.db, pageQuery{
+		columns: "c.*",
+		from:    categoriesTables + " c",
+		where:   c,
+		id:      "c.id",
+	}, page)
+
+	categories := make([]domain.Category, len(rows))
+	for i, row := range rows {
+		categories[i] = row.Category
+	}
+	return categories, pagination, err
+}
+
+// AdminGetAll returns the categories of the admin listing, whether available
+// or archived.
+func (r *CategoryPostgres) AdminGetAll(page domain.PageRequest, filter domain.AdminCategoryFilterParams) ([]domain.Category, domain.Pagination, error) {
+	c := &queryConditions{}
+	if filter.Search != "" {
+		c.add("c.name ILIKE " + c.arg("%"+escapeLike(filter.Search)+"%"))
+	}
+	if filter.Available != nil {
+		c.add("c.available=" + c.arg(*filter.Available))
+	}
+	if filter.Archived != nil {
+		if *filter.Archived {
+			c.add("c.archived_at IS NOT NULL")
+		} else {
+			c.add("c.archived_at IS NULL")
+		}
+	}
+
+	rows, pagination, err := selectPage[categoryRow](r.db, pageQuery{
+		columns: "c.*",
+		from:    categoriesTables + " c",
+		where:   c,
+		id:      "c.id",
+	}, page)
+
+	categories := make([]domain.Category, len(rows))
+	for i, row := range rows {
+		categories[i] = row.Category
 	}
+	return categories, pagination, err
+}
+
+// categoryRow is a category listing row.
+type categoryRow struct {
+	domain.Category
+	SortKey string `db:"sort_key"`
+}
+
+func (r categoryRow) position() (string, int) {
+	return r.SortKey, r.Id
+}
 
+// GetTree returns every available and not archived category, the tree is built from their
+// parent ids.
+func (r *CategoryPostgres) GetTree() ([]domain.Category, error) {
+	categories := make([]domain.Category, 0)
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE available=true AND archived_at IS NULL ORDER BY name, id", categoriesTables)
+
+	err := r.db.Select(&categories, query)
 	return categories, err
 }
 
+// GetBreadcrumbs returns the path from a top level category to the category,
+// which comes last.
+func (r *CategoryPostgres) GetBreadcrumbs(categoryId int) ([]domain.Breadcrumb, error) {
+	breadcrumbs := make([]domain.Breadcrumb, 0)
+
+	query := fmt.Sprintf(`WITH RECURSIVE path AS (
+			SELECT id, name, parent_id, 0 AS depth FROM %[1]s WHERE id=$1
+			UNION ALL
+			SELECT c.id, c.name, c.parent_id, path.depth + 1 FROM %[1]s c
+			INNER JOIN path ON c.id = path.parent_id
+			WHERE path.depth < $2
+		)
+		SELECT id, name FROM path ORDER BY depth DESC`, categoriesTables)
+
+	err := r.db.Select(&breadcrumbs, query, categoryId, maxCategoryDepth)
+	return breadcrumbs, err
+}
+
 func (r *CategoryPostgres) GetById(id int) (domain.Category, error) {
 	var category domain.Category
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1 AND available=true", categoriesTables)
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1 AND available=true AND archived_at IS NULL", categoriesTables)
 
 	err := r.db.Get(&category, query, id)
 	if err == sql.ErrNoRows {
@@ -52,25 +127,40 @@ func (r *CategoryPostgres) GetById(id int) (domain.Category, error) {
 	return category, err
 }
 
-func (r *CategoryPostgres) GetFilePath(categoryId int, fileName string) string {
-	return r.s.Category.GetFilePath(categoryId, fileName)
-}
+// AdminGetById returns a category whether available or archived.
+func (r *CategoryPostgres) AdminGetById(id int) (domain.Category, error) {
+	var category domain.Category
 
-func (r *CategoryPostgres) GetProducts(categoryId, limit, offset int, search string) ([]domain.Product, error) {
-	var products []domain.Product
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1", categoriesTables)
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE category_id=$1 AND available=true", productsTable)
-	if search != "" {
-		query += fmt.Sprintf(" AND name ILIKE '%%%s%%'", search)
+	err := r.db.Get(&category, query, id)
+	if err == sql.ErrNoRows {
+		return category, errors_handler.NoRows()
 	}
-	query += " ORDER BY id LIMIT $2 OFFSET $3"
 
-	err := r.db.Select(&products, query, categoryId, limit, offset)
+	return category, err
+}
+
+func (r *CategoryPostgres) GetIdByName(name string) (int, error) {
+	var id int
+
+	query := fmt.Sprintf("SELECT id FROM %s WHERE name=$1", categoriesTables)
+
+	err := r.db.Get(&id, query, name)
 	if err == sql.ErrNoRows {
-		return products, errors_handler.NoRows()
+		return id, errors_handler.NoRows()
 	}
 
-	return products, err
+	return id, err
+}
+
+func (r *CategoryPostgres) GetFilePath(categoryId int, fileName string) string {
+	return r.s.Category.GetFilePath(categoryId, fileName)
+}
+
+func (r *CategoryPostgres) GetProducts(categoryId int, page domain.PageRequest, filter domain.ProductFilter) ([]domain.Product, domain.Pagination, error) {
+	rows, pagination, err := selectPage[productRow](r.db, productListing(categoryId, filter), page)
+	return productsOf(rows), pagination, err
 }
 
 func (r *CategoryPostgres) CreateCategory(input domain.CreateCategoryInput, file multipart.File) (int, error) {
@@ -85,15 +175,19 @@ func (r *CategoryPostgres) CreateCategory(input domain.CreateCategoryInput, file
 		name, 
 		description, 
 		image_url, 
-		available
-	) VALUES ($1, $2, $3, $4) RETURNING id`, categoriesTables)
+		available,
+		parent_id
+	) VALUES ($1, $2, $3, $4, $5) RETURNING id`, categoriesTables)
 
-	row := tx.QueryRow(query, input.Name, input.Description, "", input.Available)
+	row := tx.QueryRow(query, input.Name, input.Description, "", input.Available, input.ParentId)
 	if err := row.Scan(&id); err != nil {
 		pqErr, ok := err.(*pq.Error)
 		if ok && pqErr.Code.Name() == "unique_violation" {
 			return 0, errors_handler.AlreadyExists("category")
 		}
+		if ok && pqErr.Code.Name() == "foreign_key_violation" {
+			return 0, errors_handler.ForeignKeyViolation()
+		}
 		return 0, err
 	}
 
@@ -142,6 +236,20 @@ func (r *CategoryPostgres) UpdateCategory(categoryId int, input domain.UpdateCat
 		argId++
 	}
 
+	if input.ParentId != nil {
+		var parentId *int
+		if *input.ParentId != 0 {
+			if err := checkCategoryParent(tx, categoryId, *input.ParentId); err != nil {
+				return err
+			}
+			parentId = input.ParentId
+		}
+
+		setValues = append(setValues, fmt.Sprintf("parent_id=$%d", argId))
+		args = append(args, parentId)
+		argId++
+	}
+
 	if input.ImgFile != nil && file != nil {
 		url, err := r.s.UploadCategoryImage(categoryId, input.ImgFile, file)
 
@@ -165,6 +273,9 @@ func (r *CategoryPostgres) UpdateCategory(categoryId int, input domain.UpdateCat
 	if ok && pqErr.Code.Name() == "unique_violation" {
 		return errors_handler.AlreadyExists("category")
 	}
+	if ok && pqErr.Code.Name() == "check_violation" {
+		return errors_handler.BadRequest(categoryCycleErrorText)
+	}
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -176,3 +287,90 @@ func (r *CategoryPostgres) UpdateCategory(categoryId int, input domain.UpdateCat
 
 	return tx.Commit()
 }
+
+// maxCategoryDepth bounds the walks up the category tree. Cycles are
+// prevented when categories are moved, the bound only guards against
+// corrupted data.
+const maxCategoryDepth = 100
+
+const categoryCycleErrorText = "parent_id: a category can not be moved under itself or its subcategories"
+
+// checkCategoryParent makes sure the parent exists and is not the category
+// or one of its subcategories, which would make a cycle. The categories
+// table is locked against concurrent writes, so two moves can not make a
+// cycle together.
+func checkCategoryParent(tx *sql.Tx, categoryId, parentId int) error {
+	lockQuery := fmt.Sprintf("LOCK TABLE %s IN SHARE ROW EXCLUSIVE MODE", categoriesTables)
+	if _, err := tx.Exec(lockQuery); err != nil {
+		return err
+	}
+
+	var exists, cycle bool
+	query := fmt.Sprintf(`WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM %[1]s WHERE id=$1
+			UNION
+			SELECT c.id, c.parent_id FROM %[1]s c
+			INNER JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors), EXISTS (SELECT 1 FROM ancestors WHERE id=$2)`, categoriesTables)
+	if err := tx.QueryRow(query, parentId, categoryId).Scan(&exists, &cycle); err != nil {
+		return err
+	}
+	if !exists {
+		return errors_handler.ForeignKeyViolation()
+	}
+	if cycle {
+		return errors_handler.BadRequest(categoryCycleErrorText)
+	}
+	return nil
+}
+
+func (r *CategoryPostgres) ArchiveCategory(id int) error {
+	return setArchived(r.db, categoriesTables, id, true)
+}
+
+func (r *CategoryPostgres) RestoreCategory(id int) error {
+	return setArchived(r.db, categoriesTables, id, false)
+}
+
+// DeleteCategory deletes a category with the products it is the primary
+// category of, together with their media. It is refused when any of these
+// products has been ordered. Its subcategories become top level categories.
+func (r *CategoryPostgres) DeleteCategory(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 FOR UPDATE", categoriesTables)
+	if err := tx.QueryRow(query, id).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	productsQuery := fmt.Sprintf("SELECT id FROM %s WHERE category_id=$1 FOR UPDATE", productsTable)
+	productIds, err := selectIds(tx, productsQuery, id)
+	if err != nil {
+		return err
+	}
+
+	variantIds, err := prepareProductsDelete(tx, productIds)
+	if err != nil {
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE id=$1", categoriesTables)
+	if _, err := tx.Exec(deleteQuery, id); err != nil {
+		return deleteError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return deleteError(err)
+	}
+	removeMedia(r.s, storage.CategoriesMedia, id)
+	removeProductsMedia(r.s, productIds, variantIds)
+	return nil
+}
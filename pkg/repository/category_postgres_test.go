@@ -9,7 +9,9 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
 	"github.com/renlin-code/mock-shop-api/pkg/storage"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,49 +31,61 @@ func TestGetAllCategories(t *testing.T) {
 	r := newCategoryPostgres(sqlx.NewDb(db, "sqlmock"), s)
 
 	type args struct {
-		limit  int
-		offset int
+		page   domain.PageRequest
+		search string
 	}
 
+	columns := []string{"id", "name", "description", "available", "image_url", "sort_key"}
+
 	tests := []struct {
-		name    string
-		mock    func()
-		input   args
-		want    []domain.Category
-		wantErr bool
+		name           string
+		mock           func()
+		input          args
+		want           []domain.Category
+		wantPagination domain.Pagination
+		wantErr        bool
 	}{
 		{
 			name: "Ok",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "available", "image_url"}).
-					AddRow(1, "category name 1", "category description 1", true, "https://test.back.com/data/categories/1/img1.png").
-					AddRow(2, "category name 2", "category description 2", true, "https://test.back.com/data/categories/2/img2.png").
-					AddRow(3, "category name 3", "category description 3", true, "https://test.back.com/data/categories/3/img3.png")
-				mock.ExpectQuery("SELECT (.+) FROM categories").
-					WithArgs(3, 0).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT COUNT(.+) FROM categories c WHERE c.available=true AND c.archived_at IS NULL").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "category name 1", "category description 1", true, "https://test.back.com/data/categories/1/img1.png", "").
+					AddRow(2, "category name 2", "category description 2", true, "https://test.back.com/data/categories/2/img2.png", "").
+					AddRow(3, "category name 3", "category description 3", true, "https://test.back.com/data/categories/3/img3.png", "")
+				mock.ExpectQuery("SELECT (.+) FROM categories c WHERE c.available=true AND c.archived_at IS NULL ORDER BY c.id LIMIT (.+) OFFSET").
+					WithArgs(4, 0).WillReturnRows(rows)
 			},
-			input: args{3, 0},
+			input: args{domain.PageRequest{Limit: 3}, ""},
 			want: []domain.Category{
 				{Id: 1, Name: "category name 1", Description: "category description 1", Available: true, ImageUrl: "https://test.back.com/data/categories/1/img1.png"},
 				{Id: 2, Name: "category name 2", Description: "category description 2", Available: true, ImageUrl: "https://test.back.com/data/categories/2/img2.png"},
 				{Id: 3, Name: "category name 3", Description: "category description 3", Available: true, ImageUrl: "https://test.back.com/data/categories/3/img3.png"},
 			},
+			wantPagination: domain.Pagination{Total: 3, Page: 1, PageSize: 3},
 		},
 		{
-			name: "Ok without pagination",
+			name: "Ok with search and cursor",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "available", "image_url"}).
-					AddRow(1, "category name 1", "category description 1", true, "https://test.back.com/data/categories/1/img1.png").
-					AddRow(2, "category name 2", "category description 2", true, "https://test.back.com/data/categories/2/img2.png").
-					AddRow(3, "category name 3", "category description 3", true, "https://test.back.com/data/categories/3/img3.png")
-				mock.ExpectQuery("SELECT (.+) FROM categories").
-					WithArgs(0, 0).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT COUNT(.+) FROM categories c WHERE c.available=true AND c.archived_at IS NULL AND \\(c.name ILIKE \\$1 OR \\$2 <% c.name\\)").
+					WithArgs("%name%", "name").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, "category name 2", "category description 2", true, "https://test.back.com/data/categories/2/img2.png", "").
+					AddRow(1, "category name 1", "category description 1", true, "https://test.back.com/data/categories/1/img1.png", "")
+				mock.ExpectQuery("SELECT (.+) FROM categories c WHERE c.available=true AND c.archived_at IS NULL AND (.+) AND c.id < \\$3 ORDER BY c.id DESC LIMIT \\$4$").
+					WithArgs("%name%", "name", 3, 2).WillReturnRows(rows)
 			},
-			input: args{0, 0},
+			input: args{domain.PageRequest{Limit: 1, Cursor: &domain.Cursor{Id: 3, Before: true}}, "name"},
 			want: []domain.Category{
-				{Id: 1, Name: "category name 1", Description: "category description 1", Available: true, ImageUrl: "https://test.back.com/data/categories/1/img1.png"},
 				{Id: 2, Name: "category name 2", Description: "category description 2", Available: true, ImageUrl: "https://test.back.com/data/categories/2/img2.png"},
-				{Id: 3, Name: "category name 3", Description: "category description 3", Available: true, ImageUrl: "https://test.back.com/data/categories/3/img3.png"},
+			},
+			wantPagination: domain.Pagination{
+				Total:      3,
+				PageSize:   1,
+				NextCursor: domain.Cursor{Id: 2}.Encode(),
+				PrevCursor: domain.Cursor{Id: 2, Before: true}.Encode(),
 			},
 		},
 	}
@@ -80,12 +94,13 @@ func TestGetAllCategories(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := r.GetAll(tt.input.limit, tt.input.offset, "")
+			got, pagination, err := r.GetAll(tt.input.page, tt.input.search)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantPagination, pagination)
 			}
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
@@ -161,6 +176,64 @@ func TestGetCategoryById(t *testing.T) {
 	}
 }
 
+func TestGetCategoryIdByName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newCategoryPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(3)
+				mock.ExpectQuery("SELECT id FROM categories WHERE name").
+					WithArgs("Mugs").WillReturnRows(rows)
+			},
+			input: "Mugs",
+			want:  3,
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"})
+				mock.ExpectQuery("SELECT id FROM categories WHERE name").
+					WithArgs("Cups").WillReturnRows(rows)
+			},
+			input:   "Cups",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetIdByName(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestGetCategoryProducts(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -177,50 +250,75 @@ func TestGetCategoryProducts(t *testing.T) {
 
 	type args struct {
 		categoryId int
-		limit      int
-		offset     int
+		page       domain.PageRequest
 	}
 
+	columns := []string{"id", "category_id", "name", "description", "price", "undiscounted_price", "stock", "available", "image_url", "sort_key"}
+
 	tests := []struct {
-		name    string
-		mock    func()
-		input   args
-		want    []domain.Product
-		wantErr bool
+		name           string
+		mock           func()
+		input          args
+		filter         domain.ProductFilter
+		want           []domain.Product
+		wantPagination domain.Pagination
+		wantErr        bool
 	}{
 		{
 			name: "Ok",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "category_id", "name", "description", "price", "undiscounted_price", "stock", "available", "image_url"}).
-					AddRow(1, 1, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/data/products/1/img1.png").
-					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "https://test.back.com/data/products/2/img1.png").
-					AddRow(3, 1, "product name 3", "product description 3", 108.49, 126.99, 27, true, "https://test.back.com/data/products/3/img1.png")
-				mock.ExpectQuery("SELECT (.+) FROM products").
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 1, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/data/products/1/img1.png", "").
+					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "https://test.back.com/data/products/2/img1.png", "").
+					AddRow(3, 1, "product name 3", "product description 3", 108.49, 126.99, 27, true, "https://test.back.com/data/products/3/img1.png", "")
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p WHERE p.available=true AND p.archived_at IS NULL AND \\(p.category_id = \\$1 OR EXISTS \\(SELECT 1 FROM product_categories pc WHERE pc.product_id = p.id AND pc.category_id = \\$1\\)\\)").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery("SELECT (.+) FROM products p WHERE p.available=true AND p.archived_at IS NULL AND \\(p.category_id = \\$1 OR EXISTS \\(SELECT 1 FROM product_categories pc WHERE pc.product_id = p.id AND pc.category_id = \\$1\\)\\) ORDER BY p.id LIMIT (.+) OFFSET").
+					WithArgs(1, 4, 0).WillReturnRows(rows)
+			},
+			input: args{1, domain.PageRequest{Limit: 3}},
+			want: []domain.Product{
+				{Id: 1, CategoryId: 1, Name: "product name 1", Description: "product description 1", Price: 99, UndiscountedPrice: 129, Stock: 12, Available: true, ImageUrl: "https://test.back.com/data/products/1/img1.png"},
+				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 199, UndiscountedPrice: 199, Stock: 2, Available: true, ImageUrl: "https://test.back.com/data/products/2/img1.png"},
+				{Id: 3, CategoryId: 1, Name: "product name 3", Description: "product description 3", Price: 10849, UndiscountedPrice: 12699, Stock: 27, Available: true, ImageUrl: "https://test.back.com/data/products/3/img1.png"},
+			},
+			wantPagination: domain.Pagination{Total: 3, Page: 1, PageSize: 3},
+		},
+		{
+			name: "Ok with more pages",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 1, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/data/products/1/img1.png", "").
+					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "https://test.back.com/data/products/2/img1.png", "").
+					AddRow(3, 1, "product name 3", "product description 3", 108.49, 126.99, 27, true, "https://test.back.com/data/products/3/img1.png", "")
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+				mock.ExpectQuery("SELECT (.+) FROM products p WHERE p.available=true AND p.archived_at IS NULL AND \\(p.category_id = \\$1 OR EXISTS \\(SELECT 1 FROM product_categories pc WHERE pc.product_id = p.id AND pc.category_id = \\$1\\)\\) ORDER BY p.id LIMIT (.+) OFFSET").
 					WithArgs(1, 3, 0).WillReturnRows(rows)
 			},
-			input: args{1, 3, 0},
+			input: args{1, domain.PageRequest{Limit: 2}},
 			want: []domain.Product{
-				{Id: 1, CategoryId: 1, Name: "product name 1", Description: "product description 1", Price: 0.99, UndiscountedPrice: 1.29, Stock: 12, Available: true, ImageUrl: "https://test.back.com/data/products/1/img1.png"},
-				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 1.99, UndiscountedPrice: 1.99, Stock: 2, Available: true, ImageUrl: "https://test.back.com/data/products/2/img1.png"},
-				{Id: 3, CategoryId: 1, Name: "product name 3", Description: "product description 3", Price: 108.49, UndiscountedPrice: 126.99, Stock: 27, Available: true, ImageUrl: "https://test.back.com/data/products/3/img1.png"},
+				{Id: 1, CategoryId: 1, Name: "product name 1", Description: "product description 1", Price: 99, UndiscountedPrice: 129, Stock: 12, Available: true, ImageUrl: "https://test.back.com/data/products/1/img1.png"},
+				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 199, UndiscountedPrice: 199, Stock: 2, Available: true, ImageUrl: "https://test.back.com/data/products/2/img1.png"},
 			},
+			wantPagination: domain.Pagination{Total: 7, Page: 1, PageSize: 2, NextCursor: domain.Cursor{Id: 2}.Encode()},
 		},
 		{
-			name: "Ok without pagination",
+			name: "Ok with subcategories",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "category_id", "name", "description", "price", "undiscounted_price", "stock", "available", "image_url"}).
-					AddRow(1, 1, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/data/products/1/img1.png").
-					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "https://test.back.com/data/products/2/img1.png").
-					AddRow(3, 1, "product name 3", "product description 3", 108.49, 126.99, 27, true, "https://test.back.com/data/products/3/img1.png")
-				mock.ExpectQuery("SELECT (.+) FROM products").
-					WithArgs(1, 0, 0).WillReturnRows(rows)
-			},
-			input: args{1, 0, 0},
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p WHERE p.available=true AND p.archived_at IS NULL AND \\(p.category_id IN \\(\\s+WITH RECURSIVE subcategories").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				rows := sqlmock.NewRows(columns).
+					AddRow(5, 4, "product name 5", "product description 5", 9.99, 9.99, 1, true, "https://test.back.com/data/products/5/img1.png", "")
+				mock.ExpectQuery("SELECT (.+) FROM products p WHERE p.available=true AND p.archived_at IS NULL AND \\(p.category_id IN (.+) OR EXISTS (.+)\\) ORDER BY p.id LIMIT (.+) OFFSET").
+					WithArgs(1, 3, 0).WillReturnRows(rows)
+			},
+			input:  args{1, domain.PageRequest{Limit: 2}},
+			filter: domain.ProductFilter{ProductFilterParams: domain.ProductFilterParams{IncludeSubcategories: true}},
 			want: []domain.Product{
-				{Id: 1, CategoryId: 1, Name: "product name 1", Description: "product description 1", Price: 0.99, UndiscountedPrice: 1.29, Stock: 12, Available: true, ImageUrl: "https://test.back.com/data/products/1/img1.png"},
-				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 1.99, UndiscountedPrice: 1.99, Stock: 2, Available: true, ImageUrl: "https://test.back.com/data/products/2/img1.png"},
-				{Id: 3, CategoryId: 1, Name: "product name 3", Description: "product description 3", Price: 108.49, UndiscountedPrice: 126.99, Stock: 27, Available: true, ImageUrl: "https://test.back.com/data/products/3/img1.png"},
+				{Id: 5, CategoryId: 4, Name: "product name 5", Description: "product description 5", Price: 999, UndiscountedPrice: 999, Stock: 1, Available: true, ImageUrl: "https://test.back.com/data/products/5/img1.png"},
 			},
+			wantPagination: domain.Pagination{Total: 1, Page: 1, PageSize: 2},
 		},
 	}
 
@@ -228,12 +326,13 @@ func TestGetCategoryProducts(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := r.GetProducts(tt.input.categoryId, tt.input.limit, tt.input.offset, "")
+			got, pagination, err := r.GetProducts(tt.input.categoryId, tt.input.page, tt.filter)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantPagination, pagination)
 			}
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
@@ -288,7 +387,7 @@ func TestCreateCategory(t *testing.T) {
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery("INSERT INTO categories").
-					WithArgs("Category name", "Category description", "", true).WillReturnRows(rows)
+					WithArgs("Category name", "Category description", "", true, nil).WillReturnRows(rows)
 
 				mock.ExpectExec("UPDATE categories").
 					WithArgs("https://test.back.com/data/categories/1/img1.png", 1).WillReturnResult(driver.ResultNoRows)
@@ -310,7 +409,7 @@ func TestCreateCategory(t *testing.T) {
 			name: "CategoryExists",
 			mock: func() {
 				mock.ExpectBegin()
-				mock.ExpectQuery("INSERT INTO categories").WithArgs("Category name", "Category description", "", true).WillReturnError(errors.New("category already exists"))
+				mock.ExpectQuery("INSERT INTO categories").WithArgs("Category name", "Category description", "", true, nil).WillReturnError(errors.New("category already exists"))
 				mock.ExpectRollback()
 			},
 			input: args{
@@ -480,6 +579,121 @@ func TestUpdateCategory(t *testing.T) {
 	}
 }
 
+func TestMoveCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newCategoryPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	type args struct {
+		categoryId int
+		parentId   int
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("LOCK TABLE categories").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("WITH RECURSIVE ancestors").
+					WithArgs(2, 1).WillReturnRows(sqlmock.NewRows([]string{"exists", "cycle"}).AddRow(true, false))
+				mock.ExpectQuery("UPDATE categories SET parent_id=\\$1 WHERE id=\\$2").
+					WithArgs(2, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			input: args{1, 2},
+		},
+		{
+			name: "Ok to top level",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE categories SET parent_id=\\$1 WHERE id=\\$2").
+					WithArgs(nil, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			input: args{1, 0},
+		},
+		{
+			name: "Under a subcategory",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("LOCK TABLE categories").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("WITH RECURSIVE ancestors").
+					WithArgs(3, 1).WillReturnRows(sqlmock.NewRows([]string{"exists", "cycle"}).AddRow(true, true))
+				mock.ExpectRollback()
+			},
+			input:   args{1, 3},
+			wantErr: true,
+		},
+		{
+			name: "Parent not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("LOCK TABLE categories").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("WITH RECURSIVE ancestors").
+					WithArgs(9, 1).WillReturnRows(sqlmock.NewRows([]string{"exists", "cycle"}).AddRow(false, false))
+				mock.ExpectRollback()
+			},
+			input:   args{1, 9},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.UpdateCategory(tt.input.categoryId, domain.UpdateCategoryInput{ParentId: &tt.input.parentId}, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetBreadcrumbs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newCategoryPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	rows := sqlmock.NewRows([]string{"id", "name"}).
+		AddRow(1, "Clothes").
+		AddRow(4, "Shirts")
+	mock.ExpectQuery("WITH RECURSIVE path (.+) ORDER BY depth DESC").
+		WithArgs(4, maxCategoryDepth).WillReturnRows(rows)
+
+	got, err := r.GetBreadcrumbs(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Breadcrumb{{Id: 1, Name: "Clothes"}, {Id: 4, Name: "Shirts"}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func stringPointer(s string) *string {
 	return &s
 }
@@ -487,3 +701,119 @@ func stringPointer(s string) *string {
 func boolPointer(b bool) *bool {
 	return &b
 }
+
+func TestDeleteCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newCategoryPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		id      int
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM categories WHERE id=(.+) FOR UPDATE").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT id FROM products WHERE category_id=(.+) FOR UPDATE").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3))
+				mock.ExpectQuery("SELECT EXISTS (.+) ordered_products").
+					WithArgs(pq.Array([]int{2, 3})).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("SELECT id FROM product_variants").
+					WithArgs(pq.Array([]int{2, 3})).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("DELETE FROM categories WHERE id=(.+)").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			id: 1,
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM categories").
+					WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			id:      2,
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+		{
+			name: "Products ordered",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM categories").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT id FROM products").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectQuery("SELECT EXISTS (.+) ordered_products").
+					WithArgs(pq.Array([]int{2})).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			id:      1,
+			wantErr: true,
+			errType: errors_handler.TypeForeignKeyViolation,
+		},
+		{
+			name: "Products ordered at commit",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM categories").
+					WithArgs(6).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+				mock.ExpectQuery("SELECT id FROM products").
+					WithArgs(6).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery("SELECT EXISTS (.+) ordered_products").
+					WithArgs(pq.Array([]int{7})).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("SELECT id FROM product_variants").
+					WithArgs(pq.Array([]int{7})).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("DELETE FROM categories").
+					WithArgs(6).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit().WillReturnError(&pq.Error{Code: "23503"})
+			},
+			id:      6,
+			wantErr: true,
+			errType: errors_handler.TypeForeignKeyViolation,
+		},
+	}
+
+	// The media of a category is only removed once its deletion is committed.
+	mediaFile := "./data/categories/6/image.jpg"
+	if err := os.MkdirAll("./data/categories/6", 0755); err != nil {
+		t.Fatalf("Error creating media: %v", err)
+	}
+	if err := os.WriteFile(mediaFile, []byte("image"), 0644); err != nil {
+		t.Fatalf("Error creating media: %v", err)
+	}
+	defer os.RemoveAll("./data/categories/6")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.DeleteCategory(tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+	assert.FileExists(t, mediaFile)
+}
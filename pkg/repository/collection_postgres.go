@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+type CollectionPostgres struct {
+	db *sqlx.DB
+}
+
+func newCollectionPostgres(db *sqlx.DB) *CollectionPostgres {
+	return &CollectionPostgres{db}
+}
+
+func (r *CollectionPostgres) GetAll(page domain.PageRequest) ([]domain.Collection, domain.Pagination, error) {
+	c := &queryConditions{}
+	c.add("c.available=true")
+
+	rows, pagination, err := selectPage[collectionRow](r.db, pageQuery{
+		columns: "c.*",
+		from:    collectionsTable + " c",
+		where:   c,
+		id:      "c.id",
+	}, page)
+
+	collections := make([]domain.Collection, len(rows))
+	for i, row := range rows {
+		collections[i] = row.Collection
+	}
+	return collections, pagination, err
+}
+
+// collectionRow is a collection listing row.
+type collectionRow struct {
+	domain.Collection
+	SortKey string `db:"sort_key"`
+}
+
+func (r collectionRow) position() (string, int) {
+	return r.SortKey, r.Id
+}
+
+func (r *CollectionPostgres) GetById(id int) (domain.Collection, error) {
+	var collection domain.Collection
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1 AND available=true", collectionsTable)
+
+	err := r.db.Get(&collection, query, id)
+	if err == sql.ErrNoRows {
+		return collection, errors_handler.NoRows()
+	}
+
+	return collection, err
+}
+
+// GetProducts returns the available products of a collection in the order
+// set by the admins.
+func (r *CollectionPostgres) GetProducts(collectionId int, page domain.PageRequest) ([]domain.Product, domain.Pagination, error) {
+	c := &queryConditions{}
+	c.add("cp.collection_id=" + c.arg(collectionId))
+	c.add("p.available=true AND p.archived_at IS NULL")
+
+	rows, pagination, err := selectPage[productRow](r.db, pageQuery{
+		columns: productColumns,
+		from:    fmt.Sprintf("%s p INNER JOIN %s cp ON cp.product_id = p.id", productsTable, collectionProductsTable),
+		where:   c,
+		key:     "cp.position",
+		id:      "p.id",
+	}, page)
+	return productsOf(rows), pagination, err
+}
+
+func (r *CollectionPostgres) CreateCollection(input domain.CreateCollectionInput) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (name, description, available) VALUES ($1, $2, $3) RETURNING id", collectionsTable)
+
+	err := r.db.QueryRow(query, input.Name, input.Description, input.Available).Scan(&id)
+	return id, collectionError(err)
+}
+
+func (r *CollectionPostgres) UpdateCollection(id int, input domain.UpdateCollectionInput) error {
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.Name != nil {
+		setValues = append(setValues, fmt.Sprintf("name=$%d", argId))
+		args = append(args, *input.Name)
+		argId++
+	}
+
+	if input.Description != nil {
+		setValues = append(setValues, fmt.Sprintf("description=$%d", argId))
+		args = append(args, *input.Description)
+		argId++
+	}
+
+	if input.Available != nil {
+		setValues = append(setValues, fmt.Sprintf("available=$%d", argId))
+		args = append(args, *input.Available)
+		argId++
+	}
+
+	setQuery := strings.Join(setValues, ", ")
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id=$%d RETURNING id", collectionsTable, setQuery, argId)
+	args = append(args, id)
+
+	err := r.db.QueryRow(query, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors_handler.NoRows()
+	}
+	return collectionError(err)
+}
+
+func (r *CollectionPostgres) DeleteCollection(id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1 RETURNING id", collectionsTable)
+
+	err := r.db.QueryRow(query, id).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors_handler.NoRows()
+	}
+	return err
+}
+
+// SetProducts replaces the products of a collection, they are shown in the
+// order given.
+func (r *CollectionPostgres) SetProducts(collectionId int, productIds []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 FOR UPDATE", collectionsTable)
+	if err := tx.QueryRow(query, collectionId).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE collection_id=$1", collectionProductsTable)
+	if _, err := tx.Exec(deleteQuery, collectionId); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (collection_id, product_id, position) VALUES ($1, $2, $3)", collectionProductsTable)
+	for position, productId := range productIds {
+		if _, err := tx.Exec(insertQuery, collectionId, productId, position); err != nil {
+			return collectionError(err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// collectionError translates the constraint violations of the collection
+// tables.
+func collectionError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		return errors_handler.AlreadyExists("collection")
+	case "foreign_key_violation":
+		return errors_handler.ForeignKeyViolation()
+	}
+	return err
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCollectionProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newCollectionPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	type args struct {
+		collectionId int
+		page         domain.PageRequest
+	}
+
+	columns := []string{"id", "category_id", "name", "description", "price", "undiscounted_price", "stock", "available", "image_url", "sort_key"}
+
+	tests := []struct {
+		name           string
+		mock           func()
+		input          args
+		want           []domain.Product
+		wantPagination domain.Pagination
+		wantErr        bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(3, 1, "product name 3", "product description 3", 108.49, 126.99, 27, true, "https://test.back.com/products/3/img1.png", "0").
+					AddRow(1, 2, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/products/1/img1.png", "1")
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p INNER JOIN collection_products cp ON cp.product_id = p.id WHERE cp.collection_id=\\$1 AND p.available=true AND p.archived_at IS NULL").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("SELECT (.+), \\(cp.position\\)::text AS sort_key FROM products p INNER JOIN collection_products cp (.+) ORDER BY cp.position, p.id LIMIT (.+) OFFSET").
+					WithArgs(1, 3, 0).WillReturnRows(rows)
+			},
+			input: args{1, domain.PageRequest{Limit: 2}},
+			want: []domain.Product{
+				{Id: 3, CategoryId: 1, Name: "product name 3", Description: "product description 3", Price: 10849, UndiscountedPrice: 12699, Stock: 27, Available: true, ImageUrl: "https://test.back.com/products/3/img1.png"},
+				{Id: 1, CategoryId: 2, Name: "product name 1", Description: "product description 1", Price: 99, UndiscountedPrice: 129, Stock: 12, Available: true, ImageUrl: "https://test.back.com/products/1/img1.png"},
+			},
+			wantPagination: domain.Pagination{Total: 2, Page: 1, PageSize: 2},
+		},
+		{
+			name: "Ok after cursor",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 2, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/products/1/img1.png", "1")
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("SELECT (.+) WHERE cp.collection_id=\\$1 AND p.available=true AND p.archived_at IS NULL AND \\(cp.position, p.id\\) > \\(\\$2, \\$3\\) ORDER BY cp.position, p.id LIMIT \\$4$").
+					WithArgs(1, "0", 3, 2).WillReturnRows(rows)
+			},
+			input: args{1, domain.PageRequest{Limit: 1, Cursor: &domain.Cursor{Key: "0", Id: 3}}},
+			want: []domain.Product{
+				{Id: 1, CategoryId: 2, Name: "product name 1", Description: "product description 1", Price: 99, UndiscountedPrice: 129, Stock: 12, Available: true, ImageUrl: "https://test.back.com/products/1/img1.png"},
+			},
+			wantPagination: domain.Pagination{Total: 2, PageSize: 1, PrevCursor: domain.Cursor{Key: "1", Id: 1, Before: true}.Encode()},
+		},
+		{
+			name: "Cursor of another sort",
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+			},
+			input:   args{1, domain.PageRequest{Limit: 1, Cursor: &domain.Cursor{Sort: domain.SortPriceAsc, Key: "0", Id: 3}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, pagination, err := r.GetProducts(tt.input.collectionId, tt.input.page)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantPagination, pagination)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetCollectionProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newCollectionPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	type args struct {
+		collectionId int
+		productIds   []int
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM collections WHERE id=(.+) FOR UPDATE").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec("DELETE FROM collection_products").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("INSERT INTO collection_products").
+					WithArgs(1, 7, 0).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO collection_products").
+					WithArgs(1, 3, 1).WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+			input: args{1, []int{7, 3}},
+		},
+		{
+			name: "Collection not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM collections").
+					WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			input:   args{2, []int{7}},
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+		{
+			name: "Product not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM collections").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec("DELETE FROM collection_products").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO collection_products").
+					WithArgs(1, 99, 0).WillReturnError(&pq.Error{Code: "23503"})
+				mock.ExpectRollback()
+			},
+			input:   args{1, []int{99}},
+			wantErr: true,
+			errType: errors_handler.TypeForeignKeyViolation,
+		},
+		{
+			name: "Insert error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM collections").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec("DELETE FROM collection_products").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO collection_products").
+					WithArgs(1, 7, 0).WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
+			},
+			input:   args{1, []int{7}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.SetProducts(tt.input.collectionId, tt.input.productIds)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+type CurrencyPostgres struct {
+	db *sqlx.DB
+}
+
+func newCurrencyPostgres(db *sqlx.DB) *CurrencyPostgres {
+	return &CurrencyPostgres{db}
+}
+
+func (r *CurrencyPostgres) GetRates() ([]domain.ExchangeRate, error) {
+	rates := make([]domain.ExchangeRate, 0)
+	query := fmt.Sprintf("SELECT currency, rate, updated_at FROM %s ORDER BY currency", exchangeRatesTable)
+	err := r.db.Select(&rates, query)
+	return rates, err
+}
+
+func (r *CurrencyPostgres) GetRate(currency string) (domain.ExchangeRate, error) {
+	var rate domain.ExchangeRate
+	query := fmt.Sprintf("SELECT currency, rate, updated_at FROM %s WHERE currency=$1", exchangeRatesTable)
+	err := r.db.Get(&rate, query, currency)
+	if err == sql.ErrNoRows {
+		return rate, errors_handler.NoRows()
+	}
+	return rate, err
+}
+
+// SetRates creates or updates the rates in a single transaction, so a
+// failed import leaves the rates unchanged.
+func (r *CurrencyPostgres) SetRates(rates []domain.ExchangeRateInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`INSERT INTO %s (currency, rate, updated_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (currency) DO UPDATE SET rate=EXCLUDED.rate, updated_at=EXCLUDED.updated_at`,
+		exchangeRatesTable)
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, rate := range rates {
+		if _, err := stmt.Exec(rate.Currency, rate.Rate); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *CurrencyPostgres) DeleteRate(currency string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE currency=$1", exchangeRatesTable)
+
+	result, err := r.db.Exec(query, currency)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors_handler.NoRows()
+	}
+	return nil
+}
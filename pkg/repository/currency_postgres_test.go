@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExchangeRate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newCurrencyPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	tests := []struct {
+		name     string
+		mock     func()
+		currency string
+		want     domain.Rate
+		wantErr  bool
+		errType  errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectQuery("SELECT currency, rate, updated_at FROM exchange_rates WHERE currency=\\$1").
+					WithArgs("EUR").
+					WillReturnRows(sqlmock.NewRows([]string{"currency", "rate", "updated_at"}).AddRow("EUR", "0.92150000", time.Now()))
+			},
+			currency: "EUR",
+			want:     92150000,
+		},
+		{
+			name: "Not supported",
+			mock: func() {
+				mock.ExpectQuery("SELECT currency, rate, updated_at FROM exchange_rates").
+					WithArgs("JPY").
+					WillReturnRows(sqlmock.NewRows([]string{"currency", "rate", "updated_at"}))
+			},
+			currency: "JPY",
+			wantErr:  true,
+			errType:  errors_handler.TypeNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetRate(tt.currency)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.currency, got.Currency)
+				assert.Equal(t, tt.want, got.Rate)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetExchangeRates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newCurrencyPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	rates := []domain.ExchangeRateInput{
+		{Currency: "EUR", Rate: 92150000},
+		{Currency: "GBP", Rate: 78900000},
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectPrepare("INSERT INTO exchange_rates \\(currency, rate, updated_at\\) VALUES \\(\\$1, \\$2, NOW\\(\\)\\) ON CONFLICT \\(currency\\) DO UPDATE")
+				mock.ExpectExec("INSERT INTO exchange_rates").
+					WithArgs("EUR", "0.92150000").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO exchange_rates").
+					WithArgs("GBP", "0.78900000").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Failed rate is rolled back",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectPrepare("INSERT INTO exchange_rates")
+				mock.ExpectExec("INSERT INTO exchange_rates").
+					WithArgs("EUR", "0.92150000").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO exchange_rates").
+					WithArgs("GBP", "0.78900000").
+					WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.SetRates(rates)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/storage"
+)
+
+type ImportPostgres struct {
+	db *sqlx.DB
+	s  *storage.Storage
+}
+
+func newImportPostgres(db *sqlx.DB, s *storage.Storage) *ImportPostgres {
+	return &ImportPostgres{db, s}
+}
+
+func (r *ImportPostgres) CreateJob(job domain.ImportJob) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (status, format, dry_run, total_rows) VALUES ($1, $2, $3, $4) RETURNING id", importJobsTable)
+
+	err := r.db.QueryRow(query, job.Status, job.Format, job.DryRun, job.TotalRows).Scan(&id)
+	return id, err
+}
+
+// UpdateJob records the progress of a job, or its result once it has
+// finished.
+func (r *ImportPostgres) UpdateJob(job domain.ImportJob) error {
+	query := fmt.Sprintf(`UPDATE %s SET
+		status=$1,
+		processed_rows=$2,
+		created_rows=$3,
+		updated_rows=$4,
+		failed_rows=$5,
+		errors=$6,
+		error=$7,
+		finished_at=$8,
+		updated_at=now()
+		WHERE id=$9`, importJobsTable)
+
+	_, err := r.db.Exec(query, job.Status, job.ProcessedRows, job.CreatedRows, job.UpdatedRows, job.FailedRows, job.Errors, job.Error, job.FinishedAt, job.Id)
+	return err
+}
+
+// ExpireJobs fails the running jobs which have not recorded their progress
+// since the given time, interrupted by a restart of the server. It returns
+// the number of jobs failed.
+func (r *ImportPostgres) ExpireJobs(before time.Time, reason string) (int, error) {
+	query := fmt.Sprintf(`UPDATE %s SET status=$1, error=$2, finished_at=now(), updated_at=now()
+		WHERE status=$3 AND updated_at < $4`, importJobsTable)
+
+	result, err := r.db.Exec(query, domain.ImportStatusFailed, reason, domain.ImportStatusRunning, before)
+	if err != nil {
+		return 0, err
+	}
+	expired, err := result.RowsAffected()
+	return int(expired), err
+}
+
+func (r *ImportPostgres) GetJob(id int) (domain.ImportJob, error) {
+	var job domain.ImportJob
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1", importJobsTable)
+
+	err := r.db.Get(&job, query, id)
+	if err == sql.ErrNoRows {
+		return job, errors_handler.NoRows()
+	}
+	return job, err
+}
+
+// GetProductIds returns the ids of the products with the given SKUs, by SKU.
+func (r *ImportPostgres) GetProductIds(skus []string) (map[string]int, error) {
+	ids := make(map[string]int, len(skus))
+
+	var rows []struct {
+		Id  int    `db:"id"`
+		Sku string `db:"sku"`
+	}
+	query := fmt.Sprintf("SELECT id, sku FROM %s WHERE sku = ANY($1)", productsTable)
+	if err := r.db.Select(&rows, query, pq.Array(skus)); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		ids[row.Sku] = row.Id
+	}
+	return ids, nil
+}
+
+// GetCategoryIds returns which of the given category ids exist.
+func (r *ImportPostgres) GetCategoryIds(ids []int) (map[int]bool, error) {
+	existing := make(map[int]bool, len(ids))
+
+	var rows []int
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id = ANY($1)", categoriesTables)
+	if err := r.db.Select(&rows, query, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	for _, id := range rows {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// UpsertProduct creates the product with the SKU of the input, or updates
+// every field of it but the image when it exists. The image is replaced when
+// one is given. It reports whether the product was created.
+func (r *ImportPostgres) UpsertProduct(input domain.CreateProductInput, file multipart.File) (int, bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	var created bool
+	query := fmt.Sprintf(`INSERT INTO %s (
+		category_id,
+		name,
+		description,
+		image_url,
+		available,
+		price,
+		undiscounted_price,
+		stock,
+		sku
+	) VALUES ($1, $2, $3, '', $4, $5, $6, $7, $8)
+	ON CONFLICT (sku) DO UPDATE SET
+		category_id=EXCLUDED.category_id,
+		name=EXCLUDED.name,
+		description=EXCLUDED.description,
+		available=EXCLUDED.available,
+		price=EXCLUDED.price,
+		undiscounted_price=EXCLUDED.undiscounted_price,
+		stock=EXCLUDED.stock
+	RETURNING id, xmax = 0`, productsTable)
+
+	row := tx.QueryRow(query, input.CategoryId, input.Name, input.Description, input.Available, input.Price, input.UndiscountedPrice, input.Stock, input.Sku)
+	if err := row.Scan(&id, &created); err != nil {
+		return 0, false, productError(err)
+	}
+
+	var replaced string
+	if input.ImgFile != nil && file != nil {
+		url, err := r.s.UploadProductImage(id, input.ImgFile, file)
+		if err != nil {
+			return 0, false, err
+		}
+
+		updateQuery := fmt.Sprintf("UPDATE %s SET image_url=$1 WHERE id=$2", productsTable)
+		if _, err := tx.Exec(updateQuery, url, id); err != nil {
+			return 0, false, err
+		}
+
+		replaced, err = replacePrimaryImage(tx, id, input.ImgFile.Filename, url)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, err
+	}
+	removeProductImage(r.s, id, replaced)
+	return id, created, nil
+}
+
+// ExportProducts calls fn with every product of the catalog, archived and
+// unavailable ones included, in id order. The products are streamed, not
+// loaded at once.
+func (r *ImportPostgres) ExportProducts(fn func(record domain.ProductRecord) error) error {
+	query := fmt.Sprintf(`SELECT
+		COALESCE(sku, '') AS sku,
+		category_id,
+		name,
+		description,
+		price,
+		undiscounted_price,
+		stock,
+		available,
+		image_url
+		FROM %s ORDER BY id`, productsTable)
+
+	rows, err := r.db.Queryx(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record domain.ProductRecord
+		if err := rows.StructScan(&record); err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpsertProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newImportPostgres(sqlx.NewDb(db, "sqlmock"), nil)
+
+	input := domain.CreateProductInput{
+		CategoryId:        1,
+		Sku:               "SKU-1",
+		Name:              "product name",
+		Description:       "product description",
+		Available:         true,
+		Price:             999,
+		UndiscountedPrice: 1299,
+		Stock:             4,
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		wantId      int
+		wantCreated bool
+		wantErr     bool
+		errType     errors_handler.Type
+	}{
+		{
+			name: "Created",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO products (.+) ON CONFLICT \\(sku\\) DO UPDATE SET (.+) RETURNING id, xmax = 0").
+					WithArgs(1, "product name", "product description", true, domain.Money(999), domain.Money(1299), 4, "SKU-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(7, true))
+				mock.ExpectCommit()
+			},
+			wantId:      7,
+			wantCreated: true,
+		},
+		{
+			name: "Updated",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO products (.+) ON CONFLICT \\(sku\\)").
+					WithArgs(1, "product name", "product description", true, domain.Money(999), domain.Money(1299), 4, "SKU-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(3, false))
+				mock.ExpectCommit()
+			},
+			wantId: 3,
+		},
+		{
+			name: "Category not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO products").
+					WithArgs(1, "product name", "product description", true, domain.Money(999), domain.Money(1299), 4, "SKU-1").
+					WillReturnError(&pq.Error{Code: "23503"})
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errType: errors_handler.TypeForeignKeyViolation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			id, created, err := r.UpsertProduct(input, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantId, id)
+				assert.Equal(t, tt.wantCreated, created)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetImportJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newImportPostgres(sqlx.NewDb(db, "sqlmock"), nil)
+
+	columns := []string{"id", "status", "format", "dry_run", "total_rows", "processed_rows", "created_rows", "updated_rows", "failed_rows", "errors", "error"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   int
+		want    domain.ImportJob
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "done", "csv", false, 3, 3, 1, 1, 1, []byte(`[{"row":4,"sku":"SKU-3","error":"name: cannot be blank."}]`), "")
+				mock.ExpectQuery("SELECT (.+) FROM import_jobs WHERE id=\\$1").
+					WithArgs(1).WillReturnRows(rows)
+			},
+			input: 1,
+			want: domain.ImportJob{
+				Id:            1,
+				Status:        domain.ImportStatusDone,
+				Format:        domain.CatalogFormatCSV,
+				TotalRows:     3,
+				ProcessedRows: 3,
+				CreatedRows:   1,
+				UpdatedRows:   1,
+				FailedRows:    1,
+				Errors:        domain.ImportRowErrors{{Row: 4, Sku: "SKU-3", Error: "name: cannot be blank."}},
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM import_jobs").
+					WithArgs(2).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			input:   2,
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetJob(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExpireImportJobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newImportPostgres(sqlx.NewDb(db, "sqlmock"), nil)
+
+	before := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("UPDATE import_jobs SET status=\\$1, error=\\$2, (.+) WHERE status=\\$3 AND updated_at < \\$4").
+		WithArgs(domain.ImportStatusFailed, "the import was interrupted", domain.ImportStatusRunning, before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	expired, err := r.ExpireJobs(before, "the import was interrupted")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, expired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+type MediaPostgres struct {
+	db *sqlx.DB
+	s  *storage.Storage
+}
+
+func newMediaPostgres(db *sqlx.DB, s *storage.Storage) *MediaPostgres {
+	return &MediaPostgres{db, s}
+}
+
+// removeMedia removes the media directory of an owner whose deletion is
+// committed. A failure is only logged, the deletion has happened: the
+// directory left belongs to no row and is removed by CollectGarbage.
+func removeMedia(s *storage.Storage, kind storage.MediaKind, ownerId int) {
+	if err := s.Media.DeleteOwner(kind, ownerId); err != nil {
+		logrus.Errorf("remove media %s/%d: %s", kind, ownerId, err.Error())
+	}
+}
+
+var mediaOwnerTables = []struct {
+	kind  storage.MediaKind
+	table string
+}{
+	{storage.UsersMedia, usersTable},
+	{storage.CategoriesMedia, categoriesTables},
+	{storage.ProductsMedia, productsTable},
+	{storage.VariantsMedia, productVariantsTable},
+}
+
+func (r *MediaPostgres) CollectGarbage(dryRun bool) ([]string, error) {
+	removed := make([]string, 0)
+
+	for _, owner := range mediaOwnerTables {
+		ids, err := r.s.Media.ListOwners(owner.kind)
+		if err != nil {
+			return removed, err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		var existing []int
+		query := fmt.Sprintf("SELECT id FROM %s", owner.table)
+		if err := r.db.Select(&existing, query); err != nil {
+			return removed, err
+		}
+		exists := make(map[int]struct{}, len(existing))
+		for _, id := range existing {
+			exists[id] = struct{}{}
+		}
+
+		for _, id := range ids {
+			if _, ok := exists[id]; ok {
+				continue
+			}
+			if !dryRun {
+				if err := r.s.Media.DeleteOwner(owner.kind, id); err != nil {
+					return removed, err
+				}
+			}
+			removed = append(removed, fmt.Sprintf("%s/%d", owner.kind, id))
+		}
+	}
+	return removed, nil
+}
+
+// ResetData removes every user, category, product and order together with
+// their media files.
+func (r *MediaPostgres) ResetData() error {
+	query := fmt.Sprintf("TRUNCATE %s, %s, %s, %s, %s RESTART IDENTITY CASCADE",
+		orderedProductsTable, ordersTable, productsTable, categoriesTables, usersTable)
+	if _, err := r.db.Exec(query); err != nil {
+		return err
+	}
+
+	for _, owner := range mediaOwnerTables {
+		ids, err := r.s.Media.ListOwners(owner.kind)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := r.s.Media.DeleteOwner(owner.kind, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// migrationsLockKey is the key of the advisory lock held while migrating,
+// so replicas starting at the same time do not race each other.
+const migrationsLockKey = 7_120_001
+
+var migrationFileRegexp = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// ErrSchemaAhead is returned when the database has migrations applied
+// which are unknown to the binary.
+var ErrSchemaAhead = errors.New("database schema is ahead of the binary")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db     *sqlx.DB
+	source fs.FS
+}
+
+// NewMigrator returns a migrator which applies the *.up.sql and *.down.sql
+// files found at the root of source.
+func NewMigrator(db *sqlx.DB, source fs.FS) *Migrator {
+	return &Migrator{db, source}
+}
+
+// Migrations returns the migrations found in the source sorted by version.
+func (m *Migrator) Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		matches := migrationFileRegexp.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(m.source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up script", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// LatestVersion returns the version of the newest migration in the source.
+func (m *Migrator) LatestVersion() (int, error) {
+	migrations, err := m.Migrations()
+	if err != nil || len(migrations) == 0 {
+		return 0, err
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// Version returns the newest migration version recorded in the database.
+func (m *Migrator) Version() (int, error) {
+	var exists bool
+	err := m.db.Get(&exists, "SELECT to_regclass($1) IS NOT NULL", schemaMigrationsTable)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	var version int
+	query := fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s", schemaMigrationsTable)
+	err = m.db.Get(&version, query)
+	return version, err
+}
+
+// Up applies every pending migration, each one in its own transaction,
+// and returns the applied ones. It fails with ErrSchemaAhead when the
+// database knows migrations the source does not.
+func (m *Migrator) Up() ([]Migration, error) {
+	done := make([]Migration, 0)
+
+	err := m.withLock(func(conn *sqlx.Conn) error {
+		migrations, applied, err := m.load(conn)
+		if err != nil {
+			return err
+		}
+		if err := checkNotAhead(migrations, applied); err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(conn, migration); err != nil {
+				return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the latest applied migration. It returns false when
+// there is nothing to roll back.
+func (m *Migrator) Down() (Migration, bool, error) {
+	var reverted Migration
+	var ok bool
+
+	err := m.withLock(func(conn *sqlx.Conn) error {
+		migrations, applied, err := m.load(conn)
+		if err != nil {
+			return err
+		}
+		if err := checkNotAhead(migrations, applied); err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			migration := migrations[i]
+			if _, found := applied[migration.Version]; !found {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d has no down script", migration.Version)
+			}
+			if err := m.revert(conn, migration); err != nil {
+				return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+			}
+			reverted, ok = migration, true
+			return nil
+		}
+		return nil
+	})
+	return reverted, ok, err
+}
+
+// Status returns every known migration with the time it was applied, if any.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	conn, err := m.db.Connx(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	migrations, applied, err := m.load(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// withLock runs fn on a single connection holding the migrations advisory lock.
+func (m *Migrator) withLock(fn func(conn *sqlx.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationsLockKey); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationsLockKey)
+
+	return fn(conn)
+}
+
+func (m *Migrator) load(conn *sqlx.Conn) ([]Migration, map[int]time.Time, error) {
+	if err := ensureMigrationsTable(conn); err != nil {
+		return nil, nil, err
+	}
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, nil, err
+	}
+	applied, err := appliedVersions(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return migrations, applied, nil
+}
+
+func checkNotAhead(migrations []Migration, applied map[int]time.Time) error {
+	known := make(map[int]struct{}, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = struct{}{}
+	}
+	for version := range applied {
+		if _, ok := known[version]; !ok {
+			return fmt.Errorf("%w: unknown migration %d is applied", ErrSchemaAhead, version)
+		}
+	}
+	return nil
+}
+
+func ensureMigrationsTable(conn *sqlx.Conn) error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		version INT NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+	)`, schemaMigrationsTable)
+	_, err := conn.ExecContext(context.Background(), query)
+	return err
+}
+
+func appliedVersions(conn *sqlx.Conn) (map[int]time.Time, error) {
+	query := fmt.Sprintf("SELECT version, applied_at FROM %s", schemaMigrationsTable)
+	rows, err := conn.QueryContext(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) apply(conn *sqlx.Conn, migration Migration) error {
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.Up); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES ($1, $2, $3)", schemaMigrationsTable)
+	if _, err := tx.Exec(query, migration.Version, migration.Name, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) revert(conn *sqlx.Conn, migration Migration) error {
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.Down); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE version=$1", schemaMigrationsTable)
+	if _, err := tx.Exec(query, migration.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
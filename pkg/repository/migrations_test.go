@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMigrationsSource() fstest.MapFS {
+	return fstest.MapFS{
+		"000001_init.up.sql":     {Data: []byte("CREATE TABLE a (id INT);")},
+		"000001_init.down.sql":   {Data: []byte("DROP TABLE a;")},
+		"000002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"000002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"README.md":              {Data: []byte("not a migration")},
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	m := NewMigrator(nil, testMigrationsSource())
+
+	got, err := m.Migrations()
+	assert.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "init", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+		{Version: 2, Name: "second", Up: "CREATE TABLE b (id INT);", Down: "DROP TABLE b;"},
+	}, got)
+
+	_, err = NewMigrator(nil, fstest.MapFS{
+		"000003_broken.down.sql": {Data: []byte("DROP TABLE c;")},
+	}).Migrations()
+	assert.Error(t, err)
+}
+
+func TestMigrateUp(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	m := NewMigrator(sqlx.NewDb(db, "sqlmock"), testMigrationsSource())
+
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(migrationsLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, testTime()))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE b").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").
+		WithArgs(2, "second", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(migrationsLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := m.Up()
+	assert.NoError(t, err)
+	if assert.Len(t, applied, 1) {
+		assert.Equal(t, 2, applied[0].Version)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateDown(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	m := NewMigrator(sqlx.NewDb(db, "sqlmock"), testMigrationsSource())
+
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(migrationsLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, testTime()))
+	mock.ExpectBegin()
+	mock.ExpectExec("DROP TABLE a").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations").
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(migrationsLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	reverted, ok, err := m.Down()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, reverted.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateUpSchemaAhead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	m := NewMigrator(sqlx.NewDb(db, "sqlmock"), testMigrationsSource())
+
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(migrationsLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+			AddRow(1, testTime()).AddRow(2, testTime()).AddRow(3, testTime()))
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(migrationsLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := m.Up()
+	assert.ErrorIs(t, err, ErrSchemaAhead)
+	assert.Empty(t, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testTime() time.Time {
+	return time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+// queryConditions builds a WHERE clause whose values are all passed as query
+// arguments.
+type queryConditions struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (c *queryConditions) add(condition string) {
+	c.conditions = append(c.conditions, condition)
+}
+
+// arg appends a query argument and returns its placeholder.
+func (c *queryConditions) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+func (c *queryConditions) where() string {
+	if len(c.conditions) == 0 {
+		return "true"
+	}
+	return strings.Join(c.conditions, " AND ")
+}
+
+func (c *queryConditions) copy() *queryConditions {
+	return &queryConditions{
+		conditions: append([]string(nil), c.conditions...),
+		args:       append([]interface{}(nil), c.args...),
+	}
+}
+
+// pageQuery describes a listing paginated by selectPage.
+type pageQuery struct {
+	columns string
+	from    string
+	where   *queryConditions
+	// key is the sort key expression, empty when the listing is sorted by
+	// id only.
+	key  string
+	id   string
+	desc bool
+	// sort names the sort of the listing, cursors are only valid for the
+	// sort they were issued for.
+	sort string
+}
+
+// pageRow is a listing row, selected with its sort key as sort_key.
+type pageRow interface {
+	position() (key string, id int)
+}
+
+// selectPage selects a page of a listing, after or before the cursor when
+// the page request has one and at its offset otherwise.
+func selectPage[T pageRow](db *sqlx.DB, q pageQuery, page domain.PageRequest) ([]T, domain.Pagination, error) {
+	rows := make([]T, 0)
+	pagination := domain.Pagination{PageSize: page.Limit}
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", q.from, q.where.where())
+	if err := db.Get(&pagination.Total, countQuery, q.where.args...); err != nil {
+		return nil, pagination, err
+	}
+
+	c := q.where.copy()
+	cursor := page.Cursor
+	backward := cursor != nil && cursor.Before
+	desc := q.desc != backward
+
+	if cursor != nil {
+		if cursor.Sort != q.sort {
+			return nil, pagination, errors_handler.BadRequest("cursor: does not match the sort")
+		}
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		if q.key == "" {
+			c.add(fmt.Sprintf("%s %s %s", q.id, op, c.arg(cursor.Id)))
+		} else {
+			c.add(fmt.Sprintf("(%s, %s) %s (%s, %s)", q.key, q.id, op, c.arg(cursor.Key), c.arg(cursor.Id)))
+		}
+	}
+
+	direction := ""
+	if desc {
+		direction = " DESC"
+	}
+	key := "''"
+	orderBy := q.id + direction
+	if q.key != "" {
+		key = fmt.Sprintf("(%s)::text", q.key)
+		orderBy = q.key + direction + ", " + orderBy
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s AS sort_key FROM %s WHERE %s ORDER BY %s LIMIT %s",
+		q.columns, key, q.from, c.where(), orderBy, c.arg(page.Limit+1))
+	if cursor == nil {
+		query += " OFFSET " + c.arg(page.Offset)
+		if page.Limit > 0 {
+			pagination.Page = page.Offset/page.Limit + 1
+		}
+	}
+
+	if err := db.Select(&rows, query, c.args...); err != nil {
+		return nil, pagination, err
+	}
+
+	more := len(rows) > page.Limit
+	if more {
+		rows = rows[:page.Limit]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, pagination, nil
+	}
+
+	hasNext, hasPrev := more, cursor != nil || page.Offset > 0
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		key, id := rows[len(rows)-1].position()
+		pagination.NextCursor = domain.Cursor{Sort: q.sort, Key: key, Id: id}.Encode()
+	}
+	if hasPrev {
+		key, id := rows[0].position()
+		pagination.PrevCursor = domain.Cursor{Sort: q.sort, Key: key, Id: id, Before: true}.Encode()
+	}
+
+	return rows, pagination, nil
+}
@@ -7,11 +7,34 @@ import (
 )
 
 const (
-	usersTable           = "users"
-	ordersTable          = "orders"
-	productsTable        = "products"
-	orderedProductsTable = "ordered_products"
-	categoriesTables     = "categories"
+	usersTable               = "users"
+	ordersTable              = "orders"
+	productsTable            = "products"
+	orderedProductsTable     = "ordered_products"
+	categoriesTables         = "categories"
+	productOptionsTable      = "product_options"
+	productVariantsTable     = "product_variants"
+	productImagesTable       = "product_images"
+	attributesTable          = "attributes"
+	productAttributesTable   = "product_attributes"
+	searchQueriesTable       = "search_queries"
+	productCategoriesTable   = "product_categories"
+	collectionsTable         = "collections"
+	collectionProductsTable  = "collection_products"
+	importJobsTable          = "import_jobs"
+	reviewsTable             = "reviews"
+	reviewReportsTable       = "review_reports"
+	wishlistItemsTable       = "wishlist_items"
+	relatedProductsTable     = "related_products"
+	exchangeRatesTable       = "exchange_rates"
+	taxClassesTable          = "tax_classes"
+	taxRatesTable            = "tax_rates"
+	promoCodesTable          = "promo_codes"
+	promoCodeProductsTable   = "promo_code_products"
+	promoCodeCategoriesTable = "promo_code_categories"
+	priceSchedulesTable      = "price_schedules"
+
+	schemaMigrationsTable = "schema_migrations"
 )
 
 type Config struct {
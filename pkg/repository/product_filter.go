@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+// Filters a facet count can leave out, see newProductConditions.
+const (
+	noFacet         = ""
+	priceFacet      = "price"
+	categoriesFacet = "categories"
+	stockFacet      = "stock"
+)
+
+// attributeFacet names the filter on an attribute.
+func attributeFacet(name string) string {
+	return "attribute:" + name
+}
+
+// priceBucketEdges are the upper bounds of the price facet buckets.
+var priceBucketEdges = []domain.Money{1000, 2500, 5000, 10000, 25000, 50000, 100000}
+
+// newProductConditions returns the conditions shared by the product listings
+// and their facet counts, selecting the available products matching the
+// filter, in a category when categoryId is not 0. The products table is
+// aliased p. The skipped filter is left out, it is the one of the facet
+// being counted.
+func newProductConditions(categoryId int, filter domain.ProductFilter, skipped string) *queryConditions {
+	c := &queryConditions{}
+
+	// Products are also matched on the trigrams of their name, so misspelled
+	// searches still find them.
+	if filter.Search != "" {
+		c.arg(filter.Search)
+		c.add(fmt.Sprintf("(p.search_vector @@ %s OR %s <%% p.name)", searchQuery, searchArg))
+	}
+
+	c.add("p.available=true AND p.archived_at IS NULL")
+
+	if categoryId != 0 && filter.IncludeSubcategories {
+		c.add(inCategories(fmt.Sprintf(`IN (
+			WITH RECURSIVE subcategories AS (
+				SELECT id FROM %[1]s WHERE id=%[2]s
+				UNION
+				SELECT c.id FROM %[1]s c
+				INNER JOIN subcategories s ON c.parent_id = s.id
+				WHERE c.available=true AND c.archived_at IS NULL
+			)
+			SELECT id FROM subcategories
+		)`, categoriesTables, c.arg(categoryId))))
+	} else if categoryId != 0 {
+		c.add(inCategories("= " + c.arg(categoryId)))
+	}
+
+	if skipped != priceFacet {
+		if filter.MinPrice != nil {
+			c.add("p.price>=" + c.arg(*filter.MinPrice))
+		}
+		if filter.MaxPrice != nil {
+			c.add("p.price<=" + c.arg(*filter.MaxPrice))
+		}
+	}
+
+	if filter.Discounted {
+		c.add("p.price<p.undiscounted_price")
+	}
+
+	if filter.InStock && skipped != stockFacet {
+		c.add(inStockExpression)
+	}
+
+	if len(filter.CategoryIds) > 0 && skipped != categoriesFacet {
+		c.add(inCategories(fmt.Sprintf("= ANY(%s)", c.arg(pq.Array(filter.CategoryIds)))))
+	}
+
+	for _, name := range sortedAttributeNames(filter) {
+		if skipped == attributeFacet(name) {
+			continue
+		}
+		c.add(fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM %s pa
+			INNER JOIN %s a ON a.id = pa.attribute_id
+			WHERE pa.product_id = p.id AND a.name = %s AND pa.value = ANY(%s)
+		)`, productAttributesTable, attributesTable, c.arg(name), c.arg(pq.Array(filter.Attributes[name]))))
+	}
+
+	return c
+}
+
+// inCategories is true for the products whose primary category, or one of
+// their other categories, matches the category condition, e.g. "= $1".
+func inCategories(condition string) string {
+	return fmt.Sprintf("(p.category_id %[2]s OR EXISTS (SELECT 1 FROM %[1]s pc WHERE pc.product_id = p.id AND pc.category_id %[2]s))",
+		productCategoriesTable, condition)
+}
+
+// searchArg is the placeholder of the search. newProductConditions passes it
+// first, so the relevance sort and the highlight can refer to it.
+const searchArg = "$1"
+
+// searchQuery is the full-text query of the search.
+var searchQuery = fmt.Sprintf("websearch_to_tsquery('english', %s)", searchArg)
+
+// searchRank is the relevance of a product to the search: its full-text rank,
+// where name matches weigh more than description ones, plus the similarity
+// of its name to the search.
+var searchRank = fmt.Sprintf("ts_rank(p.search_vector, %s) + word_similarity(%s, p.name)", searchQuery, searchArg)
+
+// searchHighlight is the product name and description fragments matching the
+// search, with the matches wrapped in <mark> tags.
+var searchHighlight = fmt.Sprintf(`ts_headline('english', p.name || '. ' || p.description, %s,
+	'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS highlight`, searchQuery)
+
+// productColumns are the columns of a product, the search vector is left out.
+const productColumns = "p.id, p.category_id, p.tax_class_id, p.sku, p.name, p.description, p.price, p.undiscounted_price, p.image_url, p.available, p.stock, p.created_at, p.rating_average, p.rating_count, p.archived_at, " + activeSaleColumn
+
+// activeSaleColumn is the sale the product is in, as a JSON object, or null.
+const activeSaleColumn = `(SELECT json_build_object(
+		'id', s.id, 'name', s.name, 'percent', s.percent, 'regular_price', p.regular_price, 'starts_at', s.starts_at, 'ends_at', s.ends_at
+	) FROM ` + priceSchedulesTable + ` s WHERE s.id = p.sale_id AND p.regular_price IS NOT NULL) AS active_sale`
+
+// inStockExpression is true for the products with stock, on the product
+// itself or on one of its available variants.
+var inStockExpression = fmt.Sprintf(`(p.stock > 0 OR EXISTS (
+	SELECT 1 FROM %s v WHERE v.product_id = p.id AND v.available=true AND v.stock > 0
+))`, productVariantsTable)
+
+// stockLevelExpression is the stock of a product, added up with the stock of
+// all its variants.
+var stockLevelExpression = fmt.Sprintf(`(p.stock + COALESCE((
+	SELECT SUM(v.stock) FROM %s v WHERE v.product_id = p.id
+), 0))`, productVariantsTable)
+
+// adminProductListing returns the page query of the admin product listing,
+// which sees unavailable and archived products.
+func adminProductListing(filter domain.AdminProductFilterParams) pageQuery {
+	c := &queryConditions{}
+
+	if filter.Search != "" {
+		c.add("p.name ILIKE " + c.arg("%"+escapeLike(filter.Search)+"%"))
+	}
+	if filter.CategoryId != 0 {
+		c.add(inCategories("= " + c.arg(filter.CategoryId)))
+	}
+	if filter.Available != nil {
+		c.add("p.available=" + c.arg(*filter.Available))
+	}
+	if filter.Archived != nil {
+		if *filter.Archived {
+			c.add("p.archived_at IS NOT NULL")
+		} else {
+			c.add("p.archived_at IS NULL")
+		}
+	}
+
+	switch filter.Stock {
+	case domain.StockLevelInStock:
+		c.add(stockLevelExpression + " > 0")
+	case domain.StockLevelLow:
+		c.add(fmt.Sprintf("%s BETWEEN 1 AND %s", stockLevelExpression, c.arg(domain.LowStockThreshold)))
+	case domain.StockLevelOutOfStock:
+		c.add(stockLevelExpression + " = 0")
+	}
+
+	return pageQuery{
+		columns: productColumns,
+		from:    productsTable + " p",
+		where:   c,
+		id:      "p.id",
+	}
+}
+
+// productKeyset returns the sort key of a product listing sort and whether
+// it is descending. The product id breaks ties, in the same direction, so
+// pages neither overlap nor skip products. Searches are sorted by relevance
+// by default.
+func productKeyset(filter domain.ProductFilter) (key string, desc bool) {
+	sort := filter.Sort
+	if sort == "" && filter.Search != "" {
+		sort = domain.SortRelevance
+	}
+
+	switch sort {
+	case domain.SortRelevance:
+		if filter.Search != "" {
+			return searchRank, true
+		}
+	case domain.SortPriceAsc:
+		return "p.price", false
+	case domain.SortPriceDesc:
+		return "p.price", true
+	case domain.SortNameAsc:
+		return "p.name", false
+	case domain.SortNameDesc:
+		return "p.name", true
+	case domain.SortNewest:
+		return "p.created_at", true
+	case domain.SortDiscount:
+		return "COALESCE((p.undiscounted_price - p.price) / NULLIF(p.undiscounted_price, 0), 0)", true
+	case domain.SortRating:
+		return "p.rating_average", true
+	}
+	return "", false
+}
+
+// productListing returns the page query of a product listing.
+func productListing(categoryId int, filter domain.ProductFilter) pageQuery {
+	key, desc := productKeyset(filter)
+	columns := productColumns
+	if filter.Search != "" {
+		columns += ", " + searchHighlight
+	}
+	return pageQuery{
+		columns: columns,
+		from:    productsTable + " p",
+		where:   newProductConditions(categoryId, filter, noFacet),
+		key:     key,
+		id:      "p.id",
+		desc:    desc,
+		sort:    filter.Sort,
+	}
+}
+
+// productRow is a product listing row.
+type productRow struct {
+	domain.Product
+	SortKey string `db:"sort_key"`
+}
+
+func (r productRow) position() (string, int) {
+	return r.SortKey, r.Id
+}
+
+func productsOf(rows []productRow) []domain.Product {
+	products := make([]domain.Product, len(rows))
+	for i, row := range rows {
+		products[i] = row.Product
+	}
+	return products
+}
+
+func sortedAttributeNames(filter domain.ProductFilter) []string {
+	names := make([]string, 0, len(filter.Attributes))
+	for name := range filter.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
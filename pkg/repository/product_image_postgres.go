@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"mime/multipart"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+type ProductImagePostgres struct {
+	db *sqlx.DB
+	s  *storage.Storage
+}
+
+func newProductImagePostgres(db *sqlx.DB, s *storage.Storage) *ProductImagePostgres {
+	return &ProductImagePostgres{db, s}
+}
+
+func (r *ProductImagePostgres) GetImages(productId int) ([]domain.ProductImage, error) {
+	images := make([]domain.ProductImage, 0)
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE product_id=$1 ORDER BY position, id", productImagesTable)
+
+	err := r.db.Select(&images, query, productId)
+	return images, err
+}
+
+func (r *ProductImagePostgres) AddImage(productId int, input domain.AddProductImageInput, file multipart.File) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 FOR UPDATE", productsTable)
+	if err := tx.QueryRow(query, productId).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errors_handler.NoRows()
+		}
+		return 0, err
+	}
+
+	fileName, url, err := r.s.AddProductImage(productId, input.ImgFile, file)
+	if err != nil {
+		return 0, err
+	}
+
+	insertQuery := fmt.Sprintf(`INSERT INTO %s (
+		product_id,
+		file_name,
+		url,
+		alt_text,
+		position
+	) SELECT $1, $2, $3, $4, COALESCE(MAX(position) + 1, 0) FROM %s WHERE product_id=$1
+	RETURNING id`, productImagesTable, productImagesTable)
+
+	if err := tx.QueryRow(insertQuery, productId, fileName, url, input.AltText).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if err := syncPrimaryImage(tx, productId); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *ProductImagePostgres) UpdateImage(productId, imageId int, input domain.UpdateProductImageInput) error {
+	query := fmt.Sprintf("UPDATE %s SET alt_text=$1 WHERE id=$2 AND product_id=$3", productImagesTable)
+
+	result, err := r.db.Exec(query, *input.AltText, imageId, productId)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors_handler.NoRows()
+	}
+	return nil
+}
+
+// SetImagePositions orders the gallery as imageIds lists it, the first image
+// becoming the primary one.
+func (r *ProductImagePostgres) SetImagePositions(productId int, imageIds []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("UPDATE %s SET position=$1 WHERE id=$2 AND product_id=$3", productImagesTable)
+	for position, imageId := range imageIds {
+		if _, err := tx.Exec(query, position, imageId, productId); err != nil {
+			return err
+		}
+	}
+
+	if err := syncPrimaryImage(tx, productId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ProductImagePostgres) DeleteImage(productId, imageId int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var fileName string
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1 AND product_id=$2 RETURNING file_name", productImagesTable)
+	err = tx.QueryRow(query, imageId, productId).Scan(&fileName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	if err := syncPrimaryImage(tx, productId); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	removeProductImage(r.s, productId, fileName)
+	return nil
+}
+
+// syncPrimaryImage keeps the image_url of a product equal to the url of the
+// first image of its gallery.
+func syncPrimaryImage(tx *sql.Tx, productId int) error {
+	query := fmt.Sprintf(`UPDATE %s SET image_url=COALESCE((
+		SELECT url FROM %s WHERE product_id=$1 ORDER BY position, id LIMIT 1
+	), '') WHERE id=$1`, productsTable, productImagesTable)
+
+	_, err := tx.Exec(query, productId)
+	return err
+}
+
+// replacePrimaryImage records a new primary image in the gallery in place of
+// the current one. It returns the file of the replaced image, to remove once
+// the transaction is committed, empty when there is none or the new image has
+// overwritten it.
+func replacePrimaryImage(tx *sql.Tx, productId int, fileName, url string) (string, error) {
+	var id int
+	var oldFileName string
+	query := fmt.Sprintf("SELECT id, file_name FROM %s WHERE product_id=$1 ORDER BY position, id LIMIT 1", productImagesTable)
+	err := tx.QueryRow(query, productId).Scan(&id, &oldFileName)
+	if err == sql.ErrNoRows {
+		insertQuery := fmt.Sprintf("INSERT INTO %s (product_id, file_name, url, position) VALUES ($1, $2, $3, 0)", productImagesTable)
+		_, err = tx.Exec(insertQuery, productId, fileName, url)
+		return "", err
+	}
+	if err != nil {
+		return "", err
+	}
+
+	updateQuery := fmt.Sprintf("UPDATE %s SET file_name=$1, url=$2 WHERE id=$3", productImagesTable)
+	if _, err := tx.Exec(updateQuery, fileName, url, id); err != nil {
+		return "", err
+	}
+
+	if oldFileName == fileName {
+		return "", nil
+	}
+	return oldFileName, nil
+}
+
+// removeProductImage removes the file of an image whose removal from the
+// gallery is committed. A failure is only logged, the image is already gone
+// from the gallery.
+func removeProductImage(s *storage.Storage, productId int, fileName string) {
+	if fileName == "" {
+		return
+	}
+	if err := s.DeleteProductImage(productId, fileName); err != nil {
+		logrus.Errorf("remove product %d image %s: %s", productId, fileName, err.Error())
+	}
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetImages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductImagePostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	columns := []string{"id", "product_id", "file_name", "url", "alt_text", "position"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   int
+		want    []domain.ProductImage
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, 1, "front.png", "https://test.back.com/products/1/front.png", "Front", 0).
+					AddRow(1, 1, "back.png", "https://test.back.com/products/1/back.png", "", 1)
+				mock.ExpectQuery("SELECT (.+) FROM product_images WHERE (.+) ORDER BY position, id").
+					WithArgs(1).WillReturnRows(rows)
+			},
+			input: 1,
+			want: []domain.ProductImage{
+				{Id: 2, ProductId: 1, FileName: "front.png", Url: "https://test.back.com/products/1/front.png", AltText: "Front", Position: 0},
+				{Id: 1, ProductId: 1, FileName: "back.png", Url: "https://test.back.com/products/1/back.png", AltText: "", Position: 1},
+			},
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM product_images").
+					WithArgs(2).WillReturnError(errors.New("select error"))
+			},
+			input:   2,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetImages(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetImagePositions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductImagePostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	type args struct {
+		productId int
+		imageIds  []int
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE product_images SET position").
+					WithArgs(0, 3, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE product_images SET position").
+					WithArgs(1, 2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE products SET image_url").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			input: args{1, []int{3, 2}},
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE product_images SET position").
+					WithArgs(0, 3, 1).WillReturnError(errors.New("update error"))
+				mock.ExpectRollback()
+			},
+			input:   args{1, []int{3, 2}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.SetImagePositions(tt.input.productId, tt.input.imageIds)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteImage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductImagePostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	type args struct {
+		productId int
+		imageId   int
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("DELETE FROM product_images").
+					WithArgs(2, 1).WillReturnRows(sqlmock.NewRows([]string{"file_name"}).AddRow("back.png"))
+				mock.ExpectExec("UPDATE products SET image_url").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			input: args{1, 2},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("DELETE FROM product_images").
+					WithArgs(5, 1).WillReturnRows(sqlmock.NewRows([]string{"file_name"}))
+				mock.ExpectRollback()
+			},
+			input:   args{1, 5},
+			wantErr: true,
+		},
+		{
+			name: "Commit failed",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("DELETE FROM product_images").
+					WithArgs(3, 1).WillReturnRows(sqlmock.NewRows([]string{"file_name"}).AddRow("front.png"))
+				mock.ExpectExec("UPDATE products SET image_url").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit().WillReturnError(errors.New("commit failed"))
+			},
+			input:   args{1, 3},
+			wantErr: true,
+		},
+	}
+
+	// The file of an image is only removed once its deletion is committed.
+	imageFile := "./data/products/1/front.png"
+	if err := os.MkdirAll(filepath.Dir(imageFile), 0755); err != nil {
+		t.Fatalf("Error creating image: %v", err)
+	}
+	if err := os.WriteFile(imageFile, []byte("image"), 0644); err != nil {
+		t.Fatalf("Error creating image: %v", err)
+	}
+	defer os.Remove(imageFile)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.DeleteImage(tt.input.productId, tt.input.imageId)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+	assert.FileExists(t, imageFile)
+}
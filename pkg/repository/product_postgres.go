@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"mime/multipart"
+	"sort"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
@@ -22,27 +23,167 @@ func newProductPostgres(db *sqlx.DB, s *storage.Storage) *ProductPostgres {
 	return &ProductPostgres{db, s}
 }
 
-func (r *ProductPostgres) GetAll(limit, offset int, search string) ([]domain.Product, error) {
-	var products []domain.Product
+func (r *ProductPostgres) GetAll(page domain.PageRequest, filter domain.ProductFilter) ([]domain.Product, domain.Pagination, error) {
+	rows, pagination, err := selectPage[productRow](r.db, productListing(0, filter), page)
+	return productsOf(rows), pagination, err
+}
+
+// AdminGetAll returns the products of the admin listing, whether available,
+// archived or out of stock.
+func (r *ProductPostgres) AdminGetAll(page domain.PageRequest, filter domain.AdminProductFilterParams) ([]domain.Product, domain.Pagination, error) {
+	rows, pagination, err := selectPage[productRow](r.db, adminProductListing(filter), page)
+	return productsOf(rows), pagination, err
+}
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE available=true", productsTable)
-	if search != "" {
-		query += fmt.Sprintf(" AND name ILIKE '%%%s%%'", search)
+// GetFacets counts the products of a listing by attribute value, price range,
+// category and availability. Each facet is counted without the filter on
+// that facet, so the other values remain selectable.
+func (r *ProductPostgres) GetFacets(categoryId int, filter domain.ProductFilter) (domain.Facets, error) {
+	var facets domain.Facets
+	var err error
+
+	facets.Attributes, err = r.attributeFacets(categoryId, filter)
+	if err != nil {
+		return facets, err
 	}
-	query += " ORDER BY id LIMIT $1 OFFSET $2"
+	facets.Price, err = r.priceFacet(categoryId, filter)
+	if err != nil {
+		return facets, err
+	}
+	facets.Categories, err = r.categoriesFacet(categoryId, filter)
+	if err != nil {
+		return facets, err
+	}
+	facets.Availability, err = r.availabilityFacet(categoryId, filter)
+	return facets, err
+}
 
-	err := r.db.Select(&products, query, limit, offset)
-	if err == sql.ErrNoRows {
-		return products, errors_handler.NoRows()
+func (r *ProductPostgres) attributeFacets(categoryId int, filter domain.ProductFilter) ([]domain.Facet, error) {
+	facets := make([]domain.Facet, 0)
+
+	c := newProductConditions(categoryId, filter, noFacet)
+	for _, name := range sortedAttributeNames(filter) {
+		c.add("a.name <> " + c.arg(name))
+	}
+	rows, err := r.countAttributeValues(c)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range sortedAttributeNames(filter) {
+		c := newProductConditions(categoryId, filter, attributeFacet(name))
+		c.add("a.name = " + c.arg(name))
+		filteredRows, err := r.countAttributeValues(c)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, filteredRows...)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Name < rows[j].Name
+	})
+	for _, row := range rows {
+		last := len(facets) - 1
+		if last < 0 || facets[last].Attribute != row.Name {
+			facets = append(facets, domain.Facet{Attribute: row.Name})
+			last++
+		}
+		facets[last].Values = append(facets[last].Values, domain.FacetValue{Value: row.Value, Count: row.Count})
+	}
+
+	return facets, nil
+}
+
+type attributeValueCount struct {
+	Name  string `db:"name"`
+	Value string `db:"value"`
+	Count int    `db:"count"`
+}
+
+func (r *ProductPostgres) countAttributeValues(c *queryConditions) ([]attributeValueCount, error) {
+	var counts []attributeValueCount
+
+	query := fmt.Sprintf(`SELECT a.name, pa.value, COUNT(DISTINCT p.id) AS count
+		FROM %s p
+		INNER JOIN %s pa ON pa.product_id = p.id
+		INNER JOIN %s a ON a.id = pa.attribute_id AND a.category_id = p.category_id
+		WHERE %s
+		GROUP BY a.name, pa.value
+		ORDER BY a.name, pa.value`, productsTable, productAttributesTable, attributesTable, c.where())
+
+	err := r.db.Select(&counts, query, c.args...)
+	return counts, err
+}
+
+// priceFacet counts the products in each price bucket. Empty buckets are
+// left out.
+func (r *ProductPostgres) priceFacet(categoryId int, filter domain.ProductFilter) ([]domain.PriceBucket, error) {
+	buckets := make([]domain.PriceBucket, 0)
+
+	c := newProductConditions(categoryId, filter, priceFacet)
+	query := fmt.Sprintf(`SELECT width_bucket(p.price, %s::numeric[]) AS bucket, COUNT(*) AS count
+		FROM %s p
+		WHERE %s
+		GROUP BY bucket
+		ORDER BY bucket`, c.arg(pq.Array(priceBucketEdges)), productsTable, c.where())
+
+	var counts []struct {
+		Bucket int `db:"bucket"`
+		Count  int `db:"count"`
+	}
+	if err := r.db.Select(&counts, query, c.args...); err != nil {
+		return nil, err
+	}
+
+	for _, count := range counts {
+		bucket := domain.PriceBucket{Count: count.Count}
+		if count.Bucket > 0 {
+			bucket.Min = priceBucketEdges[count.Bucket-1]
+		}
+		if count.Bucket < len(priceBucketEdges) {
+			bucket.Max = &priceBucketEdges[count.Bucket]
+		}
+		buckets = append(buckets, bucket)
 	}
 
-	return products, err
+	return buckets, nil
+}
+
+func (r *ProductPostgres) categoriesFacet(categoryId int, filter domain.ProductFilter) ([]domain.CategoryCount, error) {
+	categories := make([]domain.CategoryCount, 0)
+
+	c := newProductConditions(categoryId, filter, categoriesFacet)
+	query := fmt.Sprintf(`SELECT c.id, c.name, COUNT(DISTINCT p.id) AS count
+		FROM %s p
+		INNER JOIN %s c ON c.id = p.category_id
+			OR c.id IN (SELECT pc.category_id FROM %s pc WHERE pc.product_id = p.id)
+		WHERE %s
+		GROUP BY c.id, c.name
+		ORDER BY c.name`, productsTable, categoriesTables, productCategoriesTable, c.where())
+
+	err := r.db.Select(&categories, query, c.args...)
+	return categories, err
+}
+
+func (r *ProductPostgres) availabilityFacet(categoryId int, filter domain.ProductFilter) (domain.AvailabilityCount, error) {
+	var availability domain.AvailabilityCount
+
+	c := newProductConditions(categoryId, filter, stockFacet)
+	query := fmt.Sprintf(`SELECT 
+		COUNT(*) FILTER (WHERE %s) AS in_stock, 
+		COUNT(*) FILTER (WHERE NOT %s) AS out_of_stock
+		FROM %s p
+		WHERE %s`, inStockExpression, inStockExpression, productsTable, c.where())
+
+	err := r.db.Get(&availability, query, c.args...)
+	return availability, err
 }
 
 func (r *ProductPostgres) GetById(id int) (domain.Product, error) {
 	var product domain.Product
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1 AND available=true", productsTable)
+	query := fmt.Sprintf("SELECT %s FROM %s p WHERE p.id=$1 AND p.available=true AND p.archived_at IS NULL", productColumns, productsTable)
 
 	err := r.db.Get(&product, query, id)
 	if err == sql.ErrNoRows {
@@ -52,6 +193,83 @@ func (r *ProductPostgres) GetById(id int) (domain.Product, error) {
 	return product, err
 }
 
+// AdminGetById returns a product whether available or archived.
+func (r *ProductPostgres) AdminGetById(id int) (domain.Product, error) {
+	var product domain.Product
+
+	query := fmt.Sprintf("SELECT %s FROM %s p WHERE p.id=$1", productColumns, productsTable)
+
+	err := r.db.Get(&product, query, id)
+	if err == sql.ErrNoRows {
+		return product, errors_handler.NoRows()
+	}
+
+	return product, err
+}
+
+func (r *ProductPostgres) GetIdByName(categoryId int, name string) (int, error) {
+	var id int
+
+	query := fmt.Sprintf("SELECT id FROM %s WHERE category_id=$1 AND name=$2 ORDER BY id LIMIT 1", productsTable)
+
+	err := r.db.Get(&id, query, categoryId, name)
+	if err == sql.ErrNoRows {
+		return id, errors_handler.NoRows()
+	}
+
+	return id, err
+}
+
+// GetCategoryIds returns the categories a product is listed in besides its
+// primary category.
+func (r *ProductPostgres) GetCategoryIds(productId int) ([]int, error) {
+	ids := make([]int, 0)
+
+	query := fmt.Sprintf("SELECT category_id FROM %s WHERE product_id=$1 ORDER BY category_id", productCategoriesTable)
+
+	err := r.db.Select(&ids, query, productId)
+	return ids, err
+}
+
+// SetCategories replaces the categories a product is listed in besides its
+// primary category, which is skipped if listed.
+func (r *ProductPostgres) SetCategories(productId int, categoryIds []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var primaryId int
+	query := fmt.Sprintf("SELECT category_id FROM %s WHERE id=$1 FOR UPDATE", productsTable)
+	if err := tx.QueryRow(query, productId).Scan(&primaryId); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE product_id=$1", productCategoriesTable)
+	if _, err := tx.Exec(deleteQuery, productId); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (product_id, category_id) VALUES ($1, $2)", productCategoriesTable)
+	for _, categoryId := range categoryIds {
+		if categoryId == primaryId {
+			continue
+		}
+		if _, err := tx.Exec(insertQuery, productId, categoryId); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+				return errors_handler.ForeignKeyViolation()
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (r *ProductPostgres) GetFilePath(productId int, fileName string) string {
 	return r.s.Product.GetFilePath(productId, fileName)
 }
@@ -72,16 +290,13 @@ func (r *ProductPostgres) CreateProduct(input domain.CreateProductInput, file mu
 		available,
 		price,
 		undiscounted_price,
-		stock
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`, productsTable)
+		stock,
+		sku
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')) RETURNING id`, productsTable)
 
-	row := tx.QueryRow(query, input.CategoryId, input.Name, input.Description, "", input.Available, input.Price, input.UndiscountedPrice, input.Stock)
+	row := tx.QueryRow(query, input.CategoryId, input.Name, input.Description, "", input.Available, input.Price, input.UndiscountedPrice, input.Stock, input.Sku)
 	if err := row.Scan(&id); err != nil {
-		pqErr, ok := err.(*pq.Error)
-		if ok && pqErr.Code.Name() == "foreign_key_violation" {
-			return 0, errors_handler.ForeignKeyViolation()
-		}
-		return 0, err
+		return 0, productError(err)
 	}
 
 	var url string
@@ -97,7 +312,20 @@ func (r *ProductPostgres) CreateProduct(input domain.CreateProductInput, file mu
 	if err != nil {
 		return 0, err
 	}
-	return id, tx.Commit()
+
+	var replaced string
+	if url != "" {
+		replaced, err = replacePrimaryImage(tx, id, input.ImgFile.Filename, url)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	removeProductImage(r.s, id, replaced)
+	return id, nil
 }
 
 func (r *ProductPostgres) UpdateProduct(productId int, input domain.UpdateProductInput, file multipart.File) error {
@@ -129,6 +357,12 @@ func (r *ProductPostgres) UpdateProduct(productId int, input domain.UpdateProduc
 		argId++
 	}
 
+	if input.Sku != nil {
+		setValues = append(setValues, fmt.Sprintf("sku=NULLIF($%d, '')", argId))
+		args = append(args, *input.Sku)
+		argId++
+	}
+
 	if input.Stock != nil {
 		setValues = append(setValues, fmt.Sprintf("stock=$%d", argId))
 		args = append(args, *input.Stock)
@@ -153,8 +387,9 @@ func (r *ProductPostgres) UpdateProduct(productId int, input domain.UpdateProduc
 		argId++
 	}
 
+	var url string
 	if input.ImgFile != nil && file != nil {
-		url, err := r.s.UploadProductImage(productId, input.ImgFile, file)
+		url, err = r.s.UploadProductImage(productId, input.ImgFile, file)
 
 		if err != nil {
 			return err
@@ -177,8 +412,182 @@ func (r *ProductPostgres) UpdateProduct(productId int, input domain.UpdateProduc
 			return errors_handler.NoRows()
 		}
 
+		return productError(err)
+	}
+
+	var replaced string
+	if url != "" {
+		replaced, err = replacePrimaryImage(tx, productId, input.ImgFile.Filename, url)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
 		return err
 	}
+	removeProductImage(r.s, productId, replaced)
+	return nil
+}
 
-	return tx.Commit()
+func (r *ProductPostgres) ArchiveProduct(id int) error {
+	return setArchived(r.db, productsTable, id, true)
+}
+
+func (r *ProductPostgres) RestoreProduct(id int) error {
+	return setArchived(r.db, productsTable, id, false)
+}
+
+// DeleteProduct deletes a product that has never been ordered, together with
+// its media.
+func (r *ProductPostgres) DeleteProduct(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 FOR UPDATE", productsTable)
+	if err := tx.QueryRow(query, id).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	variantIds, err := prepareProductsDelete(tx, []int{id})
+	if err != nil {
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE id=$1", productsTable)
+	if _, err := tx.Exec(deleteQuery, id); err != nil {
+		return deleteError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return deleteError(err)
+	}
+	removeProductsMedia(r.s, []int{id}, variantIds)
+	return nil
+}
+
+// BulkUpdate applies the operations of the input to the selected products in
+// a transaction, with the products locked. The changes are only saved when
+// the input is not a preview and every change is valid. Listed ids that do
+// not exist fail the update, as do selections of more than
+// domain.MaxBulkProducts products.
+func (r *ProductPostgres) BulkUpdate(input domain.BulkUpdateProductsInput) (domain.BulkUpdateResult, error) {
+	result := domain.BulkUpdateResult{Preview: input.Preview, Changes: make([]domain.ProductChange, 0)}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	c := &queryConditions{}
+	if input.Filter != nil {
+		c = adminProductListing(*input.Filter).where
+	} else {
+		c.add("p.id = ANY(" + c.arg(pq.Array(input.ProductIds)) + ")")
+	}
+	query := fmt.Sprintf(`SELECT p.id, p.name, p.category_id, p.price, p.undiscounted_price, p.stock, p.available
+		FROM %s p WHERE %s ORDER BY p.id LIMIT %d FOR UPDATE OF p`, productsTable, c.where(), domain.MaxBulkProducts+1)
+
+	rows, err := tx.Query(query, c.args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	productIds := make([]int, 0)
+	for rows.Next() {
+		var change domain.ProductChange
+		before := &change.Before
+		if err := rows.Scan(&change.Id, &change.Name, &before.CategoryId, &before.Price, &before.UndiscountedPrice, &before.Stock, &before.Available); err != nil {
+			return result, err
+		}
+		result.Matched++
+
+		change.After = input.Operations.Apply(change.Before)
+		if change.After == change.Before {
+			continue
+		}
+		if err := change.After.Validate(); err != nil {
+			change.Error = err.Error()
+			result.Failed++
+		}
+		result.Changes = append(result.Changes, change)
+		productIds = append(productIds, change.Id)
+	}
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+	rows.Close()
+
+	if result.Matched > domain.MaxBulkProducts {
+		return result, errors_handler.ConstrainViolation("products")
+	}
+	if input.Filter == nil && result.Matched != len(input.ProductIds) {
+		return result, errors_handler.NoRows()
+	}
+
+	if categoryId := input.Operations.CategoryId; categoryId != nil && len(productIds) > 0 {
+		var id int
+		categoryQuery := fmt.Sprintf("SELECT id FROM %s WHERE id=$1", categoriesTables)
+		if err := tx.QueryRow(categoryQuery, *categoryId).Scan(&id); err != nil {
+			if err == sql.ErrNoRows {
+				return result, errors_handler.ForeignKeyViolation()
+			}
+			return result, err
+		}
+	}
+
+	result.Changed = len(result.Changes) - result.Failed
+	if input.Preview || result.Failed > 0 || len(productIds) == 0 {
+		return result, nil
+	}
+
+	updateQuery := fmt.Sprintf(`UPDATE %s SET
+		category_id=$1,
+		price=$2,
+		undiscounted_price=$3,
+		stock=$4,
+		available=$5
+		WHERE id=$6`, productsTable)
+	for _, change := range result.Changes {
+		after := change.After
+		if _, err := tx.Exec(updateQuery, after.CategoryId, after.Price, after.UndiscountedPrice, after.Stock, after.Available, change.Id); err != nil {
+			return result, productError(err)
+		}
+	}
+
+	// The new primary category of a moved product is no longer one of its
+	// other categories.
+	if categoryId := input.Operations.CategoryId; categoryId != nil {
+		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE product_id = ANY($1) AND category_id=$2", productCategoriesTable)
+		if _, err := tx.Exec(deleteQuery, pq.Array(productIds), *categoryId); err != nil {
+			return result, err
+		}
+	}
+
+	return result, tx.Commit()
+}
+
+// productError translates the constraint violations of the products table.
+func productError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		return errors_handler.AlreadyExists("product")
+	case "foreign_key_violation":
+		return errors_handler.ForeignKeyViolation()
+	case "check_violation":
+		return errors_handler.ConstrainViolation("price")
+	}
+	return err
 }
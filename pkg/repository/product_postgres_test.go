@@ -3,14 +3,16 @@ package repository
 import (
 	"database/sql/driver"
 	"errors"
-	"math"
 	"mime/multipart"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
 	"github.com/renlin-code/mock-shop-api/pkg/storage"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,50 +32,145 @@ func TestGetAllProducts(t *testing.T) {
 	r := newProductPostgres(sqlx.NewDb(db, "sqlmock"), s)
 
 	type args struct {
-		limit  int
-		offset int
+		page   domain.PageRequest
+		filter domain.ProductFilter
 	}
 
+	columns := []string{"id", "category_id", "name", "description", "price", "undiscounted_price", "stock", "available", "image_url", "sort_key"}
+
 	tests := []struct {
-		name    string
-		mock    func()
-		input   args
-		want    []domain.Product
-		wantErr bool
+		name           string
+		mock           func()
+		input          args
+		want           []domain.Product
+		wantPagination domain.Pagination
+		wantErr        bool
 	}{
 		{
 			name: "Ok",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "category_id", "name", "description", "price", "undiscounted_price", "stock", "available", "image_url"}).
-					AddRow(1, 1, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/data/products/1/img1.png").
-					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "https://test.back.com/data/products/2/img1.png").
-					AddRow(3, 2, "product name 3", "product description 3", 108.49, 126.99, 27, true, "https://test.back.com/data/products/3/img1.png")
-				mock.ExpectQuery("SELECT (.+) FROM products").
-					WithArgs(3, 0).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p WHERE p.available=true AND p.archived_at IS NULL").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				sale := `{"id": 5, "name": "Summer sale", "percent": 20, "regular_price": 1.24, "starts_at": "2024-06-01T00:00:00Z", "ends_at": "2024-07-01T00:00:00Z"}`
+				rows := sqlmock.NewRows(append(columns, "active_sale")).
+					AddRow(1, 1, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/data/products/1/img1.png", "", sale).
+					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "https://test.back.com/data/products/2/img1.png", "", nil).
+					AddRow(3, 2, "product name 3", "product description 3", 108.49, 126.99, 27, true, "https://test.back.com/data/products/3/img1.png", "", nil)
+				mock.ExpectQuery("SELECT p.id, (.+), p.created_at, p.rating_average, p.rating_count, p.archived_at, \\(SELECT json_build_object\\((.+)\\) FROM price_schedules s WHERE s.id = p.sale_id (.+)\\) AS active_sale, '' AS sort_key FROM products p WHERE p.available=true AND p.archived_at IS NULL ORDER BY p.id LIMIT (.+) OFFSET").
+					WithArgs(4, 0).WillReturnRows(rows)
 			},
-			input: args{3, 0},
+			input: args{domain.PageRequest{Limit: 3}, domain.ProductFilter{}},
 			want: []domain.Product{
-				{Id: 1, CategoryId: 1, Name: "product name 1", Description: "product description 1", Price: 0.99, UndiscountedPrice: 1.29, Stock: 12, Available: true, ImageUrl: "https://test.back.com/data/products/1/img1.png"},
-				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 1.99, UndiscountedPrice: 1.99, Stock: 2, Available: true, ImageUrl: "https://test.back.com/data/products/2/img1.png"},
-				{Id: 3, CategoryId: 2, Name: "product name 3", Description: "product description 3", Price: 108.49, UndiscountedPrice: 126.99, Stock: 27, Available: true, ImageUrl: "https://test.back.com/data/products/3/img1.png"},
+				{Id: 1, CategoryId: 1, Name: "product name 1", Description: "product description 1", Price: 99, UndiscountedPrice: 129, Stock: 12, Available: true, ImageUrl: "https://test.back.com/data/products/1/img1.png",
+					ActiveSale: &domain.ActiveSale{Id: 5, Name: "Summer sale", Percent: 20, RegularPrice: 124,
+						StartsAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), EndsAt: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)}},
+				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 199, UndiscountedPrice: 199, Stock: 2, Available: true, ImageUrl: "https://test.back.com/data/products/2/img1.png"},
+				{Id: 3, CategoryId: 2, Name: "product name 3", Description: "product description 3", Price: 10849, UndiscountedPrice: 12699, Stock: 27, Available: true, ImageUrl: "https://test.back.com/data/products/3/img1.png"},
 			},
+			wantPagination: domain.Pagination{Total: 3, Page: 1, PageSize: 3},
 		},
 		{
-			name: "Ok without pagination",
+			name: "Ok with next page",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "category_id", "name", "description", "price", "undiscounted_price", "stock", "available", "image_url"}).
-					AddRow(1, 1, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/data/products/1/img1.png").
-					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "https://test.back.com/data/products/2/img1.png").
-					AddRow(3, 2, "product name 3", "product description 3", 108.49, 126.99, 27, true, "https://test.back.com/data/products/3/img1.png")
-				mock.ExpectQuery("SELECT (.+) FROM products").
-					WithArgs(0, 0).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+				rows := sqlmock.NewRows(columns).
+					AddRow(3, 2, "product name 3", "product description 3", 108.49, 126.99, 27, true, "https://test.back.com/data/products/3/img1.png", "").
+					AddRow(4, 2, "product name 4", "product description 4", 9.99, 9.99, 1, true, "https://test.back.com/data/products/4/img1.png", "").
+					AddRow(5, 2, "product name 5", "product description 5", 9.99, 9.99, 1, true, "https://test.back.com/data/products/5/img1.png", "")
+				mock.ExpectQuery("SELECT (.+) FROM products p WHERE p.available=true AND p.archived_at IS NULL ORDER BY p.id LIMIT (.+) OFFSET").
+					WithArgs(3, 2).WillReturnRows(rows)
+			},
+			input: args{domain.PageRequest{Limit: 2, Offset: 2}, domain.ProductFilter{}},
+			want: []domain.Product{
+				{Id: 3, CategoryId: 2, Name: "product name 3", Description: "product description 3", Price: 10849, UndiscountedPrice: 12699, Stock: 27, Available: true, ImageUrl: "https://test.back.com/data/products/3/img1.png"},
+				{Id: 4, CategoryId: 2, Name: "product name 4", Description: "product description 4", Price: 999, UndiscountedPrice: 999, Stock: 1, Available: true, ImageUrl: "https://test.back.com/data/products/4/img1.png"},
+			},
+			wantPagination: domain.Pagination{
+				Total:      5,
+				Page:       2,
+				PageSize:   2,
+				NextCursor: domain.Cursor{Id: 4}.Encode(),
+				PrevCursor: domain.Cursor{Id: 3, Before: true}.Encode(),
+			},
+		},
+		{
+			name: "Ok with cursor",
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p WHERE p.available=true AND p.archived_at IS NULL").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "https://test.back.com/data/products/2/img1.png", "1.99")
+				mock.ExpectQuery("SELECT (.+), \\(p.price\\)::text AS sort_key FROM products p WHERE p.available=true AND p.archived_at IS NULL AND \\(p.price, p.id\\) < \\(\\$1, \\$2\\) ORDER BY p.price DESC, p.id DESC LIMIT \\$3$").
+					WithArgs("108.49", 3, 3).WillReturnRows(rows)
+			},
+			input: args{
+				domain.PageRequest{Limit: 2, Cursor: &domain.Cursor{Sort: domain.SortPriceDesc, Key: "108.49", Id: 3}},
+				domain.ProductFilter{ProductFilterParams: domain.ProductFilterParams{Sort: domain.SortPriceDesc}},
+			},
+			want: []domain.Product{
+				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 199, UndiscountedPrice: 199, Stock: 2, Available: true, ImageUrl: "https://test.back.com/data/products/2/img1.png"},
+			},
+			wantPagination: domain.Pagination{
+				Total:      3,
+				PageSize:   2,
+				PrevCursor: domain.Cursor{Sort: domain.SortPriceDesc, Key: "1.99", Id: 2, Before: true}.Encode(),
+			},
+		},
+		{
+			name: "Ok with filter",
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p WHERE \\(p.search_vector @@ (.+) OR \\$1 <% p.name\\) AND p.available=true AND p.archived_at IS NULL AND p.price<=(.+) AND (.+) AND \\(p.category_id = ANY(.+) OR EXISTS (.+)\\) AND EXISTS (.+)").
+					WithArgs("name_1", domain.Money(500), pq.Array([]int{1, 2}), "material", pq.Array([]string{"cotton", "wool"})).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				rows := sqlmock.NewRows(append(columns, "highlight")).
+					AddRow(1, 1, "product name 1", "product description 1", 0.99, 1.29, 12, true, "https://test.back.com/data/products/1/img1.png", "0.99", "<mark>product</mark> name 1")
+				mock.ExpectQuery("SELECT (.+) AS highlight, (.+) FROM products p WHERE (.+) AND p.available=true AND p.archived_at IS NULL AND p.price<=(.+) AND (.+) AND \\(p.category_id = ANY(.+) OR EXISTS (.+)\\) AND EXISTS (.+) ORDER BY p.price DESC, p.id DESC LIMIT (.+) OFFSET").
+					WithArgs("name_1", domain.Money(500), pq.Array([]int{1, 2}), "material", pq.Array([]string{"cotton", "wool"}), 4, 0).WillReturnRows(rows)
+			},
+			input: args{domain.PageRequest{Limit: 3}, domain.ProductFilter{
+				ProductFilterParams: domain.ProductFilterParams{
+					MaxPrice:    moneyPointer(500),
+					InStock:     true,
+					CategoryIds: []int{1, 2},
+					Sort:        domain.SortPriceDesc,
+				},
+				Search:     "name_1",
+				Attributes: map[string][]string{"material": {"cotton", "wool"}},
+			}},
+			want: []domain.Product{
+				{Id: 1, CategoryId: 1, Name: "product name 1", Description: "product description 1", Price: 99, UndiscountedPrice: 129, Stock: 12, Available: true, ImageUrl: "https://test.back.com/data/products/1/img1.png", Highlight: "<mark>product</mark> name 1"},
+			},
+			wantPagination: domain.Pagination{Total: 1, Page: 1, PageSize: 3},
+		},
+		{
+			name: "Ok with search by relevance",
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p WHERE \\(p.search_vector @@ websearch_to_tsquery\\('english', \\$1\\) OR \\$1 <% p.name\\) AND p.available=true AND p.archived_at IS NULL").
+					WithArgs("prodct").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				rows := sqlmock.NewRows(append(columns, "highlight")).
+					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "https://test.back.com/data/products/2/img1.png", "0.8", "product name 2. product description 2")
+				mock.ExpectQuery("SELECT (.+), \\(ts_rank\\(p.search_vector, (.+)\\) \\+ word_similarity\\(\\$1, p.name\\)\\)::text AS sort_key FROM products p WHERE (.+) ORDER BY ts_rank(.+) DESC, p.id DESC LIMIT \\$2 OFFSET \\$3").
+					WithArgs("prodct", 2, 0).WillReturnRows(rows)
 			},
-			input: args{0, 0},
+			input: args{domain.PageRequest{Limit: 1}, domain.ProductFilter{Search: "prodct"}},
 			want: []domain.Product{
-				{Id: 1, CategoryId: 1, Name: "product name 1", Description: "product description 1", Price: 0.99, UndiscountedPrice: 1.29, Stock: 12, Available: true, ImageUrl: "https://test.back.com/data/products/1/img1.png"},
-				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 1.99, UndiscountedPrice: 1.99, Stock: 2, Available: true, ImageUrl: "https://test.back.com/data/products/2/img1.png"},
-				{Id: 3, CategoryId: 2, Name: "product name 3", Description: "product description 3", Price: 108.49, UndiscountedPrice: 126.99, Stock: 27, Available: true, ImageUrl: "https://test.back.com/data/products/3/img1.png"},
+				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 199, UndiscountedPrice: 199, Stock: 2, Available: true, ImageUrl: "https://test.back.com/data/products/2/img1.png", Highlight: "product name 2. product description 2"},
 			},
+			wantPagination: domain.Pagination{Total: 2, Page: 1, PageSize: 1},
+		},
+		{
+			name: "Cursor of another sort",
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+			},
+			input: args{
+				domain.PageRequest{Limit: 2, Cursor: &domain.Cursor{Sort: domain.SortPriceDesc, Key: "108.49", Id: 3}},
+				domain.ProductFilter{ProductFilterParams: domain.ProductFilterParams{Sort: domain.SortNameAsc}},
+			},
+			wantErr: true,
 		},
 	}
 
@@ -81,12 +178,13 @@ func TestGetAllProducts(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := r.GetAll(tt.input.limit, tt.input.offset, "")
+			got, pagination, err := r.GetAll(tt.input.page, tt.input.filter)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantPagination, pagination)
 			}
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
@@ -132,8 +230,8 @@ func TestGetProductById(t *testing.T) {
 				CategoryId:        1,
 				Name:              "product name 1",
 				Description:       "product description 1",
-				Price:             0.99,
-				UndiscountedPrice: 1.29,
+				Price:             99,
+				UndiscountedPrice: 129,
 				Stock:             12,
 				Available:         true,
 				ImageUrl:          "https://test.back.com/data/products/1/img1.png",
@@ -167,6 +265,69 @@ func TestGetProductById(t *testing.T) {
 	}
 }
 
+func TestGetProductIdByName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	type args struct {
+		categoryId int
+		name       string
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(7)
+				mock.ExpectQuery("SELECT id FROM products WHERE category_id=(.+) AND name").
+					WithArgs(2, "Black mug").WillReturnRows(rows)
+			},
+			input: args{2, "Black mug"},
+			want:  7,
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"})
+				mock.ExpectQuery("SELECT id FROM products WHERE category_id=(.+) AND name").
+					WithArgs(2, "White mug").WillReturnRows(rows)
+			},
+			input:   args{2, "White mug"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetIdByName(tt.input.categoryId, tt.input.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestCreateProduct(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -215,11 +376,16 @@ func TestCreateProduct(t *testing.T) {
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery("INSERT INTO products").
-					WithArgs(1, "Product name", "Product description", "", true, roundPrice(0.99), roundPrice(1.29), 12).WillReturnRows(rows)
+					WithArgs(1, "Product name", "Product description", "", true, domain.Money(99), domain.Money(129), 12, "").WillReturnRows(rows)
 
 				mock.ExpectExec("UPDATE products").
 					WithArgs("https://test.back.com/data/products/1/img1.png", 1).WillReturnResult(driver.ResultNoRows)
 
+				mock.ExpectQuery("SELECT id, file_name FROM product_images").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "file_name"}))
+				mock.ExpectExec("INSERT INTO product_images").
+					WithArgs(1, "img1.png", "https://test.back.com/data/products/1/img1.png").WillReturnResult(sqlmock.NewResult(1, 1))
+
 				mock.ExpectCommit()
 			},
 			input: args{
@@ -228,8 +394,8 @@ func TestCreateProduct(t *testing.T) {
 					CategoryId:        1,
 					Name:              "Product name",
 					Description:       "Product description",
-					Price:             roundPrice(0.99),
-					UndiscountedPrice: roundPrice(1.29),
+					Price:             domain.Money(99),
+					UndiscountedPrice: domain.Money(129),
 					Stock:             12,
 					Available:         true,
 					ImgFile:           testFileHeader,
@@ -242,7 +408,7 @@ func TestCreateProduct(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 				mock.ExpectQuery("INSERT INTO products").
-					WithArgs(0, "Product name", "Product description", "", true, roundPrice(0.99), roundPrice(1.29), 12).WillReturnError(errors.New("category already exists"))
+					WithArgs(0, "Product name", "Product description", "", true, domain.Money(99), domain.Money(129), 12, "").WillReturnError(errors.New("category already exists"))
 				mock.ExpectRollback()
 			},
 			input: args{
@@ -251,8 +417,8 @@ func TestCreateProduct(t *testing.T) {
 					CategoryId:        0,
 					Name:              "Product name",
 					Description:       "Product description",
-					Price:             roundPrice(0.99),
-					UndiscountedPrice: roundPrice(1.29),
+					Price:             domain.Money(99),
+					UndiscountedPrice: domain.Money(129),
 					Stock:             12,
 					Available:         true,
 					ImgFile:           testFileHeader,
@@ -326,7 +492,11 @@ func TestUpdateProduct(t *testing.T) {
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery("UPDATE products SET (.+)").
-					WithArgs(1, "new name", "new description", 12, roundPrice(0.99), roundPrice(1.29), true, "https://test.back.com/data/products/1/img1.png", 1).WillReturnRows(rows)
+					WithArgs(1, "new name", "new description", 12, domain.Money(99), domain.Money(129), true, "https://test.back.com/data/products/1/img1.png", 1).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT id, file_name FROM product_images").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "file_name"}).AddRow(1, "img1.png"))
+				mock.ExpectExec("UPDATE product_images").
+					WithArgs("img1.png", "https://test.back.com/data/products/1/img1.png", 1).WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectCommit()
 			},
 			input: args{
@@ -336,8 +506,8 @@ func TestUpdateProduct(t *testing.T) {
 					Name:              stringPointer("new name"),
 					Description:       stringPointer("new description"),
 					Stock:             intPointer(12),
-					Price:             float32Pointer(roundPrice(0.99)),
-					UndiscountedPrice: float32Pointer(roundPrice(1.29)),
+					Price:             moneyPointer(99),
+					UndiscountedPrice: moneyPointer(129),
 					Available:         boolPointer(true),
 					ImgFile:           testFileHeader,
 				},
@@ -351,7 +521,11 @@ func TestUpdateProduct(t *testing.T) {
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery("UPDATE products SET (.+)").
-					WithArgs(1, "new name", "new description", 12, roundPrice(0.99), roundPrice(1.29), "https://test.back.com/data/products/1/img1.png", 1).WillReturnRows(rows)
+					WithArgs(1, "new name", "new description", 12, domain.Money(99), domain.Money(129), "https://test.back.com/data/products/1/img1.png", 1).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT id, file_name FROM product_images").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "file_name"}).AddRow(1, "img1.png"))
+				mock.ExpectExec("UPDATE product_images").
+					WithArgs("img1.png", "https://test.back.com/data/products/1/img1.png", 1).WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectCommit()
 			},
 			input: args{
@@ -361,8 +535,8 @@ func TestUpdateProduct(t *testing.T) {
 					Name:              stringPointer("new name"),
 					Description:       stringPointer("new description"),
 					Stock:             intPointer(12),
-					Price:             float32Pointer(roundPrice(0.99)),
-					UndiscountedPrice: float32Pointer(roundPrice(1.29)),
+					Price:             moneyPointer(99),
+					UndiscountedPrice: moneyPointer(129),
 					ImgFile:           testFileHeader,
 				},
 				file: testFile,
@@ -375,7 +549,11 @@ func TestUpdateProduct(t *testing.T) {
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery("UPDATE products SET (.+)").
-					WithArgs(1, "new name", 12, roundPrice(0.99), roundPrice(1.29), "https://test.back.com/data/products/1/img1.png", 1).WillReturnRows(rows)
+					WithArgs(1, "new name", 12, domain.Money(99), domain.Money(129), "https://test.back.com/data/products/1/img1.png", 1).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT id, file_name FROM product_images").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "file_name"}).AddRow(1, "img1.png"))
+				mock.ExpectExec("UPDATE product_images").
+					WithArgs("img1.png", "https://test.back.com/data/products/1/img1.png", 1).WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectCommit()
 			},
 			input: args{
@@ -384,8 +562,8 @@ func TestUpdateProduct(t *testing.T) {
 					CategoryId:        intPointer(1),
 					Name:              stringPointer("new name"),
 					Stock:             intPointer(12),
-					Price:             float32Pointer(roundPrice(0.99)),
-					UndiscountedPrice: float32Pointer(roundPrice(1.29)),
+					Price:             moneyPointer(99),
+					UndiscountedPrice: moneyPointer(129),
 					ImgFile:           testFileHeader,
 				},
 				file: testFile,
@@ -398,7 +576,7 @@ func TestUpdateProduct(t *testing.T) {
 				mock.ExpectBegin()
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery("UPDATE products SET (.+)").
-					WithArgs(1, "new name", 12, roundPrice(0.99), roundPrice(1.29), true, 1).WillReturnRows(rows)
+					WithArgs(1, "new name", 12, domain.Money(99), domain.Money(129), true, 1).WillReturnRows(rows)
 				mock.ExpectCommit()
 			},
 			input: args{
@@ -407,8 +585,8 @@ func TestUpdateProduct(t *testing.T) {
 					CategoryId:        intPointer(1),
 					Name:              stringPointer("new name"),
 					Stock:             intPointer(12),
-					Price:             float32Pointer(roundPrice(0.99)),
-					UndiscountedPrice: float32Pointer(roundPrice(1.29)),
+					Price:             moneyPointer(99),
+					UndiscountedPrice: moneyPointer(129),
 					Available:         boolPointer(true),
 				},
 			},
@@ -431,14 +609,715 @@ func TestUpdateProduct(t *testing.T) {
 	}
 }
 
-func roundPrice(price float32) float32 {
-	return float32(math.Round(float64(price)*100) / 100)
+func TestGetFacets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	type args struct {
+		categoryId int
+		filter     domain.ProductFilter
+	}
+
+	edges := pq.Array(priceBucketEdges)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		want    domain.Facets
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"name", "value", "count"}).
+					AddRow("brand", "Acme", 2).
+					AddRow("material", "cotton", 3).
+					AddRow("material", "wool", 1)
+				mock.ExpectQuery("SELECT a.name, pa.value, COUNT(.+) WHERE p.available=true AND p.archived_at IS NULL AND \\(p.category_id = \\$1 OR EXISTS (.+) GROUP BY").
+					WithArgs(1).WillReturnRows(rows)
+				rows = sqlmock.NewRows([]string{"bucket", "count"}).
+					AddRow(0, 1).
+					AddRow(7, 3)
+				mock.ExpectQuery("SELECT width_bucket(.+) WHERE p.available=true AND p.archived_at IS NULL AND \\(p.category_id = \\$1 OR EXISTS (.+) GROUP BY bucket").
+					WithArgs(1, edges).WillReturnRows(rows)
+				rows = sqlmock.NewRows([]string{"id", "name", "count"}).
+					AddRow(1, "Clothes", 4)
+				mock.ExpectQuery("SELECT c.id, c.name, COUNT(.+) WHERE p.available=true AND p.archived_at IS NULL AND \\(p.category_id = \\$1 OR EXISTS (.+) GROUP BY").
+					WithArgs(1).WillReturnRows(rows)
+				rows = sqlmock.NewRows([]string{"in_stock", "out_of_stock"}).
+					AddRow(3, 1)
+				mock.ExpectQuery("SELECT (.+) AS in_stock, (.+) AS out_of_stock FROM products p WHERE p.available=true AND p.archived_at IS NULL AND \\(p.category_id = \\$1 OR EXISTS").
+					WithArgs(1).WillReturnRows(rows)
+			},
+			input: args{1, domain.ProductFilter{}},
+			want: domain.Facets{
+				Attributes: []domain.Facet{
+					{Attribute: "brand", Values: []domain.FacetValue{{Value: "Acme", Count: 2}}},
+					{Attribute: "material", Values: []domain.FacetValue{{Value: "cotton", Count: 3}, {Value: "wool", Count: 1}}},
+				},
+				Price: []domain.PriceBucket{
+					{Min: 0, Max: moneyPointer(1000), Count: 1},
+					{Min: 100000, Count: 3},
+				},
+				Categories:   []domain.CategoryCount{{Id: 1, Name: "Clothes", Count: 4}},
+				Availability: domain.AvailabilityCount{InStock: 3, OutOfStock: 1},
+			},
+		},
+		{
+			name: "Ok with filter",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"name", "value", "count"}).
+					AddRow("brand", "Acme", 1)
+				mock.ExpectQuery("SELECT a.name, pa.value, COUNT(.+) AND p.price>=(.+) AND EXISTS (.+) AND a.name <> (.+) GROUP BY").
+					WithArgs(domain.Money(2000), "material", pq.Array([]string{"wool"}), "material").WillReturnRows(rows)
+				rows = sqlmock.NewRows([]string{"name", "value", "count"}).
+					AddRow("material", "cotton", 3).
+					AddRow("material", "wool", 1)
+				mock.ExpectQuery("SELECT a.name, pa.value, COUNT(.+) WHERE p.available=true AND p.archived_at IS NULL AND p.price>=(.+) AND a.name = (.+) GROUP BY").
+					WithArgs(domain.Money(2000), "material").WillReturnRows(rows)
+				rows = sqlmock.NewRows([]string{"bucket", "count"}).
+					AddRow(1, 2)
+				mock.ExpectQuery("SELECT width_bucket(.+) WHERE p.available=true AND p.archived_at IS NULL AND EXISTS (.+) GROUP BY bucket").
+					WithArgs("material", pq.Array([]string{"wool"}), edges).WillReturnRows(rows)
+				mock.ExpectQuery("SELECT c.id, c.name, COUNT(.+) WHERE p.available=true AND p.archived_at IS NULL AND p.price>=(.+) AND EXISTS (.+) GROUP BY").
+					WithArgs(domain.Money(2000), "material", pq.Array([]string{"wool"})).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "count"}))
+				mock.ExpectQuery("SELECT (.+) AS in_stock, (.+) AS out_of_stock FROM products p WHERE p.available=true AND p.archived_at IS NULL AND p.price>=").
+					WithArgs(domain.Money(2000), "material", pq.Array([]string{"wool"})).WillReturnRows(sqlmock.NewRows([]string{"in_stock", "out_of_stock"}).AddRow(1, 0))
+			},
+			input: args{0, domain.ProductFilter{
+				ProductFilterParams: domain.ProductFilterParams{MinPrice: moneyPointer(2000)},
+				Attributes:          map[string][]string{"material": {"wool"}},
+			}},
+			want: domain.Facets{
+				Attributes: []domain.Facet{
+					{Attribute: "brand", Values: []domain.FacetValue{{Value: "Acme", Count: 1}}},
+					{Attribute: "material", Values: []domain.FacetValue{{Value: "cotton", Count: 3}, {Value: "wool", Count: 1}}},
+				},
+				Price: []domain.PriceBucket{
+					{Min: 1000, Max: moneyPointer(2500), Count: 2},
+				},
+				Categories:   []domain.CategoryCount{},
+				Availability: domain.AvailabilityCount{InStock: 1},
+			},
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectQuery("SELECT a.name, pa.value, COUNT(.+)").
+					WillReturnError(errors.New("select error"))
+			},
+			input:   args{0, domain.ProductFilter{}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetFacets(tt.input.categoryId, tt.input.filter)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
 }
 
 func intPointer(i int) *int {
 	return &i
 }
 
-func float32Pointer(f float32) *float32 {
-	return &f
+func moneyPointer(m domain.Money) *domain.Money {
+	return &m
+}
+
+func TestSetProductCategories(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	type args struct {
+		productId   int
+		categoryIds []int
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+	}{
+		{
+			name: "Ok skipping the primary category",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT category_id FROM products WHERE id=(.+) FOR UPDATE").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(2))
+				mock.ExpectExec("DELETE FROM product_categories").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO product_categories").
+					WithArgs(1, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			input: args{1, []int{2, 5}},
+		},
+		{
+			name: "Product not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT category_id FROM products").
+					WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"category_id"}))
+				mock.ExpectRollback()
+			},
+			input:   args{2, []int{5}},
+			wantErr: true,
+		},
+		{
+			name: "Category not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT category_id FROM products").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(2))
+				mock.ExpectExec("DELETE FROM product_categories").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO product_categories").
+					WithArgs(1, 99).WillReturnError(&pq.Error{Code: "23503"})
+				mock.ExpectRollback()
+			},
+			input:   args{1, []int{99}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.SetCategories(tt.input.productId, tt.input.categoryIds)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestArchiveProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		id      int
+		archive bool
+		wantErr bool
+	}{
+		{
+			name: "Archive",
+			mock: func() {
+				mock.ExpectQuery("UPDATE products SET archived_at=COALESCE\\(archived_at, now\\(\\)\\) WHERE id=(.+) RETURNING id").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			},
+			id:      1,
+			archive: true,
+		},
+		{
+			name: "Restore",
+			mock: func() {
+				mock.ExpectQuery("UPDATE products SET archived_at=NULL WHERE id=(.+) RETURNING id").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			},
+			id: 1,
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectQuery("UPDATE products SET archived_at").
+					WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			id:      2,
+			archive: true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			if tt.archive {
+				err = r.ArchiveProduct(tt.id)
+			} else {
+				err = r.RestoreProduct(tt.id)
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		id      int
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM products WHERE id=(.+) FOR UPDATE").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM ordered_products WHERE product_id = ANY(.+)\\)").
+					WithArgs(pq.Array([]int{1})).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("SELECT id FROM product_variants WHERE product_id = ANY").
+					WithArgs(pq.Array([]int{1})).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(5))
+				mock.ExpectExec("DELETE FROM products WHERE id=(.+)").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			id: 1,
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM products").
+					WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			id:      2,
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+		{
+			name: "Ordered",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM products").
+					WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectQuery("SELECT EXISTS (.+) ordered_products").
+					WithArgs(pq.Array([]int{3})).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			id:      3,
+			wantErr: true,
+			errType: errors_handler.TypeForeignKeyViolation,
+		},
+		{
+			name: "Ordered meanwhile",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM products").
+					WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectQuery("SELECT EXISTS (.+) ordered_products").
+					WithArgs(pq.Array([]int{3})).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("SELECT id FROM product_variants").
+					WithArgs(pq.Array([]int{3})).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("DELETE FROM products").
+					WithArgs(3).WillReturnError(&pq.Error{Code: "23503"})
+				mock.ExpectRollback()
+			},
+			id:      3,
+			wantErr: true,
+			errType: errors_handler.TypeForeignKeyViolation,
+		},
+		{
+			name: "Ordered at commit",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM products").
+					WithArgs(6).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+				mock.ExpectQuery("SELECT EXISTS (.+) ordered_products").
+					WithArgs(pq.Array([]int{6})).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("SELECT id FROM product_variants").
+					WithArgs(pq.Array([]int{6})).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("DELETE FROM products").
+					WithArgs(6).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit().WillReturnError(&pq.Error{Code: "23503"})
+			},
+			id:      6,
+			wantErr: true,
+			errType: errors_handler.TypeForeignKeyViolation,
+		},
+	}
+
+	// The media of a product is only removed once its deletion is committed.
+	mediaFile := "./data/products/6/image.jpg"
+	if err := os.MkdirAll("./data/products/6", 0755); err != nil {
+		t.Fatalf("Error creating media: %v", err)
+	}
+	if err := os.WriteFile(mediaFile, []byte("image"), 0644); err != nil {
+		t.Fatalf("Error creating media: %v", err)
+	}
+	defer os.RemoveAll("./data/products/6")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.DeleteProduct(tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+	assert.FileExists(t, mediaFile)
+}
+
+func TestAdminGetAllProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	columns := []string{"id", "category_id", "name", "description", "price", "undiscounted_price", "stock", "available", "image_url", "sort_key"}
+
+	tests := []struct {
+		name           string
+		mock           func()
+		filter         domain.AdminProductFilterParams
+		want           []domain.Product
+		wantPagination domain.Pagination
+	}{
+		{
+			name: "Ok without filters",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 1, "product name 1", "product description 1", 0.99, 1.29, 0, false, "", "").
+					AddRow(2, 1, "product name 2", "product description 2", 1.99, 1.99, 2, true, "", "")
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p WHERE true").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("SELECT (.+) FROM products p WHERE true ORDER BY p.id LIMIT (.+) OFFSET").
+					WithArgs(11, 0).WillReturnRows(rows)
+			},
+			want: []domain.Product{
+				{Id: 1, CategoryId: 1, Name: "product name 1", Description: "product description 1", Price: 99, UndiscountedPrice: 129},
+				{Id: 2, CategoryId: 1, Name: "product name 2", Description: "product description 2", Price: 199, UndiscountedPrice: 199, Stock: 2, Available: true},
+			},
+			wantPagination: domain.Pagination{Total: 2, Page: 1, PageSize: 10},
+		},
+		{
+			name: "Ok with filters",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(3, 2, "product name 3", "product description 3", 9.99, 9.99, 0, false, "", "")
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p WHERE p.name ILIKE \\$1 AND \\(p.category_id = \\$2 OR EXISTS (.+)\\) AND p.available=\\$3 AND p.archived_at IS NOT NULL AND \\(p.stock \\+ COALESCE(.+) BETWEEN 1 AND \\$4").
+					WithArgs("%name%", 2, false, domain.LowStockThreshold).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery("SELECT (.+) FROM products p WHERE (.+) BETWEEN 1 AND \\$4 ORDER BY p.id LIMIT (.+) OFFSET").
+					WithArgs("%name%", 2, false, domain.LowStockThreshold, 11, 0).WillReturnRows(rows)
+			},
+			filter: domain.AdminProductFilterParams{Search: "name", CategoryId: 2, Available: boolPointer(false), Archived: boolPointer(true), Stock: domain.StockLevelLow},
+			want: []domain.Product{
+				{Id: 3, CategoryId: 2, Name: "product name 3", Description: "product description 3", Price: 999, UndiscountedPrice: 999},
+			},
+			wantPagination: domain.Pagination{Total: 1, Page: 1, PageSize: 10},
+		},
+		{
+			name: "Ok out of stock",
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM products p WHERE p.archived_at IS NULL AND \\(p.stock \\+ COALESCE(.+)\\) = 0").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery("SELECT (.+) FROM products p WHERE (.+) = 0 ORDER BY p.id LIMIT (.+) OFFSET").
+					WithArgs(11, 0).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			filter:         domain.AdminProductFilterParams{Archived: boolPointer(false), Stock: domain.StockLevelOutOfStock},
+			want:           []domain.Product{},
+			wantPagination: domain.Pagination{Total: 0, Page: 1, PageSize: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, pagination, err := r.AdminGetAll(domain.PageRequest{Limit: 10}, tt.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPagination, pagination)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBulkUpdateProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProductPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	columns := []string{"id", "name", "category_id", "price", "undiscounted_price", "stock", "available"}
+	selectQuery := "SELECT p.id, p.name, p.category_id, p.price, p.undiscounted_price, p.stock, p.available FROM products p WHERE (.+) ORDER BY p.id LIMIT 1001 FOR UPDATE OF p"
+	percent := domain.Percent(-200000)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   domain.BulkUpdateProductsInput
+		want    domain.BulkUpdateResult
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).
+					WithArgs(pq.Array([]int{1, 2})).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, "product name 1", 1, 10, 10, 3, true).
+					AddRow(2, "product name 2", 1, 20, 25, 0, false))
+				mock.ExpectExec("UPDATE products SET category_id=(.+), price=(.+), undiscounted_price=(.+), stock=(.+), available=(.+) WHERE id=(.+)").
+					WithArgs(1, domain.Money(800), domain.Money(1000), 3, true, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE products SET").
+					WithArgs(1, domain.Money(1600), domain.Money(2500), 0, false, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			input: domain.BulkUpdateProductsInput{
+				ProductIds: []int{1, 2},
+				Operations: domain.BulkProductOperations{PricePercent: &percent},
+			},
+			want: domain.BulkUpdateResult{
+				Matched: 2,
+				Changed: 2,
+				Changes: []domain.ProductChange{
+					{
+						Id:     1,
+						Name:   "product name 1",
+						Before: domain.ProductState{CategoryId: 1, Price: 1000, UndiscountedPrice: 1000, Stock: 3, Available: true},
+						After:  domain.ProductState{CategoryId: 1, Price: 800, UndiscountedPrice: 1000, Stock: 3, Available: true},
+					},
+					{
+						Id:     2,
+						Name:   "product name 2",
+						Before: domain.ProductState{CategoryId: 1, Price: 2000, UndiscountedPrice: 2500},
+						After:  domain.ProductState{CategoryId: 1, Price: 1600, UndiscountedPrice: 2500},
+					},
+				},
+			},
+		},
+		{
+			name: "Preview with filter",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM products p WHERE p.available=\\$1 ORDER BY p.id LIMIT 1001 FOR UPDATE OF p").
+					WithArgs(true).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, "product name 1", 1, 10, 10, 3, true).
+					AddRow(2, "product name 2", 1, 20, 25, 0, true))
+				mock.ExpectRollback()
+			},
+			input: domain.BulkUpdateProductsInput{
+				Filter:     &domain.AdminProductFilterParams{Available: boolPointer(true)},
+				Operations: domain.BulkProductOperations{StockDelta: intPointer(-1)},
+				Preview:    true,
+			},
+			want: domain.BulkUpdateResult{
+				Preview: true,
+				Matched: 2,
+				Changed: 1,
+				Failed:  1,
+				Changes: []domain.ProductChange{
+					{
+						Id:     1,
+						Name:   "product name 1",
+						Before: domain.ProductState{CategoryId: 1, Price: 1000, UndiscountedPrice: 1000, Stock: 3, Available: true},
+						After:  domain.ProductState{CategoryId: 1, Price: 1000, UndiscountedPrice: 1000, Stock: 2, Available: true},
+					},
+					{
+						Id:     2,
+						Name:   "product name 2",
+						Before: domain.ProductState{CategoryId: 1, Price: 2000, UndiscountedPrice: 2500, Available: true},
+						After:  domain.ProductState{CategoryId: 1, Price: 2000, UndiscountedPrice: 2500, Stock: -1, Available: true},
+						Error:  "stock: must be no less than 0",
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid change",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).
+					WithArgs(pq.Array([]int{1})).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, "product name 1", 1, 10, 10, 3, true))
+				mock.ExpectRollback()
+			},
+			input: domain.BulkUpdateProductsInput{
+				ProductIds: []int{1},
+				Operations: domain.BulkProductOperations{Price: moneyPointer(1200)},
+			},
+			want: domain.BulkUpdateResult{
+				Matched: 1,
+				Failed:  1,
+				Changes: []domain.ProductChange{
+					{
+						Id:     1,
+						Name:   "product name 1",
+						Before: domain.ProductState{CategoryId: 1, Price: 1000, UndiscountedPrice: 1000, Stock: 3, Available: true},
+						After:  domain.ProductState{CategoryId: 1, Price: 1200, UndiscountedPrice: 1000, Stock: 3, Available: true},
+						Error:  "price: must not exceed undiscounted_price",
+					},
+				},
+			},
+		},
+		{
+			name: "Move to category",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).
+					WithArgs(pq.Array([]int{1})).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, "product name 1", 1, 10, 10, 3, true))
+				mock.ExpectQuery("SELECT id FROM categories WHERE id=(.+)").
+					WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectExec("UPDATE products SET").
+					WithArgs(2, domain.Money(1000), domain.Money(1000), 3, true, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM product_categories WHERE product_id = ANY\\(\\$1\\) AND category_id=\\$2").
+					WithArgs(pq.Array([]int{1}), 2).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			input: domain.BulkUpdateProductsInput{
+				ProductIds: []int{1},
+				Operations: domain.BulkProductOperations{CategoryId: intPointer(2)},
+			},
+			want: domain.BulkUpdateResult{
+				Matched: 1,
+				Changed: 1,
+				Changes: []domain.ProductChange{
+					{
+						Id:     1,
+						Name:   "product name 1",
+						Before: domain.ProductState{CategoryId: 1, Price: 1000, UndiscountedPrice: 1000, Stock: 3, Available: true},
+						After:  domain.ProductState{CategoryId: 2, Price: 1000, UndiscountedPrice: 1000, Stock: 3, Available: true},
+					},
+				},
+			},
+		},
+		{
+			name: "Category not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).
+					WithArgs(pq.Array([]int{1})).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, "product name 1", 1, 10, 10, 3, true))
+				mock.ExpectQuery("SELECT id FROM categories").
+					WithArgs(9).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			input: domain.BulkUpdateProductsInput{
+				ProductIds: []int{1},
+				Operations: domain.BulkProductOperations{CategoryId: intPointer(9)},
+			},
+			wantErr: true,
+			errType: errors_handler.TypeForeignKeyViolation,
+		},
+		{
+			name: "Product not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).
+					WithArgs(pq.Array([]int{1, 5})).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, "product name 1", 1, 10, 10, 3, true))
+				mock.ExpectRollback()
+			},
+			input: domain.BulkUpdateProductsInput{
+				ProductIds: []int{1, 5},
+				Operations: domain.BulkProductOperations{Available: boolPointer(false)},
+			},
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.BulkUpdate(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
 }
@@ -90,7 +90,7 @@ func (r *ProfilePostgres) UpdateProfile(userId int, input domain.UpdateProfileIn
 	return tx.Commit()
 }
 
-func (r *ProfilePostgres) CreateOrder(userId int, products []domain.CreateOrderInputProduct) (int, error) {
+func (r *ProfilePostgres) CreateOrder(userId int, checkout domain.Checkout, products []domain.CreateOrderInputProduct) (int, error) {
 	tx, err := r.db.Begin()
 
 	if err != nil {
@@ -98,26 +98,91 @@ func (r *ProfilePostgres) CreateOrder(userId int, products []domain.CreateOrderI
 	}
 	defer tx.Rollback()
 
+	orderId, err := createOrder(tx, userId, checkout, products)
+	if err != nil {
+		return 0, err
+	}
+	return orderId, tx.Commit()
+}
+
+// createOrder places an order priced by the checkout in the transaction,
+// taking the ordered quantities from the stock. The prices are recorded
+// converted to the order currency, with the discount and the tax of each
+// line.
+func createOrder(tx *sql.Tx, userId int, checkout domain.Checkout, products []domain.CreateOrderInputProduct) (int, error) {
+	rate := checkout.ExchangeRate
+
+	taxRates, err := orderTaxRates(tx, checkout.TaxRegion, products)
+	if err != nil {
+		return 0, err
+	}
+
+	lines := make([]domain.OrderedProduct, len(products))
+	for i, product := range products {
+		var ordered domain.OrderedProduct
+		if product.VariantId != nil {
+			ordered, err = decrementVariantStock(tx, product)
+		} else {
+			ordered, err = decrementProductStock(tx, product)
+		}
+		if err != nil {
+			return 0, err
+		}
+		ordered.ProductId = product.Id
+		ordered.VariantId = product.VariantId
+		ordered.Quantity = product.Quantity
+		ordered.Price = rate.Rate.Convert(ordered.Price)
+		ordered.UndiscountedPrice = rate.Rate.Convert(ordered.UndiscountedPrice)
+		lines[i] = ordered
+	}
+
+	var promoCodeId *int
+	var promoCode *string
+	var discount domain.Money
+	if checkout.PromoCode != "" {
+		promo, promoDiscount, err := applyPromoCode(tx, userId, checkout.PromoCode, rate.Rate, lines)
+		if err != nil {
+			return 0, err
+		}
+		promoCodeId, promoCode, discount = &promo.Id, &promo.Code, promoDiscount
+	}
+
 	var orderId int
 	orderDate := time.Now()
 	createOrderQuery := fmt.Sprintf(`INSERT INTO %s (
 		user_id, 
-		date
-	) VALUES ($1, $2) RETURNING id`, ordersTable)
-	row := tx.QueryRow(createOrderQuery, userId, orderDate)
+		date,
+		currency,
+		exchange_rate,
+		tax_region,
+		prices_include_tax,
+		promo_code_id,
+		promo_code,
+		discount_amount
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`, ordersTable)
+	row := tx.QueryRow(createOrderQuery, userId, orderDate, rate.Currency, rate.Rate, checkout.TaxRegion, checkout.PricesIncludeTax,
+		promoCodeId, promoCode, discount)
 	if err := row.Scan(&orderId); err != nil {
 		return 0, err
 	}
+
 	createOrderedProductsQuery := fmt.Sprintf(`INSERT INTO %s (
 		order_id, 
 		product_id, 
+		variant_id, 
+		sku, 
+		variant_options, 
 		name, 
 		description, 
 		price, 
 		undiscounted_price, 
 		image_url, 
-		quantity
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, orderedProductsTable)
+		quantity,
+		tax_rate,
+		discount_amount,
+		net_amount,
+		tax_amount
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`, orderedProductsTable)
 
 	stmt, err := tx.Prepare(createOrderedProductsQuery)
 	if err != nil {
@@ -125,55 +190,125 @@ func (r *ProfilePostgres) CreateOrder(userId int, products []domain.CreateOrderI
 	}
 	defer stmt.Close()
 
-	for _, product := range products {
-		updateStockQuery := fmt.Sprintf(`UPDATE %s SET stock = stock - $1 WHERE id = $2 RETURNING 
-			name, 
-			description, 
-			price, 
-			undiscounted_price, 
-			image_url, 
-			stock
-		`, productsTable)
-		var productFromTable domain.Product
-
-		row := tx.QueryRow(updateStockQuery, product.Quantity, product.Id)
-		err := row.Scan(
-			&productFromTable.Name,
-			&productFromTable.Description,
-			&productFromTable.Price,
-			&productFromTable.UndiscountedPrice,
-			&productFromTable.ImageUrl,
-			&productFromTable.Stock)
+	for _, ordered := range lines {
+		checkout.PriceLine(&ordered, taxRates[ordered.ProductId])
 
-		if err != nil {
-			if err == sql.ErrNoRows {
-				return 0, errors_handler.NoRows()
-			}
-			if pqErr, ok := err.(*pq.Error); ok && strings.Contains(pqErr.Message, "violates check constraint \"stock\"") {
-				return 0, errors_handler.ConstrainViolation("stock")
-			}
-			return 0, err
-		}
 		_, err = stmt.Exec(
 			orderId,
-			product.Id,
-			productFromTable.Name,
-			productFromTable.Description,
-			productFromTable.Price,
-			productFromTable.UndiscountedPrice,
-			productFromTable.ImageUrl,
-			product.Quantity)
+			ordered.ProductId,
+			ordered.VariantId,
+			ordered.Sku,
+			ordered.VariantOptions,
+			ordered.Name,
+			ordered.Description,
+			ordered.Price,
+			ordered.UndiscountedPrice,
+			ordered.ImageUrl,
+			ordered.Quantity,
+			ordered.TaxRate,
+			ordered.DiscountAmount,
+			ordered.NetAmount,
+			ordered.TaxAmount)
 		if err != nil {
 			return 0, err
 		}
 	}
-	return orderId, tx.Commit()
+	return orderId, nil
 }
 
-func (r *ProfilePostgres) GetAllOrders(userId, limit, offset int) ([]domain.Order, error) {
+// decrementProductStock takes the ordered quantity from the stock of a product
+// without variants and returns what has to be recorded in the order.
+func decrementProductStock(tx *sql.Tx, product domain.CreateOrderInputProduct) (domain.OrderedProduct, error) {
+	query := fmt.Sprintf(`UPDATE %s SET stock = stock - $1 WHERE id = $2 AND archived_at IS NULL RETURNING 
+		name, 
+		description, 
+		price, 
+		undiscounted_price, 
+		image_url, 
+		EXISTS (SELECT 1 FROM %s v WHERE v.product_id = $2)
+	`, productsTable, productVariantsTable)
+
+	var ordered domain.OrderedProduct
+	var hasVariants bool
+	err := tx.QueryRow(query, product.Quantity, product.Id).Scan(
+		&ordered.Name,
+		&ordered.Description,
+		&ordered.Price,
+		&ordered.UndiscountedPrice,
+		&ordered.ImageUrl,
+		&hasVariants)
+	if err != nil {
+		return ordered, stockError(err)
+	}
+	if hasVariants {
+		return ordered, errors_handler.BadRequest("variant_id is required for products with variants")
+	}
+	return ordered, nil
+}
+
+// decrementVariantStock takes the ordered quantity from the stock of a variant
+// and returns the variant data to be recorded in the order. The image of the
+// product is used when the variant has none.
+func decrementVariantStock(tx *sql.Tx, product domain.CreateOrderInputProduct) (domain.OrderedProduct, error) {
+	query := fmt.Sprintf(`UPDATE %s v SET stock = v.stock - $1 
+		FROM %s p 
+		WHERE v.id = $2 AND v.product_id = $3 AND p.id = v.product_id AND p.archived_at IS NULL 
+		RETURNING 
+			p.name, 
+			p.description, 
+			v.price, 
+			v.undiscounted_price, 
+			COALESCE(NULLIF(v.image_url, ''), p.image_url), 
+			v.sku, 
+			v.options
+	`, productVariantsTable, productsTable)
+
+	var ordered domain.OrderedProduct
+	err := tx.QueryRow(query, product.Quantity, *product.VariantId, product.Id).Scan(
+		&ordered.Name,
+		&ordered.Description,
+		&ordered.Price,
+		&ordered.UndiscountedPrice,
+		&ordered.ImageUrl,
+		&ordered.Sku,
+		&ordered.VariantOptions)
+	if err != nil {
+		return ordered, stockError(err)
+	}
+	return ordered, nil
+}
+
+func stockError(err error) error {
+	if err == sql.ErrNoRows {
+		return errors_handler.NoRows()
+	}
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "check_violation" && strings.HasSuffix(pqErr.Constraint, "stock_check") {
+		return errors_handler.ConstrainViolation("stock")
+	}
+	return err
+}
+
+func (r *ProfilePostgres) GetAllOrders(userId int, page domain.PageRequest) ([]domain.Order, domain.Pagination, error) {
+	c := &queryConditions{}
+	c.add("ot.user_id=" + c.arg(userId))
+
+	idRows, pagination, err := selectPage[orderRow](r.db, pageQuery{
+		columns: "ot.id",
+		from:    ordersTable + " ot",
+		where:   c,
+		id:      "ot.id",
+	}, page)
+	if err != nil || len(idRows) == 0 {
+		return make([]domain.Order, 0), pagination, err
+	}
+	ids := make([]int, len(idRows))
+	for i, row := range idRows {
+		ids[i] = row.Id
+	}
+
 	query := fmt.Sprintf(`
 		WITH order_total_cost AS (
-			SELECT order_id, SUM(price * quantity) AS total_cost
+			SELECT order_id, SUM(net_amount) AS net_amount, SUM(tax_amount) AS tax_amount
 				FROM %s opt
 				GROUP BY opt.order_id
 				ORDER BY opt.order_id
@@ -182,36 +317,44 @@ func (r *ProfilePostgres) GetAllOrders(userId, limit, offset int) ([]domain.Orde
 			ot.id AS order_id,     
 			ot.user_id, 
 			ot.date,     
+			ot.currency,
+			ot.exchange_rate,
+			ot.tax_region,
+			ot.prices_include_tax,
+			ot.promo_code,
+			ot.discount_amount,
 			opt.id, 
 			opt.product_id,     
+			opt.variant_id, 
+			opt.sku, 
+			opt.variant_options, 
 			opt.name, 
 			opt.description,     
 			opt.price, 
 			opt.undiscounted_price,     
 			opt.image_url, 
 			opt.quantity,
-			otct.total_cost
-		FROM 
-			( SELECT * 
-			FROM %s ot
-			WHERE ot.user_id = $1
-			ORDER BY ot.id
-			LIMIT $2
-			OFFSET $3
-			) ot
+			opt.tax_rate,
+			opt.discount_amount,
+			opt.net_amount,
+			opt.tax_amount,
+			otct.net_amount,
+			otct.tax_amount
+		FROM %s ot
 		INNER JOIN %s opt
 		ON ot.id = opt.order_id
 		INNER JOIN order_total_cost otct
 		ON otct.order_id = ot.id
+		WHERE ot.id = ANY($1)
 		ORDER BY ot.id, opt.id;
 	`, orderedProductsTable, ordersTable, orderedProductsTable)
 
-	rows, err := r.db.Query(query, userId, limit, offset)
+	rows, err := r.db.Query(query, pq.Array(ids))
 	if err != nil {
-		return nil, err
+		return nil, pagination, err
 	}
 	defer rows.Close()
-	var orders []domain.Order
+	orders := make([]domain.Order, 0, len(ids))
 	for rows.Next() {
 		var order domain.Order
 		var product domain.OrderedProduct
@@ -219,18 +362,34 @@ func (r *ProfilePostgres) GetAllOrders(userId, limit, offset int) ([]domain.Orde
 			&order.Id,
 			&order.UserId,
 			&order.Date,
+			&order.Currency,
+			&order.ExchangeRate,
+			&order.TaxRegion,
+			&order.PricesIncludeTax,
+			&order.PromoCode,
+			&order.DiscountAmount,
 			&product.Id,
 			&product.ProductId,
+			&product.VariantId,
+			&product.Sku,
+			&product.VariantOptions,
 			&product.Name,
 			&product.Description,
 			&product.Price,
 			&product.UndiscountedPrice,
 			&product.ImageUrl,
 			&product.Quantity,
-			&order.TotalCost)
+			&product.TaxRate,
+			&product.DiscountAmount,
+			&product.NetAmount,
+			&product.TaxAmount,
+			&order.NetAmount,
+			&order.TaxAmount)
 		if err != nil {
-			return nil, err
+			return nil, pagination, err
 		}
+		product.GrossAmount = product.NetAmount + product.TaxAmount
+		order.TotalCost = order.NetAmount + order.TaxAmount
 
 		order.Products = append(order.Products, product)
 
@@ -247,13 +406,23 @@ func (r *ProfilePostgres) GetAllOrders(userId, limit, offset int) ([]domain.Orde
 			orders = append(orders, order)
 		}
 	}
-	return orders, nil
+	return orders, pagination, rows.Err()
+}
+
+// orderRow is an order listing row.
+type orderRow struct {
+	Id      int    `db:"id"`
+	SortKey string `db:"sort_key"`
+}
+
+func (r orderRow) position() (string, int) {
+	return r.SortKey, r.Id
 }
 
 func (r *ProfilePostgres) GetOrderById(userId, orderId int) (domain.Order, error) {
 	query := fmt.Sprintf(`
 		WITH order_total_cost AS (
-			SELECT order_id, SUM(price * quantity) AS total_cost
+			SELECT order_id, SUM(net_amount) AS net_amount, SUM(tax_amount) AS tax_amount
 				FROM %s op
 				WHERE order_id=$1
 				GROUP BY order_id
@@ -262,15 +431,29 @@ func (r *ProfilePostgres) GetOrderById(userId, orderId int) (domain.Order, error
 				ot.id AS order_id,     
 				ot.user_id, 
 				ot.date,     
+				ot.currency,
+				ot.exchange_rate,
+				ot.tax_region,
+				ot.prices_include_tax,
+				ot.promo_code,
+				ot.discount_amount,
 				opt.id, 
 				opt.product_id,     
+				opt.variant_id, 
+				opt.sku, 
+				opt.variant_options, 
 				opt.name, 
 				opt.description,     
 				opt.price, 
 				opt.undiscounted_price,     
 				opt.image_url, 
 				opt.quantity,
-				otct.total_cost
+				opt.tax_rate,
+				opt.discount_amount,
+				opt.net_amount,
+				opt.tax_amount,
+				otct.net_amount,
+				otct.tax_amount
 			FROM %s ot
 			INNER JOIN %s opt
 			ON opt.order_id = ot.id 
@@ -293,18 +476,34 @@ func (r *ProfilePostgres) GetOrderById(userId, orderId int) (domain.Order, error
 			&order.Id,
 			&order.UserId,
 			&order.Date,
+			&order.Currency,
+			&order.ExchangeRate,
+			&order.TaxRegion,
+			&order.PricesIncludeTax,
+			&order.PromoCode,
+			&order.DiscountAmount,
 			&product.Id,
 			&product.ProductId,
+			&product.VariantId,
+			&product.Sku,
+			&product.VariantOptions,
 			&product.Name,
 			&product.Description,
 			&product.Price,
 			&product.UndiscountedPrice,
 			&product.ImageUrl,
 			&product.Quantity,
-			&order.TotalCost)
+			&product.TaxRate,
+			&product.DiscountAmount,
+			&product.NetAmount,
+			&product.TaxAmount,
+			&order.NetAmount,
+			&order.TaxAmount)
 		if err != nil {
 			return order, err
 		}
+		product.GrossAmount = product.NetAmount + product.TaxAmount
+		order.TotalCost = order.NetAmount + order.TaxAmount
 		product.OrderId = orderId
 		order.Products = append(order.Products, product)
 	}
@@ -1,13 +1,16 @@
 package repository
 
 import (
+	"database/sql"
 	"mime/multipart"
 	"os"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
 	"github.com/renlin-code/mock-shop-api/pkg/storage"
 	"github.com/stretchr/testify/assert"
 )
@@ -192,6 +195,10 @@ func TestCreateOrder(t *testing.T) {
 	r := newProfilePostgres(sqlx.NewDb(db, "sqlmock"), s)
 
 	userId := 1
+	checkout := domain.Checkout{
+		ExchangeRate: domain.ExchangeRate{Currency: "EUR", Rate: 92000000},
+		TaxRegion:    "FR",
+	}
 	products := []domain.CreateOrderInputProduct{
 		{Id: 1, Quantity: 5},
 		{Id: 2, Quantity: 3},
@@ -201,29 +208,247 @@ func TestCreateOrder(t *testing.T) {
 		AddRow(123)
 
 	mock.ExpectBegin()
-	mock.ExpectQuery("INSERT INTO orders").
-		WithArgs(userId, sqlmock.AnyArg()).
-		WillReturnRows(rows)
-	mock.ExpectPrepare("INSERT INTO ordered_products")
-
+	mock.ExpectQuery("SELECT p.id, tr.rate FROM products p (.+) WHERE p.id = ANY\\(\\$1\\)").
+		WithArgs(pq.Array([]int64{1, 2}), pq.Array([]string{"FR", "*"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "rate"}).AddRow(1, "20.0000"))
 	for _, product := range products {
-		rows = sqlmock.NewRows([]string{"name", "description", "price", "undiscounted_price", "image_url", "stock"}).
-			AddRow("Product1", "Description1", 10.5, 12.0, "image1.jpg", 20)
+		rows := sqlmock.NewRows([]string{"name", "description", "price", "undiscounted_price", "image_url", "exists"}).
+			AddRow("Product1", "Description1", 10.5, 12.0, "image1.jpg", false)
 
 		mock.ExpectQuery("UPDATE products SET stock").
 			WithArgs(product.Quantity, product.Id).
 			WillReturnRows(rows)
+	}
+	mock.ExpectQuery("INSERT INTO orders").
+		WithArgs(userId, sqlmock.AnyArg(), "EUR", domain.Rate(92000000), "FR", false, nil, nil, domain.Money(0)).
+		WillReturnRows(rows)
+	mock.ExpectPrepare("INSERT INTO ordered_products")
 
+	taxes := map[int][]interface{}{
+		1: {domain.TaxRate(200000), domain.Money(4830), domain.Money(966)},
+		2: {domain.TaxRate(0), domain.Money(2898), domain.Money(0)},
+	}
+	for _, product := range products {
 		mock.ExpectExec("INSERT INTO ordered_products").
-			WithArgs(123, product.Id, "Product1", "Description1", 10.5, 12.0, "image1.jpg", product.Quantity).
+			WithArgs(123, product.Id, nil, "", "{}", "Product1", "Description1", domain.Money(966), domain.Money(1104), "image1.jpg", product.Quantity, taxes[product.Id][0], domain.Money(0), taxes[product.Id][1], taxes[product.Id][2]).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 	}
 
 	mock.ExpectCommit()
 
-	orderId, err := r.CreateOrder(userId, products)
+	orderId, err := r.CreateOrder(userId, checkout, products)
 	assert.NoError(t, err)
 	assert.Equal(t, 123, orderId)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestCreateOrderWithVariants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newProfilePostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	userId := 1
+	variantId := 7
+	checkout := domain.Checkout{ExchangeRate: domain.StoreExchangeRate("USD"), PricesIncludeTax: true}
+
+	tests := []struct {
+		name     string
+		mock     func()
+		products []domain.CreateOrderInputProduct
+		want     int
+		wantErr  bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT p.id, tr.rate FROM products p").
+					WithArgs(pq.Array([]int64{1}), pq.Array([]string{"*"})).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "rate"}).AddRow(1, "25.0000"))
+
+				rows := sqlmock.NewRows([]string{"name", "description", "price", "undiscounted_price", "image_url", "sku", "options"}).
+					AddRow("T-shirt", "Description", 15.0, 20.0, "image.jpg", "TS-M", `{"size": "M"}`)
+				mock.ExpectQuery("UPDATE product_variants v SET stock").
+					WithArgs(2, variantId, 1).
+					WillReturnRows(rows)
+
+				mock.ExpectQuery("INSERT INTO orders").
+					WithArgs(userId, sqlmock.AnyArg(), "USD", domain.OneRate, "", true, nil, nil, domain.Money(0)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(123))
+				mock.ExpectPrepare("INSERT INTO ordered_products")
+				mock.ExpectExec("INSERT INTO ordered_products").
+					WithArgs(123, 1, variantId, "TS-M", `{"size":"M"}`, "T-shirt", "Description", domain.Money(1500), domain.Money(2000), "image.jpg", 2, domain.TaxRate(250000), domain.Money(0), domain.Money(2400), domain.Money(600)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			products: []domain.CreateOrderInputProduct{
+				{Id: 1, VariantId: &variantId, Quantity: 2},
+			},
+			want: 123,
+		},
+		{
+			name: "Variant is required",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT p.id, tr.rate FROM products p").
+					WithArgs(pq.Array([]int64{1}), pq.Array([]string{"*"})).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "rate"}).AddRow(1, "25.0000"))
+
+				rows := sqlmock.NewRows([]string{"name", "description", "price", "undiscounted_price", "image_url", "exists"}).
+					AddRow("T-shirt", "Description", 15.0, 20.0, "image.jpg", true)
+				mock.ExpectQuery("UPDATE products SET stock").
+					WithArgs(2, 1).
+					WillReturnRows(rows)
+				mock.ExpectRollback()
+			},
+			products: []domain.CreateOrderInputProduct{
+				{Id: 1, Quantity: 2},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Variant not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT p.id, tr.rate FROM products p").
+					WithArgs(pq.Array([]int64{1}), pq.Array([]string{"*"})).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "rate"}).AddRow(1, "25.0000"))
+
+				mock.ExpectQuery("UPDATE product_variants v SET stock").
+					WithArgs(2, variantId, 1).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			products: []domain.CreateOrderInputProduct{
+				{Id: 1, VariantId: &variantId, Quantity: 2},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.CreateOrder(userId, checkout, tt.products)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetOrderById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newProfilePostgres(sqlx.NewDb(db, "sqlmock"), nil)
+
+	columns := []string{
+		"order_id", "user_id", "date", "currency", "exchange_rate", "tax_region", "prices_include_tax", "promo_code", "discount_amount",
+		"id", "product_id", "variant_id", "sku", "variant_options", "name", "description", "price", "undiscounted_price", "image_url", "quantity",
+		"tax_rate", "discount_amount", "net_amount", "tax_amount",
+		"net_amount", "tax_amount",
+	}
+	// The selected columns are matched in order, one for each scanned field.
+	query := "SELECT ot.id AS order_id, ot.user_id, ot.date, ot.currency, ot.exchange_rate, ot.tax_region, ot.prices_include_tax, ot.promo_code, ot.discount_amount, " +
+		"opt.id, opt.product_id, opt.variant_id, opt.sku, opt.variant_options, opt.name, opt.description, opt.price, opt.undiscounted_price, opt.image_url, opt.quantity, " +
+		"opt.tax_rate, opt.discount_amount, opt.net_amount, opt.tax_amount, " +
+		"otct.net_amount, otct.tax_amount " +
+		"FROM orders ot INNER JOIN ordered_products opt (.+) WHERE ot.id=\\$1 AND ot.user_id=\\$2"
+
+	promoCode := "SAVE5"
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    domain.Order
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(123, 1).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(123, 1, "2024-06-01", "EUR", "0.92000000", "FR", false, "SAVE5", "5.00",
+							7, 1, nil, "", nil, "Product1", "Description1", "9.66", "11.04", "image1.jpg", 5,
+							"20.0000", "5.00", "43.30", "8.66",
+							"72.28", "8.66").
+						AddRow(123, 1, "2024-06-01", "EUR", "0.92000000", "FR", false, "SAVE5", "5.00",
+							8, 2, nil, "", nil, "Product2", "Description2", "9.66", "11.04", "image2.jpg", 3,
+							"0.0000", "0.00", "28.98", "0.00",
+							"72.28", "8.66"))
+			},
+			want: domain.Order{
+				Id:     123,
+				UserId: 1,
+				Date:   "2024-06-01",
+				Products: []domain.OrderedProduct{
+					{
+						Id: 7, OrderId: 123, ProductId: 1, Name: "Product1", Description: "Description1",
+						Price: 966, UndiscountedPrice: 1104, ImageUrl: "image1.jpg", Quantity: 5,
+						TaxRate: 200000, DiscountAmount: 500, NetAmount: 4330, TaxAmount: 866, GrossAmount: 5196,
+					},
+					{
+						Id: 8, OrderId: 123, ProductId: 2, Name: "Product2", Description: "Description2",
+						Price: 966, UndiscountedPrice: 1104, ImageUrl: "image2.jpg", Quantity: 3,
+						TaxRate: 0, NetAmount: 2898, TaxAmount: 0, GrossAmount: 2898,
+					},
+				},
+				DiscountAmount: 500,
+				NetAmount:      7228,
+				TaxAmount:      866,
+				TotalCost:      8094,
+				Currency:       "EUR",
+				ExchangeRate:   92000000,
+				TaxRegion:      "FR",
+				PromoCode:      &promoCode,
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(123, 1).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetOrderById(1, 123)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
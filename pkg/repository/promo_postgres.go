@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+const promoCodeColumns = "pc.id, pc.code, pc.percent, pc.amount, pc.min_order_value, pc.starts_at, pc.ends_at, pc.usage_limit, pc.per_user_limit, pc.used_count, pc.active, pc.created_at"
+
+type PromoPostgres struct {
+	db *sqlx.DB
+}
+
+func newPromoPostgres(db *sqlx.DB) *PromoPostgres {
+	return &PromoPostgres{db}
+}
+
+// GetAll returns the promo codes, last created first, without their
+// restrictions.
+func (r *PromoPostgres) GetAll(page domain.PageRequest) ([]domain.PromoCode, domain.Pagination, error) {
+	rows, pagination, err := selectPage[promoCodeRow](r.db, pageQuery{
+		columns: promoCodeColumns,
+		from:    promoCodesTable + " pc",
+		where:   &queryConditions{},
+		id:      "pc.id",
+		desc:    true,
+	}, page)
+
+	codes := make([]domain.PromoCode, len(rows))
+	for i, row := range rows {
+		codes[i] = row.PromoCode
+	}
+	return codes, pagination, err
+}
+
+// promoCodeRow is a promo code listing row.
+type promoCodeRow struct {
+	domain.PromoCode
+	SortKey string `db:"sort_key"`
+}
+
+func (r promoCodeRow) position() (string, int) {
+	return r.SortKey, r.Id
+}
+
+func (r *PromoPostgres) GetById(id int) (domain.PromoCode, error) {
+	var promo domain.PromoCode
+	query := fmt.Sprintf("SELECT %s FROM %s pc WHERE pc.id=$1", promoCodeColumns, promoCodesTable)
+	err := r.db.Get(&promo, query, id)
+	if err == sql.ErrNoRows {
+		return promo, errors_handler.NoRows()
+	}
+	if err != nil {
+		return promo, err
+	}
+
+	promo.ProductIds = make([]int, 0)
+	productsQuery := fmt.Sprintf("SELECT product_id FROM %s WHERE promo_code_id=$1 ORDER BY product_id", promoCodeProductsTable)
+	if err := r.db.Select(&promo.ProductIds, productsQuery, id); err != nil {
+		return promo, err
+	}
+	promo.CategoryIds = make([]int, 0)
+	categoriesQuery := fmt.Sprintf("SELECT category_id FROM %s WHERE promo_code_id=$1 ORDER BY category_id", promoCodeCategoriesTable)
+	err = r.db.Select(&promo.CategoryIds, categoriesQuery, id)
+	return promo, err
+}
+
+func (r *PromoPostgres) CreatePromoCode(input domain.PromoCodeInput) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The code is active by default, the column default applies when active
+	// is left out.
+	columns := "code, percent, amount, min_order_value, starts_at, ends_at, usage_limit, per_user_limit"
+	values := "$1, $2, $3, $4, $5, $6, $7, $8"
+	args := []interface{}{input.Code, input.Percent, input.Amount, input.MinOrderValue, input.StartsAt, input.EndsAt,
+		input.UsageLimit, input.PerUserLimit}
+	if input.Active != nil {
+		columns += ", active"
+		values += ", $9"
+		args = append(args, *input.Active)
+	}
+
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING id", promoCodesTable, columns, values)
+	err = tx.QueryRow(query, args...).Scan(&id)
+	if err != nil {
+		return 0, promoCodeError(err)
+	}
+	if err := insertPromoCodeRestrictions(tx, id, input); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// UpdatePromoCode replaces a promo code and its restrictions, its usage
+// count is kept, and so is its active flag when not provided.
+func (r *PromoPostgres) UpdatePromoCode(id int, input domain.PromoCodeInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`UPDATE %s SET code=$1, percent=$2, amount=$3, min_order_value=$4, starts_at=$5, ends_at=$6,
+		usage_limit=$7, per_user_limit=$8, active=COALESCE($9, active) WHERE id=$10`, promoCodesTable)
+	result, err := tx.Exec(query, input.Code, input.Percent, input.Amount, input.MinOrderValue, input.StartsAt, input.EndsAt,
+		input.UsageLimit, input.PerUserLimit, input.Active, id)
+	if err != nil {
+		return promoCodeError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return errors_handler.NoRows()
+	}
+
+	for _, table := range []string{promoCodeProductsTable, promoCodeCategoriesTable} {
+		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE promo_code_id=$1", table)
+		if _, err := tx.Exec(deleteQuery, id); err != nil {
+			return err
+		}
+	}
+	if err := insertPromoCodeRestrictions(tx, id, input); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertPromoCodeRestrictions(tx *sql.Tx, id int, input domain.PromoCodeInput) error {
+	productsQuery := fmt.Sprintf("INSERT INTO %s (promo_code_id, product_id) VALUES ($1, $2)", promoCodeProductsTable)
+	for _, productId := range input.ProductIds {
+		if _, err := tx.Exec(productsQuery, id, productId); err != nil {
+			return promoCodeError(err)
+		}
+	}
+	categoriesQuery := fmt.Sprintf("INSERT INTO %s (promo_code_id, category_id) VALUES ($1, $2)", promoCodeCategoriesTable)
+	for _, categoryId := range input.CategoryIds {
+		if _, err := tx.Exec(categoriesQuery, id, categoryId); err != nil {
+			return promoCodeError(err)
+		}
+	}
+	return nil
+}
+
+// DeletePromoCode deletes a promo code, the orders placed with it keep its
+// code and discount.
+func (r *PromoPostgres) DeletePromoCode(id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1", promoCodesTable)
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors_handler.NoRows()
+	}
+	return nil
+}
+
+// promoCodeError translates the constraint violations of the promo code
+// tables.
+func promoCodeError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		return errors_handler.AlreadyExists("promo code")
+	case "foreign_key_violation":
+		return errors_handler.ForeignKeyViolation()
+	}
+	return err
+}
+
+// applyPromoCode checks that a user can use a promo code on an order and sets
+// the discount of its lines, in the transaction placing the order. The code
+// is locked until the order is committed so its usage limits hold, and its
+// usage is counted. It returns the promo code and the discount of the order.
+func applyPromoCode(tx *sql.Tx, userId int, code string, rate domain.Rate, lines []domain.OrderedProduct) (domain.PromoCode, domain.Money, error) {
+	var promo domain.PromoCode
+	query := fmt.Sprintf("SELECT %s FROM %s pc WHERE pc.code=$1 FOR UPDATE", promoCodeColumns, promoCodesTable)
+	err := tx.QueryRow(query, code).Scan(
+		&promo.Id,
+		&promo.Code,
+		&promo.Percent,
+		&promo.Amount,
+		&promo.MinOrderValue,
+		&promo.StartsAt,
+		&promo.EndsAt,
+		&promo.UsageLimit,
+		&promo.PerUserLimit,
+		&promo.UsedCount,
+		&promo.Active,
+		&promo.CreatedAt)
+	if err == sql.ErrNoRows {
+		return promo, 0, errors_handler.BadRequest("promo_code: does not exist")
+	}
+	if err != nil {
+		return promo, 0, err
+	}
+
+	var uses int
+	usesQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE promo_code_id=$1 AND user_id=$2", ordersTable)
+	if err := tx.QueryRow(usesQuery, promo.Id, userId).Scan(&uses); err != nil {
+		return promo, 0, err
+	}
+	if err := promo.Usable(time.Now(), uses); err != nil {
+		return promo, 0, errors_handler.BadRequest("promo_code: " + err.Error())
+	}
+
+	eligible, err := promoCodeEligibleProducts(tx, promo.Id, lines)
+	if err != nil {
+		return promo, 0, err
+	}
+	discount, err := promo.Apply(lines, eligible, rate)
+	if err != nil {
+		return promo, 0, errors_handler.BadRequest("promo_code: " + err.Error())
+	}
+
+	updateQuery := fmt.Sprintf("UPDATE %s SET used_count = used_count + 1 WHERE id=$1", promoCodesTable)
+	if _, err := tx.Exec(updateQuery, promo.Id); err != nil {
+		return promo, 0, err
+	}
+	return promo, discount, nil
+}
+
+// promoCodeEligibleProducts returns the ordered products a promo code
+// applies to: all of them when it has no restriction, otherwise the listed
+// products and the products of the listed categories, primary or not.
+func promoCodeEligibleProducts(tx *sql.Tx, promoCodeId int, lines []domain.OrderedProduct) (map[int]bool, error) {
+	ids := make([]int64, len(lines))
+	for i, line := range lines {
+		ids[i] = int64(line.ProductId)
+	}
+
+	query := fmt.Sprintf(`SELECT p.id FROM %[1]s p WHERE p.id = ANY($2) AND (
+		(NOT EXISTS (SELECT 1 FROM %[2]s WHERE promo_code_id=$1) AND NOT EXISTS (SELECT 1 FROM %[3]s WHERE promo_code_id=$1))
+		OR EXISTS (SELECT 1 FROM %[2]s pp WHERE pp.promo_code_id=$1 AND pp.product_id = p.id)
+		OR EXISTS (SELECT 1 FROM %[3]s pcc WHERE pcc.promo_code_id=$1 AND (pcc.category_id = p.category_id
+			OR pcc.category_id IN (SELECT category_id FROM %[4]s WHERE product_id = p.id)))
+	)`, productsTable, promoCodeProductsTable, promoCodeCategoriesTable, productCategoriesTable)
+
+	rows, err := tx.Query(query, promoCodeId, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	eligible := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		eligible[id] = true
+	}
+	return eligible, rows.Err()
+}
@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePromoCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newPromoPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	percent := 15
+	inactive := false
+	input := domain.PromoCodeInput{
+		Code:          "SUMMER15",
+		Percent:       &percent,
+		MinOrderValue: 5000,
+		ProductIds:    []int{4},
+		CategoryIds:   []int{2},
+	}
+
+	tests := []struct {
+		name    string
+		active  *bool
+		mock    func()
+		want    int
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO promo_codes \\(code, percent, amount, min_order_value, starts_at, ends_at, usage_limit, per_user_limit\\) "+
+					"VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8\\) RETURNING id").
+					WithArgs("SUMMER15", 15, nil, "50.00", nil, nil, nil, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+				mock.ExpectExec("INSERT INTO promo_code_products \\(promo_code_id, product_id\\) VALUES \\(\\$1, \\$2\\)").
+					WithArgs(8, 4).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO promo_code_categories \\(promo_code_id, category_id\\) VALUES \\(\\$1, \\$2\\)").
+					WithArgs(8, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: 8,
+		},
+		{
+			name:   "Inactive",
+			active: &inactive,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO promo_codes \\((.+), per_user_limit, active\\) VALUES \\((.+), \\$8, \\$9\\) RETURNING id").
+					WithArgs("SUMMER15", 15, nil, "50.00", nil, nil, nil, nil, false).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+				mock.ExpectExec("INSERT INTO promo_code_products").
+					WithArgs(9, 4).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO promo_code_categories").
+					WithArgs(9, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: 9,
+		},
+		{
+			name: "Product not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO promo_codes").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+				mock.ExpectExec("INSERT INTO promo_code_products").
+					WithArgs(8, 4).
+					WillReturnError(&pq.Error{Code: "23503"})
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errType: errors_handler.TypeForeignKeyViolation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			input.Active = tt.active
+			got, err := r.CreatePromoCode(input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestApplyPromoCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	userId := 1
+	columns := []string{"id", "code", "percent", "amount", "min_order_value", "starts_at", "ends_at", "usage_limit", "per_user_limit", "used_count", "active", "created_at"}
+	codeQuery := "SELECT (.+) FROM promo_codes pc WHERE pc.code=\\$1 FOR UPDATE"
+	usesQuery := "SELECT COUNT\\(\\*\\) FROM orders WHERE promo_code_id=\\$1 AND user_id=\\$2"
+	eligibleQuery := "SELECT p.id FROM products p WHERE p.id = ANY\\(\\$2\\)"
+	usedQuery := "UPDATE promo_codes SET used_count = used_count \\+ 1 WHERE id=\\$1"
+
+	twoLines := []domain.OrderedProduct{
+		{ProductId: 1, Price: 1000, Quantity: 2},
+		{ProductId: 2, Price: 1000, Quantity: 1},
+	}
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		lines        []domain.OrderedProduct
+		mock         func()
+		wantDiscount domain.Money
+		wantLines    []domain.Money
+		wantErr      string
+	}{
+		{
+			name:  "Percent",
+			lines: []domain.OrderedProduct{{ProductId: 1, Price: 999, Quantity: 1}, {ProductId: 2, Price: 1000, Quantity: 1}},
+			mock: func() {
+				mock.ExpectQuery(codeQuery).
+					WithArgs("SAVE5").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "SAVE5", 15, nil, "0.00", nil, nil, nil, nil, 0, true, time.Now()))
+				mock.ExpectQuery(usesQuery).
+					WithArgs(3, userId).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(eligibleQuery).
+					WithArgs(3, pq.Array([]int64{1, 2})).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(usedQuery).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantDiscount: 150,
+			wantLines:    []domain.Money{150, 0},
+		},
+		{
+			name:  "Fixed amount",
+			lines: twoLines,
+			mock: func() {
+				mock.ExpectQuery(codeQuery).
+					WithArgs("SAVE5").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "SAVE5", nil, "5.00", "10.00", nil, nil, 100, 1, 7, true, time.Now()))
+				mock.ExpectQuery(usesQuery).
+					WithArgs(3, userId).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(eligibleQuery).
+					WithArgs(3, pq.Array([]int64{1, 2})).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectExec(usedQuery).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantDiscount: 500,
+			wantLines:    []domain.Money{334, 166},
+		},
+		{
+			name: "Fixed amount split with leftovers",
+			lines: []domain.OrderedProduct{
+				{ProductId: 1, Price: 1000, Quantity: 1},
+				{ProductId: 2, Price: 1000, Quantity: 1},
+				{ProductId: 3, Price: 1000, Quantity: 1},
+				{ProductId: 4, Price: 999, Quantity: 1},
+			},
+			mock: func() {
+				mock.ExpectQuery(codeQuery).
+					WithArgs("SAVE5").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "SAVE5", nil, "1.00", "0.00", nil, nil, nil, nil, 0, true, time.Now()))
+				mock.ExpectQuery(usesQuery).
+					WithArgs(3, userId).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(eligibleQuery).
+					WithArgs(3, pq.Array([]int64{1, 2, 3, 4})).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(3).AddRow(4))
+				mock.ExpectExec(usedQuery).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantDiscount: 100,
+			wantLines:    []domain.Money{34, 0, 33, 33},
+		},
+		{
+			name:  "Inactive",
+			lines: twoLines,
+			mock: func() {
+				mock.ExpectQuery(codeQuery).
+					WithArgs("SAVE5").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "SAVE5", nil, "5.00", "0.00", nil, nil, nil, nil, 0, false, time.Now()))
+				mock.ExpectQuery(usesQuery).
+					WithArgs(3, userId).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectRollback()
+			},
+			wantErr: "promo_code: is not active",
+		},
+		{
+			name:  "Expired",
+			lines: twoLines,
+			mock: func() {
+				mock.ExpectQuery(codeQuery).
+					WithArgs("SAVE5").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "SAVE5", nil, "5.00", "0.00", nil, past, nil, nil, 0, true, time.Now()))
+				mock.ExpectQuery(usesQuery).
+					WithArgs(3, userId).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectRollback()
+			},
+			wantErr: "promo_code: has expired",
+		},
+		{
+			name:  "Exhausted",
+			lines: twoLines,
+			mock: func() {
+				mock.ExpectQuery(codeQuery).
+					WithArgs("SAVE5").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "SAVE5", nil, "5.00", "0.00", nil, nil, 100, nil, 100, true, time.Now()))
+				mock.ExpectQuery(usesQuery).
+					WithArgs(3, userId).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectRollback()
+			},
+			wantErr: "promo_code: has reached its usage limit",
+		},
+		{
+			name:  "Already used",
+			lines: twoLines,
+			mock: func() {
+				mock.ExpectQuery(codeQuery).
+					WithArgs("SAVE5").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "SAVE5", nil, "5.00", "10.00", nil, nil, 100, 1, 7, true, time.Now()))
+				mock.ExpectQuery(usesQuery).
+					WithArgs(3, userId).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			wantErr: "promo_code: has already been used",
+		},
+		{
+			name:  "Minimum order not met",
+			lines: twoLines,
+			mock: func() {
+				mock.ExpectQuery(codeQuery).
+					WithArgs("SAVE5").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "SAVE5", nil, "5.00", "30.01", nil, nil, nil, nil, 0, true, time.Now()))
+				mock.ExpectQuery(usesQuery).
+					WithArgs(3, userId).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(eligibleQuery).
+					WithArgs(3, pq.Array([]int64{1, 2})).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectRollback()
+			},
+			wantErr: "promo_code: requires an order of at least 30.01",
+		},
+		{
+			name:  "Not found",
+			lines: twoLines,
+			mock: func() {
+				mock.ExpectQuery(codeQuery).
+					WithArgs("SAVE5").
+					WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectRollback()
+			},
+			wantErr: "promo_code: does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			tt.mock()
+
+			tx, err := db.Begin()
+			assert.NoError(t, err)
+
+			lines := append([]domain.OrderedProduct(nil), tt.lines...)
+			_, discount, err := applyPromoCode(tx, userId, "SAVE5", domain.OneRate, lines)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.True(t, errors_handler.ErrorIsType(err, errors_handler.TypeBadRequest))
+				assert.NoError(t, tx.Rollback())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantDiscount, discount)
+				got := make([]domain.Money, len(lines))
+				for i, line := range lines {
+					got[i] = line.DiscountAmount
+				}
+				assert.Equal(t, tt.wantLines, got)
+				// The usage is counted in the transaction placing the order.
+				assert.NoError(t, tx.Commit())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
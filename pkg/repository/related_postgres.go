@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+type RelatedPostgres struct {
+	db *sqlx.DB
+}
+
+func newRelatedPostgres(db *sqlx.DB) *RelatedPostgres {
+	return &RelatedPostgres{db}
+}
+
+// Refresh recomputes the related products from the order history: two
+// products are related when they were ordered together at least minOrders
+// times, scored by the number of orders. The limit best scored products are
+// kept for each product. It returns the number of related pairs kept.
+func (r *RelatedPostgres) Refresh(limit, minOrders int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", relatedProductsTable)); err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (product_id, related_id, score)
+		SELECT product_id, related_id, score FROM (
+			SELECT a.product_id, b.product_id AS related_id, COUNT(DISTINCT a.order_id) AS score,
+				ROW_NUMBER() OVER (
+					PARTITION BY a.product_id ORDER BY COUNT(DISTINCT a.order_id) DESC, b.product_id
+				) AS rank
+			FROM %s a INNER JOIN %s b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+			GROUP BY a.product_id, b.product_id
+			HAVING COUNT(DISTINCT a.order_id) >= $2
+		) co_purchases WHERE rank <= $1`, relatedProductsTable, orderedProductsTable, orderedProductsTable)
+
+	result, err := tx.Exec(query, limit, minOrders)
+	if err != nil {
+		return 0, err
+	}
+	kept, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(kept), tx.Commit()
+}
+
+// GetRelated returns the products of the shop most often bought together
+// with a product of the shop, best scored first. When there are less than
+// limit, they are completed with the best rated products of its category.
+func (r *RelatedPostgres) GetRelated(productId, limit int) ([]domain.Product, error) {
+	var categoryId int
+	categoryQuery := fmt.Sprintf("SELECT category_id FROM %s WHERE id=$1 AND available=true AND archived_at IS NULL", productsTable)
+	if err := r.db.Get(&categoryId, categoryQuery, productId); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors_handler.NoRows()
+		}
+		return nil, err
+	}
+
+	products := make([]domain.Product, 0, limit)
+	relatedQuery := fmt.Sprintf(`SELECT %s FROM %s r INNER JOIN %s p ON p.id = r.related_id
+		WHERE r.product_id=$1 AND p.available=true AND p.archived_at IS NULL
+		ORDER BY r.score DESC, p.id LIMIT $2`, productColumns, relatedProductsTable, productsTable)
+	if err := r.db.Select(&products, relatedQuery, productId, limit); err != nil {
+		return nil, err
+	}
+	if len(products) >= limit {
+		return products, nil
+	}
+
+	excluded := []int64{int64(productId)}
+	for _, product := range products {
+		excluded = append(excluded, int64(product.Id))
+	}
+
+	fallback := make([]domain.Product, 0)
+	fallbackQuery := fmt.Sprintf(`SELECT %s FROM %s p
+		WHERE p.category_id=$1 AND p.id <> ALL($2) AND p.available=true AND p.archived_at IS NULL
+		ORDER BY p.rating_average DESC, p.rating_count DESC, p.id LIMIT $3`, productColumns, productsTable)
+	if err := r.db.Select(&fallback, fallbackQuery, categoryId, pq.Array(excluded), limit-len(products)); err != nil {
+		return nil, err
+	}
+	return append(products, fallback...), nil
+}
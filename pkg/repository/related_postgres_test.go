@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRelatedProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newRelatedPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	columns := []string{"id", "category_id", "name", "price", "available"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		limit   int
+		want    []domain.Product
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Bought together",
+			mock: func() {
+				mock.ExpectQuery("SELECT category_id FROM products WHERE id=\\$1 AND available=true AND archived_at IS NULL").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(4))
+				mock.ExpectQuery("SELECT (.+) FROM related_products r INNER JOIN products p ON p.id = r.related_id WHERE r.product_id=\\$1 AND p.available=true (.+) ORDER BY r.score DESC, p.id LIMIT \\$2").
+					WithArgs(1, 2).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(3, 4, "product 3", 10, true).
+					AddRow(2, 5, "product 2", 20, true))
+			},
+			limit: 2,
+			want: []domain.Product{
+				{Id: 3, CategoryId: 4, Name: "product 3", Price: 1000, Available: true},
+				{Id: 2, CategoryId: 5, Name: "product 2", Price: 2000, Available: true},
+			},
+		},
+		{
+			name: "Completed with the category",
+			mock: func() {
+				mock.ExpectQuery("SELECT category_id FROM products").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(4))
+				mock.ExpectQuery("SELECT (.+) FROM related_products").
+					WithArgs(1, 3).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(3, 4, "product 3", 10, true))
+				mock.ExpectQuery("SELECT (.+) FROM products p WHERE p.category_id=\\$1 AND p.id <> ALL\\(\\$2\\) AND p.available=true (.+) LIMIT \\$3").
+					WithArgs(4, pq.Array([]int64{1, 3}), 2).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(5, 4, "product 5", 15, true))
+			},
+			limit: 3,
+			want: []domain.Product{
+				{Id: 3, CategoryId: 4, Name: "product 3", Price: 1000, Available: true},
+				{Id: 5, CategoryId: 4, Name: "product 5", Price: 1500, Available: true},
+			},
+		},
+		{
+			name: "Product not found",
+			mock: func() {
+				mock.ExpectQuery("SELECT category_id FROM products").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"category_id"}))
+			},
+			limit:   2,
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetRelated(1, tt.limit)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"mime/multipart"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/renlin-code/mock-shop-api/pkg/domain"
 	"github.com/renlin-code/mock-shop-api/pkg/storage"
+	"github.com/renlin-code/mock-shop-api/schema"
 )
 
 type Authorization interface {
@@ -13,40 +15,201 @@ type Authorization interface {
 	GetUser(email, password string) (domain.User, error)
 	GetUserByEmail(email string) (domain.User, error)
 	UpdatePassword(userId int, password string) error
+	GetUserById(id int) (domain.User, error)
+	SetDisabled(userId int, disabled bool) error
+	SetAdmin(userId int, isAdmin bool) error
 }
 
 type Category interface {
-	GetAll(limit, offset int, search string) ([]domain.Category, error)
+	GetAll(page domain.PageRequest, search string) ([]domain.Category, domain.Pagination, error)
+	GetTree() ([]domain.Category, error)
+	GetBreadcrumbs(categoryId int) ([]domain.Breadcrumb, error)
 	GetById(id int) (domain.Category, error)
+	AdminGetAll(page domain.PageRequest, filter domain.AdminCategoryFilterParams) ([]domain.Category, domain.Pagination, error)
+	AdminGetById(id int) (domain.Category, error)
+	GetIdByName(name string) (int, error)
 	GetFilePath(categoryId int, fileName string) string
-	GetProducts(categoryId, limit, offset int, search string) ([]domain.Product, error)
+	GetProducts(categoryId int, page domain.PageRequest, filter domain.ProductFilter) ([]domain.Product, domain.Pagination, error)
 	CreateCategory(input domain.CreateCategoryInput, file multipart.File) (int, error)
 	UpdateCategory(id int, input domain.UpdateCategoryInput, file multipart.File) error
+	ArchiveCategory(id int) error
+	RestoreCategory(id int) error
+	DeleteCategory(id int) error
 }
 
 type Product interface {
-	GetAll(limit, offset int, search string) ([]domain.Product, error)
+	GetAll(page domain.PageRequest, filter domain.ProductFilter) ([]domain.Product, domain.Pagination, error)
+	GetFacets(categoryId int, filter domain.ProductFilter) (domain.Facets, error)
 	GetById(id int) (domain.Product, error)
+	AdminGetAll(page domain.PageRequest, filter domain.AdminProductFilterParams) ([]domain.Product, domain.Pagination, error)
+	AdminGetById(id int) (domain.Product, error)
+	GetIdByName(categoryId int, name string) (int, error)
+	GetCategoryIds(productId int) ([]int, error)
+	SetCategories(productId int, categoryIds []int) error
 	GetFilePath(productId int, fileName string) string
 	CreateProduct(input domain.CreateProductInput, file multipart.File) (int, error)
 	UpdateProduct(id int, input domain.UpdateProductInput, file multipart.File) error
+	ArchiveProduct(id int) error
+	RestoreProduct(id int) error
+	DeleteProduct(id int) error
+	BulkUpdate(input domain.BulkUpdateProductsInput) (domain.BulkUpdateResult, error)
+}
+
+type Attribute interface {
+	GetAttributes(categoryId int) ([]domain.Attribute, error)
+	CreateAttribute(categoryId int, input domain.CreateAttributeInput) (int, error)
+	UpdateAttribute(categoryId, attributeId int, input domain.UpdateAttributeInput) error
+	DeleteAttribute(categoryId, attributeId int) error
+	GetProductCategoryId(productId int) (int, error)
+	GetProductAttributes(productId int) ([]domain.ProductAttribute, error)
+	SetProductAttributes(productId int, values []domain.ProductAttributeValue) error
+}
+
+type ProductImage interface {
+	GetImages(productId int) ([]domain.ProductImage, error)
+	AddImage(productId int, input domain.AddProductImageInput, file multipart.File) (int, error)
+	UpdateImage(productId, imageId int, input domain.UpdateProductImageInput) error
+	SetImagePositions(productId int, imageIds []int) error
+	DeleteImage(productId, imageId int) error
+}
+
+type Variant interface {
+	GetOptions(productId int) ([]string, error)
+	SetOptions(productId int, options []string) error
+	GetVariants(productId int, onlyAvailable bool) ([]domain.ProductVariant, error)
+	GetFilePath(variantId int, fileName string) string
+	CreateVariant(productId int, input domain.CreateVariantInput, file multipart.File) (int, error)
+	UpdateVariant(productId, variantId int, input domain.UpdateVariantInput, file multipart.File) error
+	DeleteVariant(productId, variantId int) error
 }
 
 type Profile interface {
 	GetProfile(userId int) (domain.User, error)
 	GetFilePath(userId int, fileName string) string
 	UpdateProfile(userId int, input domain.UpdateProfileInput, file multipart.File) error
-	CreateOrder(userId int, products []domain.CreateOrderInputProduct) (int, error)
-	GetAllOrders(userId, limit, offset int) ([]domain.Order, error)
+	CreateOrder(userId int, checkout domain.Checkout, products []domain.CreateOrderInputProduct) (int, error)
+	GetAllOrders(userId int, page domain.PageRequest) ([]domain.Order, domain.Pagination, error)
 	GetOrderById(userId, orderId int) (domain.Order, error)
 	DeleteProfile(userId int, password string) error
 }
 
+type Collection interface {
+	GetAll(page domain.PageRequest) ([]domain.Collection, domain.Pagination, error)
+	GetById(id int) (domain.Collection, error)
+	GetProducts(collectionId int, page domain.PageRequest) ([]domain.Product, domain.Pagination, error)
+	CreateCollection(input domain.CreateCollectionInput) (int, error)
+	UpdateCollection(id int, input domain.UpdateCollectionInput) error
+	DeleteCollection(id int) error
+	SetProducts(collectionId int, productIds []int) error
+}
+
+type Review interface {
+	GetProductReviews(productId int, page domain.PageRequest) ([]domain.Review, domain.Pagination, error)
+	GetUserReviews(userId int, page domain.PageRequest) ([]domain.Review, domain.Pagination, error)
+	AdminGetAll(page domain.PageRequest, filter domain.AdminReviewFilterParams) ([]domain.Review, domain.Pagination, error)
+	GetReview(id int) (domain.Review, error)
+	HasPurchased(userId, productId int) (bool, error)
+	CreateReview(userId int, input domain.CreateReviewInput, status string) (int, error)
+	UpdateReview(userId, reviewId int, input domain.UpdateReviewInput, status *string) error
+	DeleteReview(userId, reviewId int) error
+	SetReviewStatus(reviewId int, status string) error
+	ReportReview(userId, reviewId int, input domain.ReportReviewInput, threshold int) (bool, error)
+}
+
+type Wishlist interface {
+	GetItems(userId int, page domain.PageRequest) ([]domain.WishlistItem, domain.Pagination, error)
+	AddItem(userId int, input domain.AddWishlistItemInput) error
+	RemoveItem(userId, productId int) error
+	CreateOrder(userId int, checkout domain.Checkout, products []domain.CreateOrderInputProduct) (int, error)
+	TakeAlerts() ([]domain.WishlistAlert, error)
+}
+
+type Related interface {
+	Refresh(limit, minOrders int) (int, error)
+	GetRelated(productId, limit int) ([]domain.Product, error)
+}
+
+type Currency interface {
+	GetRates() ([]domain.ExchangeRate, error)
+	GetRate(currency string) (domain.ExchangeRate, error)
+	SetRates(rates []domain.ExchangeRateInput) error
+	DeleteRate(currency string) error
+}
+
+type Tax interface {
+	GetClasses() ([]domain.TaxClass, error)
+	CreateClass(input domain.TaxClassInput) (int, error)
+	UpdateClass(id int, input domain.TaxClassInput) error
+	DeleteClass(id int) error
+	SetProductTaxClass(productId int, taxClassId *int) error
+	SetCategoryTaxClass(categoryId int, taxClassId *int) error
+}
+
+type Promo interface {
+	GetAll(page domain.PageRequest) ([]domain.PromoCode, domain.Pagination, error)
+	GetById(id int) (domain.PromoCode, error)
+	CreatePromoCode(input domain.PromoCodeInput) (int, error)
+	UpdatePromoCode(id int, input domain.PromoCodeInput) error
+	DeletePromoCode(id int) error
+}
+
+type Sale interface {
+	GetAll(page domain.PageRequest, filter domain.PriceScheduleFilterParams) ([]domain.PriceSchedule, domain.Pagination, error)
+	GetById(id int) (domain.PriceSchedule, error)
+	CreateSchedule(input domain.PriceScheduleInput) (int, error)
+	CancelSchedule(id int) error
+	Run(now time.Time) (domain.PriceScheduleRun, error)
+}
+
+type Import interface {
+	CreateJob(job domain.ImportJob) (int, error)
+	UpdateJob(job domain.ImportJob) error
+	ExpireJobs(before time.Time, reason string) (int, error)
+	GetJob(id int) (domain.ImportJob, error)
+	GetProductIds(skus []string) (map[string]int, error)
+	GetCategoryIds(ids []int) (map[int]bool, error)
+	UpsertProduct(input domain.CreateProductInput, file multipart.File) (int, bool, error)
+	ExportProducts(fn func(record domain.ProductRecord) error) error
+}
+
+type Search interface {
+	Suggest(prefix string, limit, minQueryCount int, since time.Time) (domain.Suggestions, error)
+	RecordQuery(query string) error
+}
+
+type Media interface {
+	CollectGarbage(dryRun bool) ([]string, error)
+	ResetData() error
+}
+
+type Schema interface {
+	Version() (int, error)
+	LatestVersion() (int, error)
+	Up() ([]Migration, error)
+	Down() (Migration, bool, error)
+	Status() ([]MigrationStatus, error)
+}
+
 type Repository struct {
 	Authorization
 	Category
 	Product
+	Attribute
+	ProductImage
+	Variant
 	Profile
+	Collection
+	Review
+	Wishlist
+	Related
+	Currency
+	Tax
+	Promo
+	Sale
+	Import
+	Search
+	Media
+	Schema
 }
 
 func NewRepository(db *sqlx.DB, s *storage.Storage) *Repository {
@@ -54,6 +217,21 @@ func NewRepository(db *sqlx.DB, s *storage.Storage) *Repository {
 		Authorization: newAuthPostgres(db),
 		Category:      newCategoryPostgres(db, s),
 		Product:       newProductPostgres(db, s),
+		Attribute:     newAttributePostgres(db),
+		ProductImage:  newProductImagePostgres(db, s),
+		Variant:       newVariantPostgres(db, s),
 		Profile:       newProfilePostgres(db, s),
+		Collection:    newCollectionPostgres(db),
+		Review:        newReviewPostgres(db),
+		Wishlist:      newWishlistPostgres(db),
+		Related:       newRelatedPostgres(db),
+		Currency:      newCurrencyPostgres(db),
+		Tax:           newTaxPostgres(db),
+		Promo:         newPromoPostgres(db),
+		Sale:          newSalePostgres(db),
+		Import:        newImportPostgres(db, s),
+		Search:        newSearchPostgres(db),
+		Media:         newMediaPostgres(db, s),
+		Schema:        NewMigrator(db, schema.Migrations),
 	}
 }
@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+type ReviewPostgres struct {
+	db *sqlx.DB
+}
+
+func newReviewPostgres(db *sqlx.DB) *ReviewPostgres {
+	return &ReviewPostgres{db}
+}
+
+const reviewColumns = "r.id, r.product_id, r.user_id, u.name AS user_name, r.rating, r.text, r.status, r.created_at, r.updated_at, r.moderated_at"
+
+// reportCountColumn counts the reports of a review, they are cleared when it
+// is approved.
+var reportCountColumn = fmt.Sprintf("(SELECT COUNT(*) FROM %s rr WHERE rr.review_id = r.id) AS report_count", reviewReportsTable)
+
+// reviewRow is a review listing row.
+type reviewRow struct {
+	domain.Review
+	SortKey string `db:"sort_key"`
+}
+
+func (r reviewRow) position() (string, int) {
+	return r.SortKey, r.Id
+}
+
+func reviewsOf(rows []reviewRow) []domain.Review {
+	reviews := make([]domain.Review, len(rows))
+	for i, row := range rows {
+		reviews[i] = row.Review
+	}
+	return reviews
+}
+
+// reviewListing returns the page query of a review listing, newest first.
+func reviewListing(c *queryConditions) pageQuery {
+	return pageQuery{
+		columns: reviewColumns,
+		from:    reviewsTable + " r INNER JOIN " + usersTable + " u ON u.id = r.user_id",
+		where:   c,
+		id:      "r.id",
+		desc:    true,
+	}
+}
+
+// GetProductReviews returns the approved reviews of a product.
+func (r *ReviewPostgres) GetProductReviews(productId int, page domain.PageRequest) ([]domain.Review, domain.Pagination, error) {
+	c := &queryConditions{}
+	c.add("r.product_id=" + c.arg(productId))
+	c.add("r.status=" + c.arg(domain.ReviewStatusApproved))
+
+	rows, pagination, err := selectPage[reviewRow](r.db, reviewListing(c), page)
+	return reviewsOf(rows), pagination, err
+}
+
+func (r *ReviewPostgres) GetUserReviews(userId int, page domain.PageRequest) ([]domain.Review, domain.Pagination, error) {
+	c := &queryConditions{}
+	c.add("r.user_id=" + c.arg(userId))
+
+	rows, pagination, err := selectPage[reviewRow](r.db, reviewListing(c), page)
+	return reviewsOf(rows), pagination, err
+}
+
+// AdminGetAll returns the reviews of any status, oldest first so the
+// moderation queue is worked through in order, with their report counts.
+func (r *ReviewPostgres) AdminGetAll(page domain.PageRequest, filter domain.AdminReviewFilterParams) ([]domain.Review, domain.Pagination, error) {
+	c := &queryConditions{}
+	if filter.Status != "" {
+		c.add("r.status=" + c.arg(filter.Status))
+	}
+	if filter.ProductId != 0 {
+		c.add("r.product_id=" + c.arg(filter.ProductId))
+	}
+	if filter.Reported {
+		c.add(fmt.Sprintf("EXISTS (SELECT 1 FROM %s rr WHERE rr.review_id = r.id)", reviewReportsTable))
+	}
+
+	q := reviewListing(c)
+	q.columns += ", " + reportCountColumn
+	q.desc = false
+
+	rows, pagination, err := selectPage[reviewRow](r.db, q, page)
+	return reviewsOf(rows), pagination, err
+}
+
+// GetReview returns a review of any status.
+func (r *ReviewPostgres) GetReview(id int) (domain.Review, error) {
+	var review domain.Review
+	query := fmt.Sprintf("SELECT %s FROM %s r INNER JOIN %s u ON u.id = r.user_id WHERE r.id=$1", reviewColumns, reviewsTable, usersTable)
+
+	err := r.db.Get(&review, query, id)
+	if err == sql.ErrNoRows {
+		return review, errors_handler.NoRows()
+	}
+	return review, err
+}
+
+// HasPurchased reports whether the user has ordered the product.
+func (r *ReviewPostgres) HasPurchased(userId, productId int) (bool, error) {
+	var purchased bool
+	query := fmt.Sprintf(`SELECT EXISTS (
+		SELECT 1 FROM %s op INNER JOIN %s o ON o.id = op.order_id WHERE o.user_id=$1 AND op.product_id=$2
+	)`, orderedProductsTable, ordersTable)
+
+	err := r.db.Get(&purchased, query, userId, productId)
+	return purchased, err
+}
+
+func (r *ReviewPostgres) CreateReview(userId int, input domain.CreateReviewInput, status string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (product_id, user_id, rating, text, status) VALUES ($1, $2, $3, $4, $5) RETURNING id", reviewsTable)
+	if err := tx.QueryRow(query, input.ProductId, userId, input.Rating, input.Text, status).Scan(&id); err != nil {
+		return 0, reviewError(err)
+	}
+
+	if err := refreshProductRating(tx, input.ProductId); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// UpdateReview updates a review of the user, the reviews of other users are
+// not found. The status is set when the text changes, a new text is moderated
+// again and its reports no longer apply.
+func (r *ReviewPostgres) UpdateReview(userId, reviewId int, input domain.UpdateReviewInput, status *string) error {
+	setValues := []string{"updated_at=now()"}
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.Rating != nil {
+		setValues = append(setValues, fmt.Sprintf("rating=$%d", argId))
+		args = append(args, *input.Rating)
+		argId++
+	}
+
+	if input.Text != nil {
+		setValues = append(setValues, fmt.Sprintf("text=$%d", argId))
+		args = append(args, *input.Text)
+		argId++
+	}
+
+	if status != nil {
+		setValues = append(setValues, fmt.Sprintf("status=$%d", argId), "moderated_at=NULL")
+		args = append(args, *status)
+		argId++
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var productId int
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id=$%d AND user_id=$%d RETURNING product_id",
+		reviewsTable, strings.Join(setValues, ", "), argId, argId+1)
+	args = append(args, reviewId, userId)
+
+	if err := tx.QueryRow(query, args...).Scan(&productId); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	if status != nil {
+		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE review_id=$1", reviewReportsTable)
+		if _, err := tx.Exec(deleteQuery, reviewId); err != nil {
+			return err
+		}
+	}
+
+	if input.Rating != nil || status != nil {
+		if err := refreshProductRating(tx, productId); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteReview deletes a review of the user, the reviews of other users are
+// not found.
+func (r *ReviewPostgres) DeleteReview(userId, reviewId int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var productId int
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1 AND user_id=$2 RETURNING product_id", reviewsTable)
+	if err := tx.QueryRow(query, reviewId, userId).Scan(&productId); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	if err := refreshProductRating(tx, productId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetReviewStatus approves or rejects a review. Approving it clears its
+// reports.
+func (r *ReviewPostgres) SetReviewStatus(reviewId int, status string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var productId int
+	query := fmt.Sprintf("UPDATE %s SET status=$1, moderated_at=now() WHERE id=$2 RETURNING product_id", reviewsTable)
+	if err := tx.QueryRow(query, status, reviewId).Scan(&productId); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	if status == domain.ReviewStatusApproved {
+		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE review_id=$1", reviewReportsTable)
+		if _, err := tx.Exec(deleteQuery, reviewId); err != nil {
+			return err
+		}
+	}
+
+	if err := refreshProductRating(tx, productId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReportReview records the report of a review by a user. Once the review is
+// reported by threshold users it is taken down, back to pending, until an
+// admin moderates it. It reports whether the review was taken down.
+func (r *ReviewPostgres) ReportReview(userId, reviewId int, input domain.ReportReviewInput, threshold int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var productId int
+	var status string
+	query := fmt.Sprintf("SELECT product_id, status FROM %s WHERE id=$1 FOR UPDATE", reviewsTable)
+	if err := tx.QueryRow(query, reviewId).Scan(&productId, &status); err != nil {
+		if err == sql.ErrNoRows {
+			return false, errors_handler.NoRows()
+		}
+		return false, err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (review_id, user_id, reason) VALUES ($1, $2, $3)", reviewReportsTable)
+	if _, err := tx.Exec(insertQuery, reviewId, userId, input.Reason); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return false, errors_handler.AlreadyExists("report")
+		}
+		return false, err
+	}
+
+	var reports int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE review_id=$1", reviewReportsTable)
+	if err := tx.QueryRow(countQuery, reviewId).Scan(&reports); err != nil {
+		return false, err
+	}
+
+	takenDown := status == domain.ReviewStatusApproved && reports >= threshold
+	if takenDown {
+		updateQuery := fmt.Sprintf("UPDATE %s SET status=$1 WHERE id=$2", reviewsTable)
+		if _, err := tx.Exec(updateQuery, domain.ReviewStatusPending, reviewId); err != nil {
+			return false, err
+		}
+		if err := refreshProductRating(tx, productId); err != nil {
+			return false, err
+		}
+	}
+
+	return takenDown, tx.Commit()
+}
+
+// refreshProductRating recomputes the rating of a product, kept on the
+// products table so listings can show and sort by it, from its approved
+// reviews.
+func refreshProductRating(tx *sql.Tx, productId int) error {
+	query := fmt.Sprintf(`UPDATE %s SET
+		rating_average=COALESCE((SELECT ROUND(AVG(rating), 2) FROM %s WHERE product_id=$1 AND status=$2), 0),
+		rating_count=(SELECT COUNT(*) FROM %s WHERE product_id=$1 AND status=$2)
+		WHERE id=$1`, productsTable, reviewsTable, reviewsTable)
+
+	_, err := tx.Exec(query, productId, domain.ReviewStatusApproved)
+	return err
+}
+
+// reviewError translates the constraint violations of the reviews table, a
+// user reviews a product once.
+func reviewError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		return errors_handler.AlreadyExists("review")
+	case "foreign_key_violation":
+		return errors_handler.ForeignKeyViolation()
+	}
+	return err
+}
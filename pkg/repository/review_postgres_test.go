@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProductReviews(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newReviewPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	date := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	columns := []string{"id", "product_id", "user_id", "user_name", "rating", "text", "status", "created_at", "updated_at", "moderated_at", "sort_key"}
+
+	tests := []struct {
+		name           string
+		mock           func()
+		productId      int
+		page           domain.PageRequest
+		want           []domain.Review
+		wantPagination domain.Pagination
+		wantErr        bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(4, 1, 2, "user 2", 5, "great", "approved", date, date, nil, "").
+					AddRow(2, 1, 3, "user 3", 3, "", "approved", date, date, date, "")
+				mock.ExpectQuery("SELECT COUNT(.+) FROM reviews r INNER JOIN users u ON u.id = r.user_id WHERE r.product_id=\\$1 AND r.status=\\$2").
+					WithArgs(1, "approved").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("SELECT r.id, r.product_id, r.user_id, u.name AS user_name, (.+) FROM reviews r (.+) WHERE r.product_id=\\$1 AND r.status=\\$2 ORDER BY r.id DESC LIMIT (.+) OFFSET").
+					WithArgs(1, "approved", 11, 0).WillReturnRows(rows)
+			},
+			productId: 1,
+			page:      domain.PageRequest{Limit: 10},
+			want: []domain.Review{
+				{Id: 4, ProductId: 1, UserId: 2, UserName: "user 2", Rating: 5, Text: "great", Status: "approved", CreatedAt: date, UpdatedAt: date},
+				{Id: 2, ProductId: 1, UserId: 3, UserName: "user 3", Rating: 3, Status: "approved", CreatedAt: date, UpdatedAt: date, ModeratedAt: &date},
+			},
+			wantPagination: domain.Pagination{Total: 2, Page: 1, PageSize: 10},
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM reviews r").
+					WithArgs(1, "approved").WillReturnError(errors.New("count error"))
+			},
+			productId: 1,
+			page:      domain.PageRequest{Limit: 10},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, pagination, err := r.GetProductReviews(tt.productId, tt.page)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantPagination, pagination)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newReviewPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	input := domain.CreateReviewInput{ProductId: 1, Rating: 4, Text: "review text"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO reviews \\(product_id, user_id, rating, text, status\\) VALUES (.+) RETURNING id").
+					WithArgs(1, 2, 4, "review text", "approved").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectExec("UPDATE products SET rating_average=(.+) FROM reviews WHERE product_id=\\$1 AND status=\\$2(.+), rating_count=(.+) WHERE id=\\$1").
+					WithArgs(1, "approved").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: 3,
+		},
+		{
+			name: "Already reviewed",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO reviews").
+					WithArgs(1, 2, 4, "review text", "approved").WillReturnError(&pq.Error{Code: "23505"})
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errType: errors_handler.TypeAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.CreateReview(2, input, domain.ReviewStatusApproved)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newReviewPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	type args struct {
+		userId   int
+		reviewId int
+		input    domain.UpdateReviewInput
+		status   *string
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok text",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE reviews SET updated_at=now\\(\\), rating=\\$1, text=\\$2, status=\\$3, moderated_at=NULL WHERE id=\\$4 AND user_id=\\$5 RETURNING product_id").
+					WithArgs(2, "new text", "pending", 3, 2).WillReturnRows(sqlmock.NewRows([]string{"product_id"}).AddRow(1))
+				mock.ExpectExec("DELETE FROM review_reports WHERE review_id=\\$1").
+					WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE products SET rating_average").
+					WithArgs(1, "approved").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			input: args{2, 3, domain.UpdateReviewInput{Rating: intPointer(2), Text: stringPointer("new text")}, stringPointer("pending")},
+		},
+		{
+			name: "Ok rating",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE reviews SET updated_at=now\\(\\), rating=\\$1 WHERE id=\\$2 AND user_id=\\$3").
+					WithArgs(4, 3, 2).WillReturnRows(sqlmock.NewRows([]string{"product_id"}).AddRow(1))
+				mock.ExpectExec("UPDATE products SET rating_average").
+					WithArgs(1, "approved").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			input: args{2, 3, domain.UpdateReviewInput{Rating: intPointer(4)}, nil},
+		},
+		{
+			name: "Review of another user",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE reviews SET").
+					WithArgs(5, 3, 4).WillReturnRows(sqlmock.NewRows([]string{"product_id"}))
+				mock.ExpectRollback()
+			},
+			input:   args{4, 3, domain.UpdateReviewInput{Rating: intPointer(5)}, nil},
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.UpdateReview(tt.input.userId, tt.input.reviewId, tt.input.input, tt.input.status)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReportReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newReviewPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	input := domain.ReportReviewInput{Reason: "spam"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT product_id, status FROM reviews WHERE id=\\$1 FOR UPDATE").
+					WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"product_id", "status"}).AddRow(1, "approved"))
+				mock.ExpectExec("INSERT INTO review_reports \\(review_id, user_id, reason\\)").
+					WithArgs(3, 2, "spam").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT COUNT(.+) FROM review_reports WHERE review_id=\\$1").
+					WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Taken down",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT product_id, status FROM reviews").
+					WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"product_id", "status"}).AddRow(1, "approved"))
+				mock.ExpectExec("INSERT INTO review_reports").
+					WithArgs(3, 2, "spam").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT COUNT(.+) FROM review_reports").
+					WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectExec("UPDATE reviews SET status=\\$1 WHERE id=\\$2").
+					WithArgs("pending", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE products SET rating_average").
+					WithArgs(1, "approved").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: true,
+		},
+		{
+			name: "Already reported",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT product_id, status FROM reviews").
+					WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"product_id", "status"}).AddRow(1, "approved"))
+				mock.ExpectExec("INSERT INTO review_reports").
+					WithArgs(3, 2, "spam").WillReturnError(&pq.Error{Code: "23505"})
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errType: errors_handler.TypeAlreadyExists,
+		},
+		{
+			name: "Review not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT product_id, status FROM reviews").
+					WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"product_id", "status"}))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.ReportReview(2, 3, input, 3)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
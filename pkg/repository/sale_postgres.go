@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+const priceScheduleColumns = "s.id, s.name, s.product_id, s.category_id, s.percent, s.starts_at, s.ends_at, s.status, s.created_at"
+
+type SalePostgres struct {
+	db *sqlx.DB
+}
+
+func newSalePostgres(db *sqlx.DB) *SalePostgres {
+	return &SalePostgres{db}
+}
+
+// GetAll returns the price schedules, the last starting first.
+func (r *SalePostgres) GetAll(page domain.PageRequest, filter domain.PriceScheduleFilterParams) ([]domain.PriceSchedule, domain.Pagination, error) {
+	c := &queryConditions{}
+	if filter.Status != "" {
+		c.add("s.status=" + c.arg(filter.Status))
+	}
+	if filter.ProductId != 0 {
+		c.add("s.product_id=" + c.arg(filter.ProductId))
+	}
+	if filter.CategoryId != 0 {
+		c.add("s.category_id=" + c.arg(filter.CategoryId))
+	}
+
+	rows, pagination, err := selectPage[priceScheduleRow](r.db, pageQuery{
+		columns: priceScheduleColumns,
+		from:    priceSchedulesTable + " s",
+		where:   c,
+		key:     "s.starts_at",
+		id:      "s.id",
+		desc:    true,
+	}, page)
+
+	schedules := make([]domain.PriceSchedule, len(rows))
+	for i, row := range rows {
+		schedules[i] = row.PriceSchedule
+	}
+	return schedules, pagination, err
+}
+
+// priceScheduleRow is a price schedule listing row.
+type priceScheduleRow struct {
+	domain.PriceSchedule
+	SortKey string `db:"sort_key"`
+}
+
+func (r priceScheduleRow) position() (string, int) {
+	return r.SortKey, r.Id
+}
+
+func (r *SalePostgres) GetById(id int) (domain.PriceSchedule, error) {
+	var schedule domain.PriceSchedule
+	query := fmt.Sprintf("SELECT %s FROM %s s WHERE s.id=$1", priceScheduleColumns, priceSchedulesTable)
+	err := r.db.Get(&schedule, query, id)
+	if err == sql.ErrNoRows {
+		return schedule, errors_handler.NoRows()
+	}
+	return schedule, err
+}
+
+func (r *SalePostgres) CreateSchedule(input domain.PriceScheduleInput) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (name, product_id, category_id, percent, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, priceSchedulesTable)
+	err := r.db.QueryRow(query, input.Name, input.ProductId, input.CategoryId, input.Percent, input.StartsAt, input.EndsAt).Scan(&id)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+		return 0, errors_handler.ForeignKeyViolation()
+	}
+	return id, err
+}
+
+// CancelSchedule cancels a price schedule, the prices of a started sale are
+// restored right away and its products join the other active sales they are
+// in.
+func (r *SalePostgres) CancelSchedule(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	query := fmt.Sprintf("SELECT status FROM %s WHERE id=$1 FOR UPDATE", priceSchedulesTable)
+	if err := tx.QueryRow(query, id).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	switch status {
+	case domain.PriceScheduleEnded:
+		return errors_handler.BadRequest("price schedule has already ended")
+	case domain.PriceScheduleCancelled:
+		return errors_handler.BadRequest("price schedule is already cancelled")
+	case domain.PriceScheduleActive:
+		if err := endSale(tx, "sale_id=$1", id); err != nil {
+			return err
+		}
+	}
+
+	updateQuery := fmt.Sprintf("UPDATE %s SET status=$1 WHERE id=$2", priceSchedulesTable)
+	if _, err := tx.Exec(updateQuery, domain.PriceScheduleCancelled, id); err != nil {
+		return err
+	}
+	if status == domain.PriceScheduleActive {
+		if err := resumeSales(tx); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Run ends the sales due to end at now and starts the sales due to start, in
+// a single transaction. The schedules are locked, so concurrent runs wait for
+// each other. The sales ending are ended first, and their products join the
+// active sales overlapping them before the sales starting. Schedules whose
+// whole period has passed are ended without being started.
+func (r *SalePostgres) Run(now time.Time) (domain.PriceScheduleRun, error) {
+	var run domain.PriceScheduleRun
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return run, err
+	}
+	defer tx.Rollback()
+
+	endingQuery := fmt.Sprintf("SELECT id FROM %s WHERE status=$1 AND ends_at <= $2 ORDER BY id FOR UPDATE", priceSchedulesTable)
+	ending, err := selectIds(tx, endingQuery, domain.PriceScheduleActive, now)
+	if err != nil {
+		return run, err
+	}
+	for _, id := range ending {
+		if err := endSale(tx, "sale_id=$1", id); err != nil {
+			return run, err
+		}
+	}
+	// Also restore the products of the sales deleted with their category,
+	// which are left with their sale price.
+	if err := endSale(tx, "sale_id IS NULL"); err != nil {
+		return run, err
+	}
+
+	endQuery := fmt.Sprintf("UPDATE %s SET status=$1 WHERE status IN ($2, $3) AND ends_at <= $4", priceSchedulesTable)
+	if _, err := tx.Exec(endQuery, domain.PriceScheduleEnded, domain.PriceScheduleActive, domain.PriceScheduleScheduled, now); err != nil {
+		return run, err
+	}
+	run.Ended = len(ending)
+
+	if err := resumeSales(tx); err != nil {
+		return run, err
+	}
+
+	startingQuery := fmt.Sprintf(`SELECT id, product_id, category_id, percent FROM %s
+		WHERE status=$1 AND starts_at <= $2 ORDER BY starts_at, id FOR UPDATE`, priceSchedulesTable)
+	starting, err := selectSchedules(tx, startingQuery, domain.PriceScheduleScheduled, now)
+	if err != nil {
+		return run, err
+	}
+
+	startQuery := fmt.Sprintf("UPDATE %s SET status=$1 WHERE id=$2", priceSchedulesTable)
+	for _, schedule := range starting {
+		if err := startSale(tx, schedule); err != nil {
+			return run, err
+		}
+		if _, err := tx.Exec(startQuery, domain.PriceScheduleActive, schedule.Id); err != nil {
+			return run, err
+		}
+	}
+	run.Started = len(starting)
+
+	return run, tx.Commit()
+}
+
+// resumeSales applies the active sales again, to their products not in a
+// sale: the products of a sale just ended, which were left out of the sales
+// overlapping it, or the products added to a category on sale. The sale
+// started first takes a product first.
+func resumeSales(tx *sql.Tx) error {
+	query := fmt.Sprintf(`SELECT id, product_id, category_id, percent FROM %s
+		WHERE status=$1 ORDER BY starts_at, id FOR UPDATE`, priceSchedulesTable)
+	active, err := selectSchedules(tx, query, domain.PriceScheduleActive)
+	if err != nil {
+		return err
+	}
+	for _, schedule := range active {
+		if err := startSale(tx, schedule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectSchedules returns the id, target and percent of the price schedules
+// selected by a query.
+func selectSchedules(tx *sql.Tx, query string, args ...interface{}) ([]domain.PriceSchedule, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []domain.PriceSchedule
+	for rows.Next() {
+		var schedule domain.PriceSchedule
+		if err := rows.Scan(&schedule.Id, &schedule.ProductId, &schedule.CategoryId, &schedule.Percent); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// startSale lowers the prices of the products of a sale which are not in
+// another sale, and of their variants, keeping their regular price. The
+// undiscounted price is left as it is, the sale price being lower than the
+// regular one.
+func startSale(tx *sql.Tx, schedule domain.PriceSchedule) error {
+	condition := "p.id = $3"
+	target := schedule.ProductId
+	if schedule.CategoryId != nil {
+		condition = inCategories("= $3")
+		target = schedule.CategoryId
+	}
+
+	productsQuery := fmt.Sprintf(`UPDATE %s p SET
+		sale_id=$1,
+		regular_price=p.price,
+		sale_price=ROUND(p.price * (100 - $2) / 100, 2),
+		price=ROUND(p.price * (100 - $2) / 100, 2)
+		WHERE p.regular_price IS NULL AND %s`, productsTable, condition)
+	if _, err := tx.Exec(productsQuery, schedule.Id, schedule.Percent, *target); err != nil {
+		return err
+	}
+
+	variantsQuery := fmt.Sprintf(`UPDATE %s v SET
+		sale_id=$1,
+		regular_price=v.price,
+		sale_price=ROUND(v.price * (100 - $2) / 100, 2),
+		price=ROUND(v.price * (100 - $2) / 100, 2)
+		WHERE v.regular_price IS NULL AND v.product_id IN (SELECT id FROM %s WHERE sale_id=$1)`, productVariantsTable, productsTable)
+	_, err := tx.Exec(variantsQuery, schedule.Id, schedule.Percent)
+	return err
+}
+
+// endSale restores the regular price of the products, and variants, in a
+// sale matching the condition. A price changed during the sale is kept.
+func endSale(tx *sql.Tx, condition string, args ...interface{}) error {
+	for _, table := range []string{productVariantsTable, productsTable} {
+		query := fmt.Sprintf(`UPDATE %s SET
+			price=CASE WHEN price = sale_price THEN regular_price ELSE price END,
+			undiscounted_price=GREATEST(undiscounted_price, CASE WHEN price = sale_price THEN regular_price ELSE price END),
+			sale_id=NULL,
+			regular_price=NULL,
+			sale_price=NULL
+			WHERE regular_price IS NOT NULL AND %s`, table, condition)
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPriceSchedules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newSalePostgres(sqlx.NewDb(db, "sqlmock"))
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM price_schedules WHERE status=\\$1 AND ends_at <= \\$2 ORDER BY id FOR UPDATE").
+		WithArgs(domain.PriceScheduleActive, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectExec("UPDATE product_variants SET (.+) WHERE regular_price IS NOT NULL AND sale_id=\\$1").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE products SET (.+) WHERE regular_price IS NOT NULL AND sale_id=\\$1").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE product_variants SET (.+) WHERE regular_price IS NOT NULL AND sale_id IS NULL").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE products SET (.+) WHERE regular_price IS NOT NULL AND sale_id IS NULL").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE price_schedules SET status=\\$1 WHERE status IN \\(\\$2, \\$3\\) AND ends_at <= \\$4").
+		WithArgs(domain.PriceScheduleEnded, domain.PriceScheduleActive, domain.PriceScheduleScheduled, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, product_id, category_id, percent FROM price_schedules WHERE status=\\$1 ORDER BY starts_at, id FOR UPDATE").
+		WithArgs(domain.PriceScheduleActive).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "category_id", "percent"}))
+	mock.ExpectQuery("SELECT id, product_id, category_id, percent FROM price_schedules (.+) FOR UPDATE").
+		WithArgs(domain.PriceScheduleScheduled, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "category_id", "percent"}).AddRow(5, nil, 3, 20))
+	mock.ExpectExec("UPDATE products p SET (.+) WHERE p.regular_price IS NULL AND \\(p.category_id = \\$3 OR EXISTS (.+)\\)").
+		WithArgs(5, 20, 3).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("UPDATE product_variants v SET (.+) WHERE v.regular_price IS NULL AND v.product_id IN \\(SELECT id FROM products WHERE sale_id=\\$1\\)").
+		WithArgs(5, 20).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE price_schedules SET status=\\$1 WHERE id=\\$2").
+		WithArgs(domain.PriceScheduleActive, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	run, err := r.Run(now)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PriceScheduleRun{Started: 1, Ended: 1}, run)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunOverlappingPriceSchedules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newSalePostgres(sqlx.NewDb(db, "sqlmock"))
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// The sale 2 of the product 1 ends while the sale 4 of its category,
+	// started later and left without it, goes on: the product joins it.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM price_schedules WHERE status=\\$1 AND ends_at <= \\$2 ORDER BY id FOR UPDATE").
+		WithArgs(domain.PriceScheduleActive, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectExec("UPDATE product_variants SET (.+) WHERE regular_price IS NOT NULL AND sale_id=\\$1").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE products SET (.+) WHERE regular_price IS NOT NULL AND sale_id=\\$1").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE product_variants SET (.+) WHERE regular_price IS NOT NULL AND sale_id IS NULL").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE products SET (.+) WHERE regular_price IS NOT NULL AND sale_id IS NULL").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE price_schedules SET status=\\$1 WHERE status IN \\(\\$2, \\$3\\) AND ends_at <= \\$4").
+		WithArgs(domain.PriceScheduleEnded, domain.PriceScheduleActive, domain.PriceScheduleScheduled, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, product_id, category_id, percent FROM price_schedules WHERE status=\\$1 ORDER BY starts_at, id FOR UPDATE").
+		WithArgs(domain.PriceScheduleActive).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "category_id", "percent"}).AddRow(4, nil, 3, 10))
+	mock.ExpectExec("UPDATE products p SET (.+) WHERE p.regular_price IS NULL AND \\(p.category_id = \\$3 OR EXISTS (.+)\\)").
+		WithArgs(4, 10, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE product_variants v SET (.+) WHERE v.regular_price IS NULL AND v.product_id IN \\(SELECT id FROM products WHERE sale_id=\\$1\\)").
+		WithArgs(4, 10).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT id, product_id, category_id, percent FROM price_schedules (.+) FOR UPDATE").
+		WithArgs(domain.PriceScheduleScheduled, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "category_id", "percent"}))
+	mock.ExpectCommit()
+
+	run, err := r.Run(now)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PriceScheduleRun{Started: 0, Ended: 1}, run)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelPriceSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newSalePostgres(sqlx.NewDb(db, "sqlmock"))
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Active",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM price_schedules WHERE id=\\$1 FOR UPDATE").
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.PriceScheduleActive))
+				mock.ExpectExec("UPDATE product_variants SET (.+) WHERE regular_price IS NOT NULL AND sale_id=\\$1").
+					WithArgs(5).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("UPDATE products SET (.+) WHERE regular_price IS NOT NULL AND sale_id=\\$1").
+					WithArgs(5).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec("UPDATE price_schedules SET status=\\$1 WHERE id=\\$2").
+					WithArgs(domain.PriceScheduleCancelled, 5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT id, product_id, category_id, percent FROM price_schedules WHERE status=\\$1").
+					WithArgs(domain.PriceScheduleActive).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "category_id", "percent"}).AddRow(6, nil, 3, 10))
+				mock.ExpectExec("UPDATE products p SET (.+) WHERE p.regular_price IS NULL AND \\(p.category_id = \\$3 OR EXISTS (.+)\\)").
+					WithArgs(6, 10, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE product_variants v SET").
+					WithArgs(6, 10).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Scheduled",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM price_schedules").
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.PriceScheduleScheduled))
+				mock.ExpectExec("UPDATE price_schedules SET status=\\$1 WHERE id=\\$2").
+					WithArgs(domain.PriceScheduleCancelled, 5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Already ended",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM price_schedules").
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.PriceScheduleEnded))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errType: errors_handler.TypeBadRequest,
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM price_schedules").
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.CancelSchedule(5)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
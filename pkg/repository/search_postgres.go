@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+)
+
+type SearchPostgres struct {
+	db *sqlx.DB
+}
+
+func newSearchPostgres(db *sqlx.DB) *SearchPostgres {
+	return &SearchPostgres{db}
+}
+
+// Suggest returns the available products and categories with a name
+// starting with the prefix, or with a word of it starting with the prefix,
+// and the most popular past searches starting with the prefix. Names
+// starting with the prefix come first, then the shortest ones. Only the
+// searches made at least minQueryCount times, the last one since the given
+// time, are suggested.
+func (r *SearchPostgres) Suggest(prefix string, limit, minQueryCount int, since time.Time) (domain.Suggestions, error) {
+	suggestions := domain.Suggestions{
+		Products:   make([]domain.ProductSuggestion, 0),
+		Categories: make([]domain.CategorySuggestion, 0),
+		Queries:    make([]string, 0),
+	}
+	startsWith := escapeLike(prefix) + "%"
+	wordStartsWith := "% " + startsWith
+
+	query := fmt.Sprintf(`SELECT id, name, image_url FROM %s
+		WHERE available=true AND archived_at IS NULL AND (name ILIKE $1 OR name ILIKE $2)
+		ORDER BY name ILIKE $1 DESC, length(name), name
+		LIMIT $3`, productsTable)
+	if err := r.db.Select(&suggestions.Products, query, startsWith, wordStartsWith, limit); err != nil {
+		return suggestions, err
+	}
+
+	query = fmt.Sprintf(`SELECT id, name FROM %s
+		WHERE available=true AND archived_at IS NULL AND (name ILIKE $1 OR name ILIKE $2)
+		ORDER BY name ILIKE $1 DESC, length(name), name
+		LIMIT $3`, categoriesTables)
+	if err := r.db.Select(&suggestions.Categories, query, startsWith, wordStartsWith, limit); err != nil {
+		return suggestions, err
+	}
+
+	query = fmt.Sprintf(`SELECT query FROM %s
+		WHERE query LIKE $1 AND count >= $2 AND searched_at >= $3
+		ORDER BY count DESC, query
+		LIMIT $4`, searchQueriesTable)
+	err := r.db.Select(&suggestions.Queries, query, startsWith, minQueryCount, since, limit)
+	return suggestions, err
+}
+
+// RecordQuery counts a search. The query is expected in its normalized form.
+func (r *SearchPostgres) RecordQuery(query string) error {
+	insertQuery := fmt.Sprintf(`INSERT INTO %s (query) VALUES ($1)
+		ON CONFLICT (query) DO UPDATE SET count = %[1]s.count + 1, searched_at = now()`, searchQueriesTable)
+
+	_, err := r.db.Exec(insertQuery, query)
+	return err
+}
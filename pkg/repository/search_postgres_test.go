@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newSearchPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	type args struct {
+		prefix        string
+		limit         int
+		minQueryCount int
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		want    domain.Suggestions
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				products := sqlmock.NewRows([]string{"id", "name", "image_url"}).
+					AddRow(1, "Cotton shirt", "https://test.back.com/products/1/img1.png").
+					AddRow(2, "Organic cotton socks", "https://test.back.com/products/2/img1.png")
+				mock.ExpectQuery("SELECT id, name, image_url FROM products").
+					WithArgs("cot%", "% cot%", 5).WillReturnRows(products)
+				categories := sqlmock.NewRows([]string{"id", "name"})
+				mock.ExpectQuery("SELECT id, name FROM categories").
+					WithArgs("cot%", "% cot%", 5).WillReturnRows(categories)
+				queries := sqlmock.NewRows([]string{"query"}).AddRow("cotton").AddRow("cotton shirt")
+				mock.ExpectQuery("SELECT query FROM search_queries WHERE query LIKE (.+) ORDER BY count DESC").
+					WithArgs("cot%", 5, since, 5).WillReturnRows(queries)
+			},
+			input: args{"cot", 5, 5},
+			want: domain.Suggestions{
+				Products: []domain.ProductSuggestion{
+					{Id: 1, Name: "Cotton shirt", ImageUrl: "https://test.back.com/products/1/img1.png"},
+					{Id: 2, Name: "Organic cotton socks", ImageUrl: "https://test.back.com/products/2/img1.png"},
+				},
+				Categories: []domain.CategorySuggestion{},
+				Queries:    []string{"cotton", "cotton shirt"},
+			},
+		},
+		{
+			name: "Prefix with wildcards",
+			mock: func() {
+				mock.ExpectQuery("SELECT id, name, image_url FROM products").
+					WithArgs("50\\%%", "% 50\\%%", 3).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "image_url"}))
+				mock.ExpectQuery("SELECT id, name FROM categories").
+					WithArgs("50\\%%", "% 50\\%%", 3).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+				mock.ExpectQuery("SELECT query FROM search_queries").
+					WithArgs("50\\%%", 5, since, 3).WillReturnRows(sqlmock.NewRows([]string{"query"}))
+			},
+			input: args{"50%", 3, 5},
+			want: domain.Suggestions{
+				Products:   []domain.ProductSuggestion{},
+				Categories: []domain.CategorySuggestion{},
+				Queries:    []string{},
+			},
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectQuery("SELECT id, name, image_url FROM products").
+					WithArgs("cot%", "% cot%", 5).WillReturnError(errors.New("some error"))
+			},
+			input:   args{"cot", 5, 5},
+			wantErr: true,
+		},
+		{
+			name: "Popular queries only",
+			mock: func() {
+				mock.ExpectQuery("SELECT id, name, image_url FROM products").
+					WithArgs("ali%", "% ali%", 5).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "image_url"}))
+				mock.ExpectQuery("SELECT id, name FROM categories").
+					WithArgs("ali%", "% ali%", 5).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+				mock.ExpectQuery("SELECT query FROM search_queries WHERE query LIKE \\$1 AND count >= \\$2 AND searched_at >= \\$3 ORDER BY count DESC, query LIMIT \\$4").
+					WithArgs("ali%", 10, since, 5).WillReturnRows(sqlmock.NewRows([]string{"query"}).AddRow("alice wonderland"))
+			},
+			input: args{"ali", 5, 10},
+			want: domain.Suggestions{
+				Products:   []domain.ProductSuggestion{},
+				Categories: []domain.CategorySuggestion{},
+				Queries:    []string{"alice wonderland"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Suggest(tt.input.prefix, tt.input.limit, tt.input.minQueryCount, since)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRecordQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newSearchPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec("INSERT INTO search_queries (.+) ON CONFLICT \\(query\\) DO UPDATE SET count = search_queries.count \\+ 1").
+		WithArgs("cotton shirt").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, r.RecordQuery("cotton shirt"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
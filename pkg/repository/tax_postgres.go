@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+type TaxPostgres struct {
+	db *sqlx.DB
+}
+
+func newTaxPostgres(db *sqlx.DB) *TaxPostgres {
+	return &TaxPostgres{db}
+}
+
+func (r *TaxPostgres) GetClasses() ([]domain.TaxClass, error) {
+	classes := make([]domain.TaxClass, 0)
+	query := fmt.Sprintf("SELECT id, name FROM %s ORDER BY name", taxClassesTable)
+	if err := r.db.Select(&classes, query); err != nil {
+		return nil, err
+	}
+
+	var rates []domain.TaxRegionRate
+	ratesQuery := fmt.Sprintf("SELECT tax_class_id, region, rate FROM %s ORDER BY tax_class_id, region", taxRatesTable)
+	if err := r.db.Select(&rates, ratesQuery); err != nil {
+		return nil, err
+	}
+
+	positions := make(map[int]int, len(classes))
+	for i := range classes {
+		classes[i].Rates = make([]domain.TaxRegionRate, 0)
+		positions[classes[i].Id] = i
+	}
+	for _, rate := range rates {
+		if i, ok := positions[rate.TaxClassId]; ok {
+			classes[i].Rates = append(classes[i].Rates, rate)
+		}
+	}
+	return classes, nil
+}
+
+func (r *TaxPostgres) CreateClass(input domain.TaxClassInput) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (name) VALUES ($1) RETURNING id", taxClassesTable)
+	if err := tx.QueryRow(query, input.Name).Scan(&id); err != nil {
+		return 0, taxClassError(err)
+	}
+	if err := insertTaxRates(tx, id, input.Rates); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// UpdateClass renames a tax class and replaces its rates.
+func (r *TaxPostgres) UpdateClass(id int, input domain.TaxClassInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("UPDATE %s SET name=$1 WHERE id=$2", taxClassesTable)
+	result, err := tx.Exec(query, input.Name, id)
+	if err != nil {
+		return taxClassError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return errors_handler.NoRows()
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE tax_class_id=$1", taxRatesTable)
+	if _, err := tx.Exec(deleteQuery, id); err != nil {
+		return err
+	}
+	if err := insertTaxRates(tx, id, input.Rates); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertTaxRates(tx *sql.Tx, taxClassId int, rates []domain.TaxRegionRateInput) error {
+	query := fmt.Sprintf("INSERT INTO %s (tax_class_id, region, rate) VALUES ($1, $2, $3)", taxRatesTable)
+	for _, rate := range rates {
+		if _, err := tx.Exec(query, taxClassId, rate.Region, rate.Rate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteClass deletes a tax class, the products and categories it was
+// assigned to are left without one.
+func (r *TaxPostgres) DeleteClass(id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1", taxClassesTable)
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors_handler.NoRows()
+	}
+	return nil
+}
+
+func (r *TaxPostgres) SetProductTaxClass(productId int, taxClassId *int) error {
+	return r.setTaxClass(productsTable, productId, taxClassId)
+}
+
+func (r *TaxPostgres) SetCategoryTaxClass(categoryId int, taxClassId *int) error {
+	return r.setTaxClass(categoriesTables, categoryId, taxClassId)
+}
+
+func (r *TaxPostgres) setTaxClass(table string, id int, taxClassId *int) error {
+	query := fmt.Sprintf("UPDATE %s SET tax_class_id=$1 WHERE id=$2", table)
+
+	result, err := r.db.Exec(query, taxClassId, id)
+	if err != nil {
+		return taxClassError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return errors_handler.NoRows()
+	}
+	return nil
+}
+
+// taxClassError translates the constraint violations of the tax tables.
+func taxClassError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		return errors_handler.AlreadyExists("tax class")
+	case "foreign_key_violation":
+		return errors_handler.ForeignKeyViolation()
+	}
+	return err
+}
+
+// orderTaxRates returns the tax rate of each ordered product in a region: the
+// rate of the tax class of the product, or of its primary category, for the
+// most specific of the regions. Products without a class or a rate for the
+// region are missing, they are not taxed.
+func orderTaxRates(tx *sql.Tx, region string, products []domain.CreateOrderInputProduct) (map[int]domain.TaxRate, error) {
+	ids := make([]int64, len(products))
+	for i, product := range products {
+		ids[i] = int64(product.Id)
+	}
+
+	query := fmt.Sprintf(`SELECT p.id, tr.rate FROM %s p
+		LEFT JOIN %s c ON c.id = p.category_id
+		INNER JOIN LATERAL (
+			SELECT rate FROM %s
+			WHERE tax_class_id = COALESCE(p.tax_class_id, c.tax_class_id) AND region = ANY($2::text[])
+			ORDER BY array_position($2::text[], region::text) LIMIT 1
+		) tr ON true
+		WHERE p.id = ANY($1)`, productsTable, categoriesTables, taxRatesTable)
+
+	rows, err := tx.Query(query, pq.Array(ids), pq.Array(domain.TaxRegions(region)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make(map[int]domain.TaxRate)
+	for rows.Next() {
+		var productId int
+		var rate domain.TaxRate
+		if err := rows.Scan(&productId, &rate); err != nil {
+			return nil, err
+		}
+		rates[productId] = rate
+	}
+	return rates, rows.Err()
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTaxClass(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newTaxPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	input := domain.TaxClassInput{
+		Name: "Standard",
+		Rates: []domain.TaxRegionRateInput{
+			{Region: "FR", Rate: 200000},
+			{Region: "US-CA", Rate: 72500},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO tax_classes \\(name\\) VALUES \\(\\$1\\) RETURNING id").
+					WithArgs("Standard").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectExec("INSERT INTO tax_rates \\(tax_class_id, region, rate\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+					WithArgs(3, "FR", "20.0000").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO tax_rates").
+					WithArgs(3, "US-CA", "7.2500").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: 3,
+		},
+		{
+			name: "Name already exists",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO tax_classes").
+					WithArgs("Standard").
+					WillReturnError(&pq.Error{Code: "23505"})
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errType: errors_handler.TypeAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.CreateClass(input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"mime/multipart"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/storage"
+)
+
+type VariantPostgres struct {
+	db *sqlx.DB
+	s  *storage.Storage
+}
+
+func newVariantPostgres(db *sqlx.DB, s *storage.Storage) *VariantPostgres {
+	return &VariantPostgres{db, s}
+}
+
+func (r *VariantPostgres) GetOptions(productId int) ([]string, error) {
+	options := make([]string, 0)
+
+	query := fmt.Sprintf("SELECT name FROM %s WHERE product_id=$1 ORDER BY position", productOptionsTable)
+
+	err := r.db.Select(&options, query, productId)
+	return options, err
+}
+
+func (r *VariantPostgres) SetOptions(productId int, options []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id=$1 FOR UPDATE", productsTable)
+	if err := tx.QueryRow(query, productId).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE product_id=$1", productOptionsTable)
+	if _, err := tx.Exec(deleteQuery, productId); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (product_id, name, position) VALUES ($1, $2, $3)", productOptionsTable)
+	for position, option := range options {
+		if _, err := tx.Exec(insertQuery, productId, option, position); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *VariantPostgres) GetVariants(productId int, onlyAvailable bool) ([]domain.ProductVariant, error) {
+	variants := make([]domain.ProductVariant, 0)
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE product_id=$1", productVariantsTable)
+	if onlyAvailable {
+		query += " AND available=true"
+	}
+	query += " ORDER BY id"
+
+	err := r.db.Select(&variants, query, productId)
+	return variants, err
+}
+
+func (r *VariantPostgres) GetFilePath(variantId int, fileName string) string {
+	return r.s.Variant.GetFilePath(variantId, fileName)
+}
+
+func (r *VariantPostgres) CreateVariant(productId int, input domain.CreateVariantInput, file multipart.File) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (
+		product_id,
+		sku,
+		options,
+		image_url,
+		available,
+		price,
+		undiscounted_price,
+		stock
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`, productVariantsTable)
+
+	row := tx.QueryRow(query, productId, input.Sku, input.Options, "", input.Available, input.Price, input.UndiscountedPrice, input.Stock)
+	if err := row.Scan(&id); err != nil {
+		return 0, variantError(err)
+	}
+
+	if input.ImgFile != nil && file != nil {
+		url, err := r.s.UploadVariantImage(id, input.ImgFile, file)
+		if err != nil {
+			return 0, err
+		}
+
+		updateQuery := fmt.Sprintf("UPDATE %s SET image_url=$1 WHERE id=$2", productVariantsTable)
+		if _, err := tx.Exec(updateQuery, url, id); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *VariantPostgres) UpdateVariant(productId, variantId int, input domain.UpdateVariantInput, file multipart.File) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.Sku != nil {
+		setValues = append(setValues, fmt.Sprintf("sku=$%d", argId))
+		args = append(args, *input.Sku)
+		argId++
+	}
+
+	if input.Options != nil {
+		setValues = append(setValues, fmt.Sprintf("options=$%d", argId))
+		args = append(args, input.Options)
+		argId++
+	}
+
+	if input.Stock != nil {
+		setValues = append(setValues, fmt.Sprintf("stock=$%d", argId))
+		args = append(args, *input.Stock)
+		argId++
+	}
+
+	if input.Price != nil {
+		setValues = append(setValues, fmt.Sprintf("price=$%d", argId))
+		args = append(args, *input.Price)
+		argId++
+	}
+
+	if input.UndiscountedPrice != nil {
+		setValues = append(setValues, fmt.Sprintf("undiscounted_price=$%d", argId))
+		args = append(args, *input.UndiscountedPrice)
+		argId++
+	}
+
+	if input.Available != nil {
+		setValues = append(setValues, fmt.Sprintf("available=$%d", argId))
+		args = append(args, *input.Available)
+		argId++
+	}
+
+	if input.ImgFile != nil && file != nil {
+		url, err := r.s.UploadVariantImage(variantId, input.ImgFile, file)
+		if err != nil {
+			return err
+		}
+
+		setValues = append(setValues, fmt.Sprintf("image_url=$%d", argId))
+		args = append(args, url)
+		argId++
+	}
+
+	setQuery := strings.Join(setValues, ", ")
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id=$%d AND product_id=$%d RETURNING id", productVariantsTable, setQuery, argId, argId+1)
+	args = append(args, variantId, productId)
+
+	var id int
+	err = tx.QueryRow(query, args...).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return variantError(err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *VariantPostgres) DeleteVariant(productId, variantId int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1 AND product_id=$2 RETURNING id", productVariantsTable)
+	err = tx.QueryRow(query, variantId, productId).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors_handler.NoRows()
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	removeMedia(r.s, storage.VariantsMedia, variantId)
+	return nil
+}
+
+// variantError translates the constraint violations of the variants table.
+func variantError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		return errors_handler.AlreadyExists("variant")
+	case "foreign_key_violation":
+		return errors_handler.ForeignKeyViolation()
+	case "check_violation":
+		return errors_handler.ConstrainViolation("price")
+	}
+	return err
+}
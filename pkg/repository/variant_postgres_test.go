@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVariants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newVariantPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	columns := []string{"id", "product_id", "sku", "options", "price", "undiscounted_price", "image_url", "available", "stock"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   int
+		want    []domain.ProductVariant
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 1, "TS-S", `{"size": "S"}`, 15.0, 20.0, "", true, 5).
+					AddRow(2, 1, "TS-M", `{"size": "M"}`, 15.0, 20.0, "", true, 3)
+				mock.ExpectQuery("SELECT (.+) FROM product_variants WHERE (.+) AND available=true").
+					WithArgs(1).WillReturnRows(rows)
+			},
+			input: 1,
+			want: []domain.ProductVariant{
+				{Id: 1, ProductId: 1, Sku: "TS-S", Options: domain.VariantOptions{"size": "S"}, Price: 1500, UndiscountedPrice: 2000, Available: true, Stock: 5},
+				{Id: 2, ProductId: 1, Sku: "TS-M", Options: domain.VariantOptions{"size": "M"}, Price: 1500, UndiscountedPrice: 2000, Available: true, Stock: 3},
+			},
+		},
+		{
+			name: "No variants",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM product_variants WHERE (.+) AND available=true").
+					WithArgs(2).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			input: 2,
+			want:  []domain.ProductVariant{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetVariants(tt.input, true)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetOptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newVariantPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	type args struct {
+		productId int
+		options   []string
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM products").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec("DELETE FROM product_options").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO product_options").
+					WithArgs(1, "size", 0).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO product_options").
+					WithArgs(1, "colour", 1).WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+			input: args{1, []string{"size", "colour"}},
+		},
+		{
+			name: "Product not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM products").
+					WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			input:   args{2, []string{"size"}},
+			wantErr: true,
+		},
+		{
+			name: "Insert error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM products").
+					WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec("DELETE FROM product_options").
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO product_options").
+					WithArgs(1, "size", 0).WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
+			},
+			input:   args{1, []string{"size"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.SetOptions(tt.input.productId, tt.input.options)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteVariant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	fsTest := storage.NewFileSystemStorage(storage.Config{
+		MediaBaseUrl: "https://test.back.com",
+	})
+	s := storage.NewStorage(fsTest)
+
+	r := newVariantPostgres(sqlx.NewDb(db, "sqlmock"), s)
+
+	type args struct {
+		productId int
+		variantId int
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("DELETE FROM product_variants").
+					WithArgs(3, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectCommit()
+			},
+			input: args{1, 3},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("DELETE FROM product_variants").
+					WithArgs(4, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			input:   args{1, 4},
+			wantErr: true,
+		},
+		{
+			name: "Commit failed",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("DELETE FROM product_variants").
+					WithArgs(5, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				mock.ExpectCommit().WillReturnError(errors.New("commit failed"))
+			},
+			input:   args{1, 5},
+			wantErr: true,
+		},
+	}
+
+	// The media of a variant is only removed once its deletion is committed.
+	mediaFile := "./data/variants/5/image.jpg"
+	if err := os.MkdirAll(filepath.Dir(mediaFile), 0755); err != nil {
+		t.Fatalf("Error creating media: %v", err)
+	}
+	if err := os.WriteFile(mediaFile, []byte("image"), 0644); err != nil {
+		t.Fatalf("Error creating media: %v", err)
+	}
+	defer os.RemoveAll("./data/variants/5")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.DeleteVariant(tt.input.productId, tt.input.variantId)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+	assert.FileExists(t, mediaFile)
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+type WishlistPostgres struct {
+	db *sqlx.DB
+}
+
+func newWishlistPostgres(db *sqlx.DB) *WishlistPostgres {
+	return &WishlistPostgres{db}
+}
+
+// purchasableExpression is true for the products a customer can order now:
+// available, not archived and in stock.
+var purchasableExpression = fmt.Sprintf("(p.available=true AND p.archived_at IS NULL AND %s)", inStockExpression)
+
+// wishlistRow is a wishlist listing row.
+type wishlistRow struct {
+	domain.WishlistItem
+	SortKey string `db:"sort_key"`
+}
+
+func (r wishlistRow) position() (string, int) {
+	return r.SortKey, r.Id
+}
+
+// GetItems returns the wishlist of the user, last added first. Products
+// archived or made unavailable since they were added are kept in it.
+func (r *WishlistPostgres) GetItems(userId int, page domain.PageRequest) ([]domain.WishlistItem, domain.Pagination, error) {
+	c := &queryConditions{}
+	c.add("w.user_id=" + c.arg(userId))
+
+	q := pageQuery{
+		columns: fmt.Sprintf("%s, %s AS in_stock, w.notify, w.created_at AS added_at", productColumns, inStockExpression),
+		from:    wishlistItemsTable + " w INNER JOIN " + productsTable + " p ON p.id = w.product_id",
+		where:   c,
+		key:     "w.created_at",
+		id:      "p.id",
+		desc:    true,
+	}
+
+	rows, pagination, err := selectPage[wishlistRow](r.db, q, page)
+	items := make([]domain.WishlistItem, len(rows))
+	for i, row := range rows {
+		items[i] = row.WishlistItem
+	}
+	return items, pagination, err
+}
+
+// AddItem saves a product of the shop to the wishlist. The price and stock
+// the notifications compare against are taken from the product as it is
+// now, also when the product was already in the wishlist.
+func (r *WishlistPostgres) AddItem(userId int, input domain.AddWishlistItemInput) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, product_id, notify, notified_price, notified_in_stock)
+		SELECT $1, p.id, $3, p.price, %s FROM %s p
+		WHERE p.id=$2 AND p.available=true AND p.archived_at IS NULL
+		ON CONFLICT (user_id, product_id) DO UPDATE SET
+		notify=EXCLUDED.notify, notified_price=EXCLUDED.notified_price, notified_in_stock=EXCLUDED.notified_in_stock`,
+		wishlistItemsTable, purchasableExpression, productsTable)
+
+	result, err := r.db.Exec(query, userId, input.ProductId, input.Notify)
+	if err != nil {
+		return err
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if added == 0 {
+		return errors_handler.NoRows()
+	}
+	return nil
+}
+
+func (r *WishlistPostgres) RemoveItem(userId, productId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id=$1 AND product_id=$2", wishlistItemsTable)
+
+	result, err := r.db.Exec(query, userId, productId)
+	if err != nil {
+		return err
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return errors_handler.NoRows()
+	}
+	return nil
+}
+
+// CreateOrder orders products of the wishlist and removes them from it, in
+// the same transaction. All the ordered products must be in the wishlist.
+func (r *WishlistPostgres) CreateOrder(userId int, checkout domain.Checkout, products []domain.CreateOrderInputProduct) (int, error) {
+	ids := make([]int64, 0, len(products))
+	seen := make(map[int]bool)
+	for _, product := range products {
+		if !seen[product.Id] {
+			seen[product.Id] = true
+			ids = append(ids, int64(product.Id))
+		}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id=$1 AND product_id = ANY($2)", wishlistItemsTable)
+	result, err := tx.Exec(deleteQuery, userId, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if int(removed) != len(ids) {
+		return 0, errors_handler.BadRequest("products: must be in the wishlist")
+	}
+
+	orderId, err := createOrder(tx, userId, checkout, products)
+	if err != nil {
+		return 0, err
+	}
+	return orderId, tx.Commit()
+}
+
+// TakeAlerts returns the price drops and back in stock changes of the
+// wishlisted products the users asked to be notified of, since they were
+// last notified. The compared price and stock are moved to the current ones
+// in the same statement, so each change is returned once and a price that
+// went up or a product that ran out of stock is compared from there on.
+func (r *WishlistPostgres) TakeAlerts() ([]domain.WishlistAlert, error) {
+	alerts := make([]domain.WishlistAlert, 0)
+	query := fmt.Sprintf(`WITH current AS (
+		SELECT w.user_id, w.product_id, p.name, p.price, %s AS purchasable,
+			(p.available=true AND p.archived_at IS NULL) AS listed,
+			w.notified_price, w.notified_in_stock
+		FROM %s w INNER JOIN %s p ON p.id = w.product_id
+		WHERE w.notify=true
+		FOR UPDATE OF w
+	), updated AS (
+		UPDATE %s w SET notified_price=c.price, notified_in_stock=c.purchasable
+		FROM current c
+		WHERE w.user_id = c.user_id AND w.product_id = c.product_id
+		AND (w.notified_price <> c.price OR w.notified_in_stock <> c.purchasable)
+	)
+	SELECT c.user_id, u.name AS user_name, u.email AS user_email, c.product_id, c.name AS product_name,
+		c.price, c.notified_price AS previous_price, (c.purchasable AND NOT c.notified_in_stock) AS back_in_stock
+	FROM current c INNER JOIN %s u ON u.id = c.user_id
+	WHERE (c.listed AND c.price < c.notified_price) OR (c.purchasable AND NOT c.notified_in_stock)
+	ORDER BY c.user_id, c.product_id`,
+		purchasableExpression, wishlistItemsTable, productsTable, wishlistItemsTable, usersTable)
+
+	err := r.db.Select(&alerts, query)
+	return alerts, err
+}
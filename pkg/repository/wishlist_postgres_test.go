@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddWishlistItem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newWishlistPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   domain.AddWishlistItemInput
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO wishlist_items (.+) SELECT (.+) FROM products p (.+) ON CONFLICT \\(user_id, product_id\\) DO UPDATE").
+					WithArgs(1, 2, true).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: domain.AddWishlistItemInput{ProductId: 2, Notify: true},
+		},
+		{
+			name: "Product not found",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO wishlist_items").
+					WithArgs(1, 3, false).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			input:   domain.AddWishlistItemInput{ProductId: 3},
+			wantErr: true,
+			errType: errors_handler.TypeNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.AddItem(1, tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateWishlistOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	r := newWishlistPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	userId := 1
+	products := []domain.CreateOrderInputProduct{
+		{Id: 1, Quantity: 2},
+		{Id: 2, Quantity: 1},
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+		errType errors_handler.Type
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM wishlist_items WHERE user_id=\\$1 AND product_id = ANY\\(\\$2\\)").
+					WithArgs(userId, pq.Array([]int64{1, 2})).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery("SELECT p.id, tr.rate FROM products p").
+					WithArgs(pq.Array([]int64{1, 2}), pq.Array([]string{"*"})).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "rate"}))
+				for _, product := range products {
+					rows := sqlmock.NewRows([]string{"name", "description", "price", "undiscounted_price", "image_url", "exists"}).
+						AddRow("Product1", "Description1", 10.5, 12.0, "image1.jpg", false)
+					mock.ExpectQuery("UPDATE products SET stock").
+						WithArgs(product.Quantity, product.Id).
+						WillReturnRows(rows)
+				}
+				mock.ExpectQuery("INSERT INTO orders").
+					WithArgs(userId, sqlmock.AnyArg(), "USD", domain.OneRate, "", false, nil, nil, domain.Money(0)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(123))
+				mock.ExpectPrepare("INSERT INTO ordered_products")
+				for _, product := range products {
+					mock.ExpectExec("INSERT INTO ordered_products").
+						WithArgs(123, product.Id, nil, "", "{}", "Product1", "Description1", domain.Money(1050), domain.Money(1200), "image1.jpg", product.Quantity, domain.TaxRate(0), domain.Money(0), domain.Money(1050).Mul(product.Quantity), domain.Money(0)).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+				mock.ExpectCommit()
+			},
+			want: 123,
+		},
+		{
+			name: "Not in wishlist",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM wishlist_items").
+					WithArgs(userId, pq.Array([]int64{1, 2})).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errType: errors_handler.TypeBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.CreateOrder(userId, domain.Checkout{ExchangeRate: domain.StoreExchangeRate("USD")}, products)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != "" {
+					assert.True(t, errors_handler.ErrorIsType(err, tt.errType))
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture describes a catalog with users and their orders.
+type Fixture struct {
+	Categories []CategoryFixture `json:"categories" yaml:"categories"`
+	Users      []UserFixture     `json:"users" yaml:"users"`
+	Orders     []OrderFixture    `json:"orders" yaml:"orders"`
+
+	// fsys and dir are used to resolve image paths relative to the fixture file.
+	fsys fs.FS
+	dir  string
+}
+
+type CategoryFixture struct {
+	Name        string           `json:"name" yaml:"name"`
+	Description string           `json:"description" yaml:"description"`
+	Image       string           `json:"image" yaml:"image"`
+	Available   *bool            `json:"available" yaml:"available"`
+	Products    []ProductFixture `json:"products" yaml:"products"`
+}
+
+type ProductFixture struct {
+	Name              string       `json:"name" yaml:"name"`
+	Description       string       `json:"description" yaml:"description"`
+	Image             string       `json:"image" yaml:"image"`
+	Available         *bool        `json:"available" yaml:"available"`
+	Price             domain.Money `json:"price" yaml:"price"`
+	UndiscountedPrice domain.Money `json:"undiscounted_price" yaml:"undiscounted_price"`
+	Stock             int          `json:"stock" yaml:"stock"`
+}
+
+type UserFixture struct {
+	Name     string `json:"name" yaml:"name"`
+	Email    string `json:"email" yaml:"email"`
+	Password string `json:"password" yaml:"password"`
+}
+
+type OrderFixture struct {
+	User     string                `json:"user" yaml:"user"`
+	Products []OrderProductFixture `json:"products" yaml:"products"`
+}
+
+// OrderProductFixture references a product of the fixture by name. The
+// category is only needed when the name is not unique in the fixture.
+type OrderProductFixture struct {
+	Category string `json:"category" yaml:"category"`
+	Product  string `json:"product" yaml:"product"`
+	Quantity int    `json:"quantity" yaml:"quantity"`
+}
+
+// Load reads a YAML or JSON fixture, depending on the file extension.
+func Load(fsys fs.FS, name string) (*Fixture, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := &Fixture{fsys: fsys, dir: path.Dir(name)}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, fixture)
+	case ".json":
+		err = json.Unmarshal(content, fixture)
+	default:
+		return nil, fmt.Errorf("unsupported fixture format %q", path.Ext(name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	return fixture, nil
+}
+
+func isAvailable(available *bool) bool {
+	return available == nil || *available
+}
@@ -0,0 +1,290 @@
+package seed
+
+import (
+	"fmt"
+	"io/fs"
+	"mime/multipart"
+	"path"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/service"
+)
+
+// Result counts what a fixture application created and skipped.
+type Result struct {
+	CategoriesCreated int
+	CategoriesSkipped int
+	ProductsCreated   int
+	ProductsSkipped   int
+	UsersCreated      int
+	UsersSkipped      int
+	OrdersCreated     int
+	OrdersSkipped     int
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("categories: %d created, %d skipped; products: %d created, %d skipped; users: %d created, %d skipped; orders: %d created, %d skipped",
+		r.CategoriesCreated, r.CategoriesSkipped,
+		r.ProductsCreated, r.ProductsSkipped,
+		r.UsersCreated, r.UsersSkipped,
+		r.OrdersCreated, r.OrdersSkipped)
+}
+
+// Loader applies fixtures through the service layer, so the same rules as
+// the API apply to the seeded data.
+type Loader struct {
+	services *service.Service
+}
+
+func NewLoader(services *service.Service) *Loader {
+	return &Loader{services}
+}
+
+// Reset removes every user, category, product and order.
+func (l *Loader) Reset() error {
+	return l.services.Media.ResetData()
+}
+
+// Apply creates what the fixture describes and skips what already exists:
+// categories are matched by name, products by category and name and users
+// by e-mail. Orders are only placed for users created by this call, so
+// applying the same fixture twice does not duplicate them.
+func (l *Loader) Apply(f *Fixture) (Result, error) {
+	var result Result
+	products := newProductIndex()
+
+	for _, category := range f.Categories {
+		categoryId, created, err := l.applyCategory(f, category)
+		if err != nil {
+			return result, fmt.Errorf("category %q: %w", category.Name, err)
+		}
+		if created {
+			result.CategoriesCreated++
+		} else {
+			result.CategoriesSkipped++
+		}
+
+		for _, product := range category.Products {
+			productId, created, err := l.applyProduct(f, categoryId, product)
+			if err != nil {
+				return result, fmt.Errorf("product %q: %w", product.Name, err)
+			}
+			if created {
+				result.ProductsCreated++
+			} else {
+				result.ProductsSkipped++
+			}
+			products.add(category.Name, product.Name, productId)
+		}
+	}
+
+	createdUsers := make(map[string]int)
+	for _, user := range f.Users {
+		userId, created, err := l.applyUser(user)
+		if err != nil {
+			return result, fmt.Errorf("user %q: %w", user.Email, err)
+		}
+		if created {
+			createdUsers[user.Email] = userId
+			result.UsersCreated++
+		} else {
+			result.UsersSkipped++
+		}
+	}
+
+	for i, order := range f.Orders {
+		userId, ok := createdUsers[order.User]
+		if !ok {
+			result.OrdersSkipped++
+			continue
+		}
+		if err := l.applyOrder(userId, order, products); err != nil {
+			return result, fmt.Errorf("order %d: %w", i+1, err)
+		}
+		result.OrdersCreated++
+	}
+
+	return result, nil
+}
+
+func (l *Loader) applyCategory(f *Fixture, category CategoryFixture) (int, bool, error) {
+	id, err := l.services.Category.GetIdByName(category.Name)
+	if err == nil {
+		return id, false, nil
+	}
+	if !errors_handler.ErrorIsType(err, errors_handler.TypeNotFound) {
+		return 0, false, err
+	}
+
+	input := domain.CreateCategoryInput{
+		Name:        category.Name,
+		Description: category.Description,
+		Available:   isAvailable(category.Available),
+	}
+	file, header, err := f.openImage(category.Image)
+	if err != nil {
+		return 0, false, err
+	}
+	if file != nil {
+		defer file.Close()
+		input.ImgFile = header
+		err = input.Validate()
+	} else {
+		err = input.ValidateFields()
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	id, err = l.services.Category.CreateCategory(input, file)
+	return id, err == nil, err
+}
+
+func (l *Loader) applyProduct(f *Fixture, categoryId int, product ProductFixture) (int, bool, error) {
+	id, err := l.services.Product.GetIdByName(categoryId, product.Name)
+	if err == nil {
+		return id, false, nil
+	}
+	if !errors_handler.ErrorIsType(err, errors_handler.TypeNotFound) {
+		return 0, false, err
+	}
+
+	input := domain.CreateProductInput{
+		CategoryId:        categoryId,
+		Name:              product.Name,
+		Description:       product.Description,
+		Available:         isAvailable(product.Available),
+		Price:             product.Price,
+		UndiscountedPrice: product.UndiscountedPrice,
+		Stock:             product.Stock,
+	}
+	file, header, err := f.openImage(product.Image)
+	if err != nil {
+		return 0, false, err
+	}
+	if file != nil {
+		defer file.Close()
+		input.ImgFile = header
+		err = input.Validate()
+	} else {
+		err = input.ValidateFields()
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	id, err = l.services.Product.CreateProduct(input, file)
+	return id, err == nil, err
+}
+
+func (l *Loader) applyUser(user UserFixture) (int, bool, error) {
+	existing, err := l.services.Authorization.GetUserByEmail(user.Email)
+	if err == nil {
+		return existing.Id, false, nil
+	}
+	if !errors_handler.ErrorIsType(err, errors_handler.TypeNotFound) {
+		return 0, false, err
+	}
+
+	input := domain.CreateUserInput{
+		Name:     user.Name,
+		Email:    user.Email,
+		Password: user.Password,
+	}
+	if err := input.Validate(); err != nil {
+		return 0, false, err
+	}
+
+	id, err := l.services.Authorization.RegisterUser(input)
+	return id, err == nil, err
+}
+
+func (l *Loader) applyOrder(userId int, order OrderFixture, products *productIndex) error {
+	var input domain.CreateOrderInput
+	for _, product := range order.Products {
+		productId, err := products.find(product.Category, product.Product)
+		if err != nil {
+			return err
+		}
+		input.Products = append(input.Products, domain.CreateOrderInputProduct{
+			Id:       productId,
+			Quantity: product.Quantity,
+		})
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	input.Sort()
+
+	_, err := l.services.Profile.CreateOrder(userId, input)
+	return err
+}
+
+// openImage opens an image referenced by the fixture. It returns a nil file
+// when no image is referenced.
+func (f *Fixture) openImage(name string) (multipart.File, *multipart.FileHeader, error) {
+	if name == "" {
+		return nil, nil, nil
+	}
+
+	file, err := f.fsys.Open(path.Join(f.dir, name))
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	multipartFile, ok := file.(multipart.File)
+	if !ok {
+		file.Close()
+		return nil, nil, fmt.Errorf("image %s: %w", name, fs.ErrInvalid)
+	}
+
+	return multipartFile, &multipart.FileHeader{Filename: path.Base(name), Size: info.Size()}, nil
+}
+
+type productKey struct {
+	category string
+	name     string
+}
+
+// productIndex resolves the products referenced by orders.
+type productIndex struct {
+	byKey  map[productKey]int
+	byName map[string][]int
+}
+
+func newProductIndex() *productIndex {
+	return &productIndex{
+		byKey:  make(map[productKey]int),
+		byName: make(map[string][]int),
+	}
+}
+
+func (p *productIndex) add(category, name string, id int) {
+	p.byKey[productKey{category, name}] = id
+	p.byName[name] = append(p.byName[name], id)
+}
+
+func (p *productIndex) find(category, name string) (int, error) {
+	if category != "" {
+		id, ok := p.byKey[productKey{category, name}]
+		if !ok {
+			return 0, fmt.Errorf("product %q not found in category %q", name, category)
+		}
+		return id, nil
+	}
+
+	ids := p.byName[name]
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("product %q not found", name)
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, fmt.Errorf("product %q is ambiguous, set its category", name)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+type AttributeService struct {
+	repo repository.Attribute
+}
+
+func newAttributeService(repo repository.Attribute) *AttributeService {
+	return &AttributeService{repo}
+}
+
+func (s *AttributeService) GetAttributes(categoryId int) ([]domain.Attribute, error) {
+	return s.repo.GetAttributes(categoryId)
+}
+
+func (s *AttributeService) CreateAttribute(categoryId int, input domain.CreateAttributeInput) (int, error) {
+	id, err := s.repo.CreateAttribute(categoryId, input)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return id, errors_handler.NotFound("category")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return id, errors_handler.BadRequest("attribute with such name already exists")
+	}
+	return id, err
+}
+
+func (s *AttributeService) UpdateAttribute(categoryId, attributeId int, input domain.UpdateAttributeInput) error {
+	err := s.repo.UpdateAttribute(categoryId, attributeId, input)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("attribute")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return errors_handler.BadRequest("attribute with such name already exists")
+	}
+	return err
+}
+
+func (s *AttributeService) DeleteAttribute(categoryId, attributeId int) error {
+	err := s.repo.DeleteAttribute(categoryId, attributeId)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("attribute")
+	}
+	return err
+}
+
+// SetProductAttributes replaces the attribute values of a product. The
+// attributes must be defined for the category of the product and the values
+// are stored in the canonical form of the attribute type.
+func (s *AttributeService) SetProductAttributes(productId int, input domain.SetProductAttributesInput) error {
+	categoryId, err := s.repo.GetProductCategoryId(productId)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("product")
+	}
+	if err != nil {
+		return err
+	}
+
+	attributes, err := s.repo.GetAttributes(categoryId)
+	if err != nil {
+		return err
+	}
+	byId := make(map[int]domain.Attribute, len(attributes))
+	for _, attribute := range attributes {
+		byId[attribute.Id] = attribute
+	}
+
+	values := make([]domain.ProductAttributeValue, 0, len(input.Values))
+	for _, value := range input.Values {
+		attribute, ok := byId[value.AttributeId]
+		if !ok {
+			return errors_handler.BadRequest(fmt.Sprintf("attribute %d is not defined for the category of the product", value.AttributeId))
+		}
+		normalized, err := normalizeAttributeValue(attribute.Type, value.Value)
+		if err != nil {
+			return errors_handler.BadRequest(fmt.Sprintf("%s: %s", attribute.Name, err.Error()))
+		}
+		values = append(values, domain.ProductAttributeValue{AttributeId: value.AttributeId, Value: normalized})
+	}
+
+	return s.repo.SetProductAttributes(productId, values)
+}
+
+func normalizeAttributeValue(attributeType, value string) (string, error) {
+	switch attributeType {
+	case domain.AttributeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("must be a number")
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case domain.AttributeBoolean:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("must be true or false")
+		}
+		return strconv.FormatBool(boolean), nil
+	}
+	return value, nil
+}
@@ -79,14 +79,7 @@ func (s *AuthService) GenerateAuthToken(email, password string) (string, error)
 		return "", err
 	}
 
-	payload := map[string]interface{}{
-		"id": user.Id,
-	}
-
-	signKey := os.Getenv("TOKEN_SIGNIN_KEY")
-
-	return generateToken(payload, signKey, signInTokenTTL)
-
+	return generateSignInToken(user.Id)
 }
 
 func (s *AuthService) ParseAuthToken(authToken string) (int, error) {
@@ -98,9 +91,98 @@ func (s *AuthService) ParseAuthToken(authToken string) (int, error) {
 
 	id := int(tokenPayload["id"].(float64))
 
+	user, err := s.repo.GetUserById(id)
+	if err != nil {
+		if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+			return 0, errors_handler.Unauthorized("user not found")
+		}
+		return 0, err
+	}
+	if user.Disabled {
+		return 0, errors_handler.Unauthorized("user is disabled")
+	}
+
 	return id, nil
 }
 
+func (s *AuthService) IsAdmin(userId int) (bool, error) {
+	user, err := s.repo.GetUserById(userId)
+	if err != nil {
+		if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.IsAdmin && !user.Disabled, nil
+}
+
+// RegisterUser creates an account with the given password without
+// the e-mail confirmation step.
+func (s *AuthService) RegisterUser(input domain.CreateUserInput) (int, error) {
+	user := domain.User{
+		Name:     input.Name,
+		Email:    input.Email,
+		Password: generatePasswordHash(input.Password),
+	}
+
+	id, err := s.repo.CreateUser(user)
+	if err != nil {
+		if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+			return 0, errors_handler.Forbidden(err.Error())
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *AuthService) SetUserDisabled(email string, disabled bool) error {
+	user, err := s.getUserByEmail(email)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetDisabled(user.Id, disabled)
+}
+
+func (s *AuthService) GrantAdmin(email string) error {
+	user, err := s.getUserByEmail(email)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetAdmin(user.Id, true)
+}
+
+// IssueAuthToken returns a sign in token for the user without checking
+// the password. It is meant for debugging from the command line only.
+func (s *AuthService) IssueAuthToken(email string) (string, error) {
+	user, err := s.getUserByEmail(email)
+	if err != nil {
+		return "", err
+	}
+	return generateSignInToken(user.Id)
+}
+
+func (s *AuthService) GetUserByEmail(email string) (domain.User, error) {
+	return s.getUserByEmail(email)
+}
+
+func (s *AuthService) getUserByEmail(email string) (domain.User, error) {
+	user, err := s.repo.GetUserByEmail(email)
+	if err != nil && errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return user, errors_handler.NotFound("user with this email")
+	}
+	return user, err
+}
+
+func generateSignInToken(userId int) (string, error) {
+	payload := map[string]interface{}{
+		"id": userId,
+	}
+
+	signKey := os.Getenv("TOKEN_SIGNIN_KEY")
+
+	return generateToken(payload, signKey, signInTokenTTL)
+}
+
 func (s *AuthService) RecoveryPassword(email string) error {
 	user, err := s.repo.GetUserByEmail(email)
 	if err != nil {
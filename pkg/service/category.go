@@ -16,12 +16,41 @@ func newCategoryService(repo repository.Category) *CategoryService {
 	return &CategoryService{repo}
 }
 
-func (s *CategoryService) GetAll(limit, offset int, search string) ([]domain.Category, error) {
-	categories, err := s.repo.GetAll(limit, offset, search)
+func (s *CategoryService) GetAll(page domain.PageRequest, search string) ([]domain.Category, domain.Pagination, error) {
+	categories, pagination, err := s.repo.GetAll(page, search)
 	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
-		return categories, errors_handler.NotFound("categories")
+		return categories, pagination, errors_handler.NotFound("categories")
 	}
-	return categories, err
+	return categories, pagination, err
+}
+
+// GetTree returns the available top level categories with their available
+// subcategories. The subcategories of an unavailable category are left out.
+func (s *CategoryService) GetTree() ([]domain.CategoryNode, error) {
+	categories, err := s.repo.GetTree()
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]domain.Category, 0)
+	children := make(map[int][]domain.Category)
+	for _, category := range categories {
+		if category.ParentId == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentId] = append(children[*category.ParentId], category)
+		}
+	}
+
+	var nodes func(categories []domain.Category) []domain.CategoryNode
+	nodes = func(categories []domain.Category) []domain.CategoryNode {
+		result := make([]domain.CategoryNode, len(categories))
+		for i, category := range categories {
+			result[i] = domain.CategoryNode{Category: category, Children: nodes(children[category.Id])}
+		}
+		return result
+	}
+	return nodes(roots), nil
 }
 
 func (s *CategoryService) GetById(id int) (domain.Category, error) {
@@ -29,19 +58,49 @@ func (s *CategoryService) GetById(id int) (domain.Category, error) {
 	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
 		return category, errors_handler.NotFound("category")
 	}
+	if err != nil {
+		return category, err
+	}
+
+	category.Breadcrumbs, err = s.repo.GetBreadcrumbs(id)
 	return category, err
 }
 
+func (s *CategoryService) AdminGetAll(page domain.PageRequest, filter domain.AdminCategoryFilterParams) ([]domain.Category, domain.Pagination, error) {
+	return s.repo.AdminGetAll(page, filter)
+}
+
+func (s *CategoryService) AdminGetById(id int) (domain.Category, error) {
+	category, err := s.repo.AdminGetById(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return category, errors_handler.NotFound("category")
+	}
+	if err != nil {
+		return category, err
+	}
+
+	category.Breadcrumbs, err = s.repo.GetBreadcrumbs(id)
+	return category, err
+}
+
+func (s *CategoryService) GetIdByName(name string) (int, error) {
+	id, err := s.repo.GetIdByName(name)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return id, errors_handler.NotFound("category")
+	}
+	return id, err
+}
+
 func (s *CategoryService) GetFilePath(categoryId int, fileName string) string {
 	return s.repo.GetFilePath(categoryId, fileName)
 }
 
-func (s *CategoryService) GetProducts(categoryId, limit, offset int, search string) ([]domain.Product, error) {
-	products, err := s.repo.GetProducts(categoryId, limit, offset, search)
+func (s *CategoryService) GetProducts(categoryId int, page domain.PageRequest, filter domain.ProductFilter) ([]domain.Product, domain.Pagination, error) {
+	products, pagination, err := s.repo.GetProducts(categoryId, page, filter)
 	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
-		return products, errors_handler.NotFound("products")
+		return products, pagination, errors_handler.NotFound("products")
 	}
-	return products, err
+	return products, pagination, err
 }
 
 func (s *CategoryService) CreateCategory(input domain.CreateCategoryInput, file multipart.File) (int, error) {
@@ -50,6 +109,9 @@ func (s *CategoryService) CreateCategory(input domain.CreateCategoryInput, file
 	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
 		return id, errors_handler.BadRequest("category with such name already exists")
 	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return id, errors_handler.BadRequest("provided parent_id does not correspond to any existing category")
+	}
 	return id, err
 }
 
@@ -62,6 +124,37 @@ func (s *CategoryService) UpdateCategory(id int, input domain.UpdateCategoryInpu
 	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
 		return errors_handler.BadRequest("category with such name already exists")
 	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return errors_handler.BadRequest("provided parent_id does not correspond to any existing category")
+	}
+
+	return err
+}
+
+func (s *CategoryService) ArchiveCategory(id int) error {
+	err := s.repo.ArchiveCategory(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("category")
+	}
+	return err
+}
 
+func (s *CategoryService) RestoreCategory(id int) error {
+	err := s.repo.RestoreCategory(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("category")
+	}
+	return err
+}
+
+func (s *CategoryService) DeleteCategory(id int) error {
+	err := s.repo.DeleteCategory(id)
+
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("category")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return errors_handler.Conflict("category has products that appear in orders, archive it instead")
+	}
 	return err
 }
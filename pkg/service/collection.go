@@ -0,0 +1,77 @@
+package service
+
+import (
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+type CollectionService struct {
+	repo repository.Collection
+}
+
+func newCollectionService(repo repository.Collection) *CollectionService {
+	return &CollectionService{repo}
+}
+
+func (s *CollectionService) GetAll(page domain.PageRequest) ([]domain.Collection, domain.Pagination, error) {
+	return s.repo.GetAll(page)
+}
+
+func (s *CollectionService) GetById(id int) (domain.Collection, error) {
+	collection, err := s.repo.GetById(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return collection, errors_handler.NotFound("collection")
+	}
+	return collection, err
+}
+
+// GetProducts returns the available products of an available collection.
+func (s *CollectionService) GetProducts(collectionId int, page domain.PageRequest) ([]domain.Product, domain.Pagination, error) {
+	if _, err := s.GetById(collectionId); err != nil {
+		return nil, domain.Pagination{}, err
+	}
+	return s.repo.GetProducts(collectionId, page)
+}
+
+func (s *CollectionService) CreateCollection(input domain.CreateCollectionInput) (int, error) {
+	id, err := s.repo.CreateCollection(input)
+
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return id, errors_handler.BadRequest("collection with such name already exists")
+	}
+	return id, err
+}
+
+func (s *CollectionService) UpdateCollection(id int, input domain.UpdateCollectionInput) error {
+	err := s.repo.UpdateCollection(id, input)
+
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("collection")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return errors_handler.BadRequest("collection with such name already exists")
+	}
+	return err
+}
+
+func (s *CollectionService) DeleteCollection(id int) error {
+	err := s.repo.DeleteCollection(id)
+
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("collection")
+	}
+	return err
+}
+
+func (s *CollectionService) SetProducts(collectionId int, input domain.SetCollectionProductsInput) error {
+	err := s.repo.SetProducts(collectionId, input.ProductIds)
+
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("collection")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return errors_handler.BadRequest("provided product_ids do not all correspond to existing products")
+	}
+	return err
+}
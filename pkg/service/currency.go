@@ -0,0 +1,132 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+// storeCurrency returns the ISO 4217 code of the currency the prices are in,
+// set with APP_CURRENCY.
+func storeCurrency() string {
+	if currency := strings.TrimSpace(os.Getenv("APP_CURRENCY")); currency != "" {
+		return strings.ToUpper(currency)
+	}
+	return domain.DefaultCurrency
+}
+
+// exchangeRate returns the rate converting the store prices to a currency,
+// the store currency when it is empty.
+func exchangeRate(repo repository.Currency, currency string) (domain.ExchangeRate, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" || currency == storeCurrency() {
+		return domain.StoreExchangeRate(storeCurrency()), nil
+	}
+	rate, err := repo.GetRate(currency)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return rate, errors_handler.BadRequest(fmt.Sprintf("currency: %s is not supported", currency))
+	}
+	return rate, err
+}
+
+type CurrencyService struct {
+	repo repository.Currency
+}
+
+func newCurrencyService(repo repository.Currency) *CurrencyService {
+	return &CurrencyService{repo}
+}
+
+// GetRates returns the rates of the currencies the prices can be shown in,
+// the store currency first.
+func (s *CurrencyService) GetRates() ([]domain.ExchangeRate, error) {
+	rates, err := s.repo.GetRates()
+	if err != nil {
+		return nil, err
+	}
+	return append([]domain.ExchangeRate{domain.StoreExchangeRate(storeCurrency())}, rates...), nil
+}
+
+func (s *CurrencyService) GetRate(currency string) (domain.ExchangeRate, error) {
+	return exchangeRate(s.repo, currency)
+}
+
+func (s *CurrencyService) SetRates(input domain.SetExchangeRatesInput) error {
+	for _, rate := range input.Rates {
+		if rate.Currency == storeCurrency() {
+			return errors_handler.BadRequest(fmt.Sprintf("rates: %s is the store currency", rate.Currency))
+		}
+	}
+	return s.repo.SetRates(input.Rates)
+}
+
+// ImportRates sets the rates of a CSV file with a header row naming its
+// currency and rate columns. Nothing is saved when a row is invalid.
+func (s *CurrencyService) ImportRates(file io.Reader) error {
+	rates, err := readExchangeRates(file)
+	if err != nil {
+		return errors_handler.BadRequest(err.Error())
+	}
+	input := domain.SetExchangeRatesInput{Rates: rates}
+	if err := input.Validate(); err != nil {
+		return errors_handler.BadRequest(err.Error())
+	}
+	return s.SetRates(input)
+}
+
+func (s *CurrencyService) DeleteRate(currency string) error {
+	err := s.repo.DeleteRate(strings.ToUpper(currency))
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("exchange rate")
+	}
+	return err
+}
+
+func readExchangeRates(r io.Reader) ([]domain.ExchangeRateInput, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file: can not be empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file: invalid csv: %s", err.Error())
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"currency", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("file: missing %s column", name)
+		}
+	}
+
+	rates := make([]domain.ExchangeRateInput, 0)
+	for n := 2; ; n++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("file: invalid csv: %s", err.Error())
+		}
+		rate, err := domain.ParseRate(fields[columns["rate"]])
+		if err != nil {
+			return nil, fmt.Errorf("file: row %d: %s", n, err.Error())
+		}
+		rates = append(rates, domain.ExchangeRateInput{
+			Currency: strings.ToUpper(strings.TrimSpace(fields[columns["currency"]])),
+			Rate:     rate,
+		})
+	}
+	return rates, nil
+}
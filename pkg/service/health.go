@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+type HealthService struct {
+	repo repository.Schema
+}
+
+func newHealthService(repo repository.Schema) *HealthService {
+	return &HealthService{repo}
+}
+
+func (s *HealthService) Check() (domain.Health, error) {
+	health := domain.Health{Status: "ok"}
+
+	version, err := s.repo.Version()
+	if err != nil {
+		return health, err
+	}
+	health.SchemaVersion = version
+
+	latest, err := s.repo.LatestVersion()
+	if err != nil {
+		return health, err
+	}
+	health.LatestSchemaVersion = latest
+
+	if version != latest {
+		health.Status = "schema_mismatch"
+	}
+	return health, nil
+}
@@ -0,0 +1,482 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxImportRows = 10000
+	// maxImportErrors is the number of row errors kept on a job, the failed
+	// rows are all counted.
+	maxImportErrors = 1000
+	// importProgressRows is how often, in rows, the progress of a job is
+	// recorded.
+	importProgressRows = 100
+	// importJobTimeout is how long a running job can go without recording
+	// its progress before it is considered interrupted.
+	importJobTimeout = 10 * time.Minute
+)
+
+// productColumns are the columns of a catalog CSV, in export order. Import
+// files may order them freely and leave the optional ones out, unknown
+// columns are ignored.
+var productColumns = []string{"sku", "category_id", "name", "description", "price", "undiscounted_price", "stock", "available", "image", "image_url"}
+
+var requiredProductColumns = []string{"sku", "category_id", "name", "price", "undiscounted_price", "stock"}
+
+type ImportService struct {
+	repo repository.Import
+}
+
+func newImportService(repo repository.Import) *ImportService {
+	return &ImportService{repo}
+}
+
+// importRow is a parsed row of an import, err is set when it could not be
+// read.
+type importRow struct {
+	row    int
+	record domain.ProductRecord
+	err    error
+}
+
+// importSource is a parsed import upload. images holds the files of an
+// archive upload by path, it is nil for the other uploads.
+type importSource struct {
+	rows   []importRow
+	images map[string]*zip.File
+}
+
+// ImportProducts reads an import upload and starts processing it in the
+// background. It returns the id of the job to follow it with. Uploads that
+// can not be read at all are refused, the rows are validated by the job.
+func (s *ImportService) ImportProducts(input domain.ImportProductsInput, file multipart.File) (int, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0, err
+	}
+
+	format := input.Format()
+	var source importSource
+	if format == domain.CatalogFormatZip {
+		source, err = readImportArchive(data)
+	} else {
+		source.rows, err = readProductRecords(format, bytes.NewReader(data))
+	}
+	if err != nil {
+		return 0, errors_handler.BadRequest(err.Error())
+	}
+	if len(source.rows) > maxImportRows {
+		return 0, errors_handler.BadRequest(fmt.Sprintf("file: at most %d rows can be imported at once", maxImportRows))
+	}
+
+	job := domain.ImportJob{
+		Status:    domain.ImportStatusRunning,
+		Format:    format,
+		DryRun:    input.DryRun,
+		TotalRows: len(source.rows),
+	}
+	job.Id, err = s.repo.CreateJob(job)
+	if err != nil {
+		return 0, err
+	}
+
+	go s.runImport(job, source)
+	return job.Id, nil
+}
+
+// ExpireJobs fails the jobs left running by a server which stopped while
+// processing them, and returns their number.
+func (s *ImportService) ExpireJobs() (int, error) {
+	return s.repo.ExpireJobs(time.Now().Add(-importJobTimeout), "the import was interrupted")
+}
+
+func (s *ImportService) GetJob(id int) (domain.ImportJob, error) {
+	if _, err := s.ExpireJobs(); err != nil {
+		return domain.ImportJob{}, err
+	}
+	job, err := s.repo.GetJob(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return job, errors_handler.NotFound("import job")
+	}
+	return job, err
+}
+
+// runImport validates the rows of an import and, unless it is a dry run,
+// upserts their products. A row failing does not stop the others. It runs
+// outside of any request, so a panic fails the job instead of the server.
+func (s *ImportService) runImport(job domain.ImportJob, source importSource) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("import job %d: %v\n%s", job.Id, r, debug.Stack())
+			s.finishImport(job, errors.New("the import stopped unexpectedly"))
+		}
+	}()
+	s.finishImport(job, s.importRows(&job, source))
+}
+
+// finishImport records the result of a job.
+func (s *ImportService) finishImport(job domain.ImportJob, err error) {
+	if err != nil {
+		job.Status = domain.ImportStatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = domain.ImportStatusDone
+	}
+	now := time.Now()
+	job.FinishedAt = &now
+
+	if err := s.repo.UpdateJob(job); err != nil {
+		logrus.Errorf("import job %d: %s", job.Id, err.Error())
+	}
+}
+
+func (s *ImportService) importRows(job *domain.ImportJob, source importSource) error {
+	skus := make([]string, 0, len(source.rows))
+	categoryIds := make([]int, 0)
+	for _, row := range source.rows {
+		skus = append(skus, row.record.Sku)
+		categoryIds = append(categoryIds, row.record.CategoryId)
+	}
+	productIds, err := s.repo.GetProductIds(skus)
+	if err != nil {
+		return err
+	}
+	categories, err := s.repo.GetCategoryIds(categoryIds)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]int, len(source.rows))
+	for _, row := range source.rows {
+		created, err := s.importRow(job.DryRun, row, source, productIds, categories, seen)
+		switch {
+		case err != nil:
+			job.FailedRows++
+			if len(job.Errors) < maxImportErrors {
+				job.Errors = append(job.Errors, domain.ImportRowError{Row: row.row, Sku: row.record.Sku, Error: err.Error()})
+			}
+		case created:
+			job.CreatedRows++
+		default:
+			job.UpdatedRows++
+		}
+
+		job.ProcessedRows++
+		if job.ProcessedRows%importProgressRows == 0 {
+			if err := s.repo.UpdateJob(*job); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importRow imports a row and reports whether its product was, or would be
+// in a dry run, created rather than updated. The returned errors are row
+// errors, shown to the admin.
+func (s *ImportService) importRow(dryRun bool, row importRow, source importSource, productIds map[string]int, categories map[int]bool, seen map[string]int) (bool, error) {
+	if row.err != nil {
+		return false, row.err
+	}
+	record := row.record
+
+	if first, ok := seen[record.Sku]; ok && record.Sku != "" {
+		return false, fmt.Errorf("sku: already imported by row %d", first)
+	}
+	seen[record.Sku] = row.row
+
+	image, err := importImage(record.Image, source.images)
+	if err != nil {
+		return false, err
+	}
+	var header *multipart.FileHeader
+	if image != nil {
+		header = &multipart.FileHeader{Filename: path.Base(image.Name), Size: int64(image.UncompressedSize64)}
+	}
+
+	_, exists := productIds[record.Sku]
+	if err := record.Validate(header, exists); err != nil {
+		return false, err
+	}
+	if !categories[record.CategoryId] {
+		return false, errors.New("category_id: does not correspond to any existing category")
+	}
+	if dryRun {
+		return !exists, nil
+	}
+
+	var file multipart.File
+	if image != nil {
+		file, err = openArchiveFile(image)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	id, created, err := s.repo.UpsertProduct(record.CreateInput(header), file)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return false, errors.New("category_id: does not correspond to any existing category")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeConstrainViolation) {
+		return false, errors.New("undiscounted_price: must not be less than price")
+	}
+	if err != nil {
+		logrus.Errorf("import row %d: %s", row.row, err.Error())
+		return false, errors.New("product could not be saved")
+	}
+	productIds[record.Sku] = id
+	return created, nil
+}
+
+// importImage returns the archive file an image path of a row refers to, nil
+// when the row has no image.
+func importImage(imagePath string, images map[string]*zip.File) (*zip.File, error) {
+	if imagePath == "" {
+		return nil, nil
+	}
+	if strings.Contains(imagePath, "://") {
+		return nil, errors.New("image: must be a path inside the import archive, not a url")
+	}
+	if images == nil {
+		return nil, errors.New("image: images can only be imported in a zip archive")
+	}
+	image, ok := images[path.Clean(strings.TrimPrefix(imagePath, "/"))]
+	if !ok {
+		return nil, fmt.Errorf("image: %s not found in the archive", imagePath)
+	}
+	if path.Ext(image.Name) == "" {
+		return nil, errors.New("image: file extension must be .jpg/.jpeg/.png")
+	}
+	return image, nil
+}
+
+// memoryFile is a file read in memory.
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+func openArchiveFile(f *zip.File) (multipart.File, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return memoryFile{bytes.NewReader(data)}, nil
+}
+
+// readImportArchive reads a zip archive holding, at its root, one CSV or
+// JSON lines file of products and the images the rows refer to by path.
+func readImportArchive(data []byte) (importSource, error) {
+	var source importSource
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return source, errors.New("file: invalid zip archive")
+	}
+
+	var records *zip.File
+	source.images = make(map[string]*zip.File)
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Clean(f.Name)
+		ext := strings.TrimPrefix(path.Ext(name), ".")
+		if !strings.Contains(name, "/") && (ext == domain.CatalogFormatCSV || ext == domain.CatalogFormatJSONL) {
+			if records != nil {
+				return source, errors.New("file: the archive must hold a single .csv or .jsonl file at its root")
+			}
+			records = f
+			continue
+		}
+		source.images[name] = f
+	}
+	if records == nil {
+		return source, errors.New("file: the archive must hold a .csv or .jsonl file at its root")
+	}
+
+	r, err := records.Open()
+	if err != nil {
+		return source, errors.New("file: invalid zip archive")
+	}
+	defer r.Close()
+
+	source.rows, err = readProductRecords(strings.TrimPrefix(path.Ext(records.Name), "."), r)
+	return source, err
+}
+
+func readProductRecords(format string, r io.Reader) ([]importRow, error) {
+	if format == domain.CatalogFormatJSONL {
+		return readJSONLRecords(r)
+	}
+	return readCSVRecords(r)
+}
+
+// readCSVRecords reads a CSV file with a header row naming its columns.
+func readCSVRecords(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file: can not be empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file: invalid csv: %s", err.Error())
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredProductColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("file: missing %s column", name)
+		}
+	}
+
+	rows := make([]importRow, 0)
+	for n := 2; ; n++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) && parseErr.Err != csv.ErrFieldCount {
+				rows = append(rows, importRow{row: n, err: errors.New("invalid csv row")})
+				continue
+			}
+			return nil, fmt.Errorf("file: invalid csv: %s", err.Error())
+		}
+		record, err := parseCSVRecord(columns, fields)
+		rows = append(rows, importRow{row: n, record: record, err: err})
+	}
+	return rows, nil
+}
+
+func parseCSVRecord(columns map[string]int, fields []string) (domain.ProductRecord, error) {
+	var record domain.ProductRecord
+	value := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+
+	record.Sku = value("sku")
+	record.Name = value("name")
+	record.Description = value("description")
+	record.Image = value("image")
+
+	var err error
+	if record.CategoryId, err = strconv.Atoi(value("category_id")); err != nil {
+		return record, errors.New("category_id: must be an integer")
+	}
+	if record.Stock, err = strconv.Atoi(value("stock")); err != nil {
+		return record, errors.New("stock: must be an integer")
+	}
+	if record.Price, err = domain.ParseMoney(value("price")); err != nil {
+		return record, errors.New("price: must be a number with at most 2 decimals")
+	}
+	if record.UndiscountedPrice, err = domain.ParseMoney(value("undiscounted_price")); err != nil {
+		return record, errors.New("undiscounted_price: must be a number with at most 2 decimals")
+	}
+	if available := value("available"); available != "" {
+		if record.Available, err = strconv.ParseBool(available); err != nil {
+			return record, errors.New("available: must be true or false")
+		}
+	}
+	return record, nil
+}
+
+// readJSONLRecords reads a JSON lines file, one product object per line.
+// Blank lines are skipped.
+func readJSONLRecords(r io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	rows := make([]importRow, 0)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record domain.ProductRecord
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			err = errors.New("invalid json object")
+		}
+		rows = append(rows, importRow{row: n, record: record, err: err})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("file: invalid jsonl: %s", err.Error())
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file: can not be empty")
+	}
+	return rows, nil
+}
+
+// ExportProducts writes the whole catalog to w, as CSV or JSON lines.
+func (s *ImportService) ExportProducts(format string, w io.Writer) error {
+	if format == domain.CatalogFormatJSONL {
+		encoder := json.NewEncoder(w)
+		return s.repo.ExportProducts(func(record domain.ProductRecord) error {
+			return encoder.Encode(record)
+		})
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(productColumns); err != nil {
+		return err
+	}
+	err := s.repo.ExportProducts(func(record domain.ProductRecord) error {
+		return writer.Write([]string{
+			record.Sku,
+			strconv.Itoa(record.CategoryId),
+			record.Name,
+			record.Description,
+			record.Price.String(),
+			record.UndiscountedPrice.String(),
+			strconv.Itoa(record.Stock),
+			strconv.FormatBool(record.Available),
+			record.Image,
+			record.ImageUrl,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+type MediaService struct {
+	repo repository.Media
+}
+
+func newMediaService(repo repository.Media) *MediaService {
+	return &MediaService{repo}
+}
+
+// CollectGarbage removes the media directories whose owner no longer
+// exists and returns them. With dryRun nothing is removed.
+func (s *MediaService) CollectGarbage(dryRun bool) ([]string, error) {
+	return s.repo.CollectGarbage(dryRun)
+}
+
+// ResetData removes every user, category, product and order.
+func (s *MediaService) ResetData() error {
+	return s.repo.ResetData()
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"fmt"
 	"mime/multipart"
 
 	"github.com/renlin-code/mock-shop-api/pkg/domain"
@@ -9,19 +10,27 @@ import (
 )
 
 type ProductService struct {
-	repo repository.Product
+	repo          repository.Product
+	categoryRepo  repository.Category
+	attributeRepo repository.Attribute
+	imageRepo     repository.ProductImage
+	variantRepo   repository.Variant
 }
 
-func newProductService(repo repository.Product) *ProductService {
-	return &ProductService{repo}
+func newProductService(repo repository.Product, categoryRepo repository.Category, attributeRepo repository.Attribute, imageRepo repository.ProductImage, variantRepo repository.Variant) *ProductService {
+	return &ProductService{repo, categoryRepo, attributeRepo, imageRepo, variantRepo}
 }
 
-func (s *ProductService) GetAll(limit, offset int, search string) ([]domain.Product, error) {
-	products, err := s.repo.GetAll(limit, offset, search)
+func (s *ProductService) GetAll(page domain.PageRequest, filter domain.ProductFilter) ([]domain.Product, domain.Pagination, error) {
+	products, pagination, err := s.repo.GetAll(page, filter)
 	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
-		return products, errors_handler.NotFound("products")
+		return products, pagination, errors_handler.NotFound("products")
 	}
-	return products, err
+	return products, pagination, err
+}
+
+func (s *ProductService) GetFacets(categoryId int, filter domain.ProductFilter) (domain.Facets, error) {
+	return s.repo.GetFacets(categoryId, filter)
 }
 
 func (s *ProductService) GetById(id int) (domain.Product, error) {
@@ -29,9 +38,81 @@ func (s *ProductService) GetById(id int) (domain.Product, error) {
 	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
 		return product, errors_handler.NotFound("product")
 	}
+	if err != nil {
+		return product, err
+	}
+	return s.withDetails(product, true)
+}
+
+func (s *ProductService) AdminGetAll(page domain.PageRequest, filter domain.AdminProductFilterParams) ([]domain.Product, domain.Pagination, error) {
+	return s.repo.AdminGetAll(page, filter)
+}
+
+// AdminGetById returns a product whether available or archived, with all its
+// variants.
+func (s *ProductService) AdminGetById(id int) (domain.Product, error) {
+	product, err := s.repo.AdminGetById(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return product, errors_handler.NotFound("product")
+	}
+	if err != nil {
+		return product, err
+	}
+	return s.withDetails(product, false)
+}
+
+// withDetails adds to a product its breadcrumbs, categories, attributes,
+// images, options and variants, only the available ones when
+// onlyAvailableVariants is set.
+func (s *ProductService) withDetails(product domain.Product, onlyAvailableVariants bool) (domain.Product, error) {
+	id := product.Id
+
+	var err error
+	product.Breadcrumbs, err = s.categoryRepo.GetBreadcrumbs(product.CategoryId)
+	if err != nil {
+		return product, err
+	}
+	categoryIds, err := s.repo.GetCategoryIds(id)
+	if err != nil {
+		return product, err
+	}
+	product.CategoryIds = append([]int{product.CategoryId}, categoryIds...)
+	product.Attributes, err = s.attributeRepo.GetProductAttributes(id)
+	if err != nil {
+		return product, err
+	}
+	product.Images, err = s.imageRepo.GetImages(id)
+	if err != nil {
+		return product, err
+	}
+	product.Options, err = s.variantRepo.GetOptions(id)
+	if err != nil {
+		return product, err
+	}
+	product.Variants, err = s.variantRepo.GetVariants(id, onlyAvailableVariants)
 	return product, err
 }
 
+func (s *ProductService) GetIdByName(categoryId int, name string) (int, error) {
+	id, err := s.repo.GetIdByName(categoryId, name)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return id, errors_handler.NotFound("product")
+	}
+	return id, err
+}
+
+func (s *ProductService) SetCategories(productId int, input domain.SetProductCategoriesInput) error {
+	err := s.repo.SetCategories(productId, input.CategoryIds)
+
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("product")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return errors_handler.BadRequest("provided category_ids do not all correspond to existing categories")
+	}
+	return err
+}
+
 func (s *ProductService) GetFilePath(productId int, fileName string) string {
 	return s.repo.GetFilePath(productId, fileName)
 }
@@ -42,6 +123,9 @@ func (s *ProductService) CreateProduct(input domain.CreateProductInput, file mul
 	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
 		return id, errors_handler.BadRequest("provided category_id does not correspond to any existing category")
 	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return id, errors_handler.BadRequest("product with such sku already exists")
+	}
 	return id, err
 }
 
@@ -51,5 +135,61 @@ func (s *ProductService) UpdateProduct(id int, input domain.UpdateProductInput,
 	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
 		return errors_handler.NotFound("product")
 	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return errors_handler.BadRequest("product with such sku already exists")
+	}
+	return err
+}
+
+// BulkUpdate applies the operations of the input to the selected products at
+// once. An update leaving a product invalid changes none of them, its preview
+// tells which.
+func (s *ProductService) BulkUpdate(input domain.BulkUpdateProductsInput) (domain.BulkUpdateResult, error) {
+	result, err := s.repo.BulkUpdate(input)
+
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return result, errors_handler.NotFound("product")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return result, errors_handler.BadRequest("provided category_id does not correspond to any existing category")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeConstrainViolation) {
+		return result, errors_handler.BadRequest(fmt.Sprintf("at most %d products can be updated at once", domain.MaxBulkProducts))
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if !input.Preview && result.Failed > 0 {
+		return result, errors_handler.BadRequest(fmt.Sprintf("the update would leave %d products invalid, preview it to see them", result.Failed))
+	}
+	return result, nil
+}
+
+func (s *ProductService) ArchiveProduct(id int) error {
+	err := s.repo.ArchiveProduct(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("product")
+	}
+	return err
+}
+
+func (s *ProductService) RestoreProduct(id int) error {
+	err := s.repo.RestoreProduct(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("product")
+	}
+	return err
+}
+
+func (s *ProductService) DeleteProduct(id int) error {
+	err := s.repo.DeleteProduct(id)
+
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("product")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return errors_handler.Conflict("product appears in orders, archive it instead")
+	}
 	return err
 }
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"mime/multipart"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+type ProductImageService struct {
+	repo repository.ProductImage
+}
+
+func newProductImageService(repo repository.ProductImage) *ProductImageService {
+	return &ProductImageService{repo}
+}
+
+func (s *ProductImageService) AddImage(productId int, input domain.AddProductImageInput, file multipart.File) (int, error) {
+	id, err := s.repo.AddImage(productId, input, file)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return id, errors_handler.NotFound("product")
+	}
+	return id, err
+}
+
+func (s *ProductImageService) UpdateImage(productId, imageId int, input domain.UpdateProductImageInput) error {
+	err := s.repo.UpdateImage(productId, imageId, input)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("image")
+	}
+	return err
+}
+
+// ReorderImages orders the gallery of a product. The ids must list every
+// image of the product exactly once.
+func (s *ProductImageService) ReorderImages(productId int, input domain.ReorderProductImagesInput) error {
+	images, err := s.repo.GetImages(productId)
+	if err != nil {
+		return err
+	}
+	if len(images) != len(input.Ids) {
+		return errors_handler.BadRequest("ids must list every image of the product")
+	}
+	imageIds := make(map[int]struct{}, len(images))
+	for _, image := range images {
+		imageIds[image.Id] = struct{}{}
+	}
+	for _, id := range input.Ids {
+		if _, found := imageIds[id]; !found {
+			return errors_handler.BadRequest("ids must list every image of the product")
+		}
+	}
+
+	return s.repo.SetImagePositions(productId, input.Ids)
+}
+
+// SetPrimaryImage moves an image to the first position of the gallery and
+// keeps the order of the others.
+func (s *ProductImageService) SetPrimaryImage(productId, imageId int) error {
+	images, err := s.repo.GetImages(productId)
+	if err != nil {
+		return err
+	}
+
+	ids := []int{imageId}
+	found := false
+	for _, image := range images {
+		if image.Id == imageId {
+			found = true
+			continue
+		}
+		ids = append(ids, image.Id)
+	}
+	if !found {
+		return errors_handler.NotFound("image")
+	}
+
+	return s.repo.SetImagePositions(productId, ids)
+}
+
+func (s *ProductImageService) DeleteImage(productId, imageId int) error {
+	err := s.repo.DeleteImage(productId, imageId)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("image")
+	}
+	return err
+}
@@ -9,11 +9,12 @@ import (
 )
 
 type ProfileService struct {
-	repo repository.Profile
+	repo         repository.Profile
+	currencyRepo repository.Currency
 }
 
-func newProfileService(repo repository.Profile) *ProfileService {
-	return &ProfileService{repo}
+func newProfileService(repo repository.Profile, currencyRepo repository.Currency) *ProfileService {
+	return &ProfileService{repo, currencyRepo}
 }
 
 func (s *ProfileService) GetProfile(userId int) (domain.User, error) {
@@ -27,20 +28,25 @@ func (s *ProfileService) UpdateProfile(userId int, input domain.UpdateProfileInp
 	return s.repo.UpdateProfile(userId, input, file)
 }
 
-func (s *ProfileService) CreateOrder(userId int, products []domain.CreateOrderInputProduct) (int, error) {
-	id, err := s.repo.CreateOrder(userId, products)
+// CreateOrder places an order in the currency of the input, its prices are
+// converted with the current exchange rate and taxed for its region.
+func (s *ProfileService) CreateOrder(userId int, input domain.CreateOrderInput) (int, error) {
+	checkout, err := newCheckout(s.currencyRepo, input)
+	if err != nil {
+		return 0, err
+	}
+	id, err := s.repo.CreateOrder(userId, checkout, input.Products)
 	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
-		return id, errors_handler.NotFound("product")
+		return id, errors_handler.NotFound("product or variant")
 	}
 	if errors_handler.ErrorIsType(err, errors_handler.TypeConstrainViolation) {
 		return id, errors_handler.BadRequest("quantity exceeds the stock")
 	}
 	return id, err
-
 }
 
-func (s *ProfileService) GetAllOrders(userId, limit, offset int) ([]domain.Order, error) {
-	return s.repo.GetAllOrders(userId, limit, offset)
+func (s *ProfileService) GetAllOrders(userId int, page domain.PageRequest) ([]domain.Order, domain.Pagination, error) {
+	return s.repo.GetAllOrders(userId, page)
 }
 
 func (s *ProfileService) GetOrderById(userId, orderId int) (domain.Order, error) {
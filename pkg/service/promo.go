@@ -0,0 +1,68 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+// normalizePromoCode returns a promo code as it is stored, the codes are
+// matched case-insensitively.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+type PromoService struct {
+	repo repository.Promo
+}
+
+func newPromoService(repo repository.Promo) *PromoService {
+	return &PromoService{repo}
+}
+
+func (s *PromoService) GetAll(page domain.PageRequest) ([]domain.PromoCode, domain.Pagination, error) {
+	return s.repo.GetAll(page)
+}
+
+func (s *PromoService) GetById(id int) (domain.PromoCode, error) {
+	promo, err := s.repo.GetById(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return promo, errors_handler.NotFound("promo code")
+	}
+	return promo, err
+}
+
+func (s *PromoService) CreatePromoCode(input domain.PromoCodeInput) (int, error) {
+	input.Code = normalizePromoCode(input.Code)
+	id, err := s.repo.CreatePromoCode(input)
+	return id, promoCodeError(err)
+}
+
+func (s *PromoService) UpdatePromoCode(id int, input domain.PromoCodeInput) error {
+	input.Code = normalizePromoCode(input.Code)
+	err := s.repo.UpdatePromoCode(id, input)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("promo code")
+	}
+	return promoCodeError(err)
+}
+
+func (s *PromoService) DeletePromoCode(id int) error {
+	err := s.repo.DeletePromoCode(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("promo code")
+	}
+	return err
+}
+
+func promoCodeError(err error) error {
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return errors_handler.BadRequest("promo code with such code already exists")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return errors_handler.BadRequest("provided product_ids and category_ids do not all correspond to existing products and categories")
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+const defaultRelatedMinOrders = 2
+
+type RelatedService struct {
+	repo repository.Related
+	// minOrders is the number of orders two products must share to be
+	// related, set with RELATED_MIN_ORDERS.
+	minOrders int
+}
+
+func newRelatedService(repo repository.Related) *RelatedService {
+	minOrders := defaultRelatedMinOrders
+	if value, err := strconv.Atoi(os.Getenv("RELATED_MIN_ORDERS")); err == nil && value > 0 {
+		minOrders = value
+	}
+	return &RelatedService{repo: repo, minOrders: minOrders}
+}
+
+// Refresh recomputes the products bought together and returns the number of
+// related pairs kept.
+func (s *RelatedService) Refresh() (int, error) {
+	return s.repo.Refresh(domain.RelatedProductsLimit, s.minOrders)
+}
+
+func (s *RelatedService) GetRelated(productId int) ([]domain.Product, error) {
+	products, err := s.repo.GetRelated(productId, domain.RelatedProductsLimit)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return products, errors_handler.NotFound("product")
+	}
+	return products, err
+}
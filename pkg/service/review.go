@@ -0,0 +1,220 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+const defaultReportThreshold = 3
+
+// reviewModeration is the moderation policy of the reviews, set with the
+// environment:
+//   - REVIEWS_AUTO_APPROVE: "false" holds every review for moderation,
+//     reviews are published right away otherwise.
+//   - REVIEWS_BANNED_WORDS_FILE: a local file listing a banned word per line,
+//     reviews using one are always held for moderation. A file that can not
+//     be read is an error, the policy would not be enforced.
+//   - REVIEWS_REPORT_THRESHOLD: the number of reports taking a published
+//     review down until it is moderated, 3 by default.
+type reviewModeration struct {
+	autoApprove     bool
+	bannedWords     map[string]bool
+	reportThreshold int
+}
+
+func newReviewModeration() (reviewModeration, error) {
+	m := reviewModeration{
+		autoApprove:     os.Getenv("REVIEWS_AUTO_APPROVE") != "false",
+		bannedWords:     make(map[string]bool),
+		reportThreshold: defaultReportThreshold,
+	}
+
+	if threshold, err := strconv.Atoi(os.Getenv("REVIEWS_REPORT_THRESHOLD")); err == nil && threshold > 0 {
+		m.reportThreshold = threshold
+	}
+
+	if path := os.Getenv("REVIEWS_BANNED_WORDS_FILE"); path != "" {
+		if err := m.loadBannedWords(path); err != nil {
+			return m, fmt.Errorf("failed to load banned words: %w", err)
+		}
+	}
+	return m, nil
+}
+
+func (m *reviewModeration) loadBannedWords(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if word := strings.ToLower(strings.TrimSpace(scanner.Text())); word != "" {
+			m.bannedWords[word] = true
+		}
+	}
+	return scanner.Err()
+}
+
+// status returns the status of a new or edited review.
+func (m reviewModeration) status(text string) string {
+	if !m.autoApprove || m.hasBannedWord(text) {
+		return domain.ReviewStatusPending
+	}
+	return domain.ReviewStatusApproved
+}
+
+func (m reviewModeration) hasBannedWord(text string) bool {
+	if len(m.bannedWords) == 0 {
+		return false
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		if m.bannedWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+type ReviewService struct {
+	repo        repository.Review
+	productRepo repository.Product
+	moderation  reviewModeration
+}
+
+func newReviewService(repo repository.Review, productRepo repository.Product) (*ReviewService, error) {
+	moderation, err := newReviewModeration()
+	if err != nil {
+		return nil, err
+	}
+	return &ReviewService{repo, productRepo, moderation}, nil
+}
+
+// GetProductReviews returns the approved reviews of an available product,
+// newest first.
+func (s *ReviewService) GetProductReviews(productId int, page domain.PageRequest) ([]domain.Review, domain.Pagination, error) {
+	if err := s.checkProduct(productId); err != nil {
+		return nil, domain.Pagination{}, err
+	}
+	return s.repo.GetProductReviews(productId, page)
+}
+
+// GetUserReviews returns the reviews of the user, whatever their status.
+func (s *ReviewService) GetUserReviews(userId int, page domain.PageRequest) ([]domain.Review, domain.Pagination, error) {
+	return s.repo.GetUserReviews(userId, page)
+}
+
+func (s *ReviewService) AdminGetAll(page domain.PageRequest, filter domain.AdminReviewFilterParams) ([]domain.Review, domain.Pagination, error) {
+	return s.repo.AdminGetAll(page, filter)
+}
+
+// CreateReview reviews an available product the user has purchased. The
+// review is published or held for moderation as the policy says.
+func (s *ReviewService) CreateReview(userId int, input domain.CreateReviewInput) (int, error) {
+	if err := s.checkProduct(input.ProductId); err != nil {
+		return 0, err
+	}
+
+	purchased, err := s.repo.HasPurchased(userId, input.ProductId)
+	if err != nil {
+		return 0, err
+	}
+	if !purchased {
+		return 0, errors_handler.Forbidden("only customers who purchased the product can review it")
+	}
+
+	id, err := s.repo.CreateReview(userId, input, s.moderation.status(input.Text))
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return id, errors_handler.Conflict("product already reviewed, edit the review instead")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return id, errors_handler.NotFound("product")
+	}
+	return id, err
+}
+
+// UpdateReview updates a review of the user, a new text is moderated again.
+func (s *ReviewService) UpdateReview(userId, reviewId int, input domain.UpdateReviewInput) error {
+	var status *string
+	if input.Text != nil {
+		textStatus := s.moderation.status(*input.Text)
+		status = &textStatus
+	}
+
+	err := s.repo.UpdateReview(userId, reviewId, input, status)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("review")
+	}
+	return err
+}
+
+func (s *ReviewService) DeleteReview(userId, reviewId int) error {
+	err := s.repo.DeleteReview(userId, reviewId)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("review")
+	}
+	return err
+}
+
+func (s *ReviewService) ApproveReview(reviewId int) error {
+	return s.setStatus(reviewId, domain.ReviewStatusApproved)
+}
+
+func (s *ReviewService) RejectReview(reviewId int) error {
+	return s.setStatus(reviewId, domain.ReviewStatusRejected)
+}
+
+func (s *ReviewService) setStatus(reviewId int, status string) error {
+	err := s.repo.SetReviewStatus(reviewId, status)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("review")
+	}
+	return err
+}
+
+// ReportReview reports a published review of another user as abusive.
+func (s *ReviewService) ReportReview(userId, reviewId int, input domain.ReportReviewInput) error {
+	review, err := s.repo.GetReview(reviewId)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("review")
+	}
+	if err != nil {
+		return err
+	}
+	// Reviews that are not published can not be seen, nor reported.
+	if review.Status != domain.ReviewStatusApproved {
+		return errors_handler.NotFound("review")
+	}
+	if review.UserId == userId {
+		return errors_handler.BadRequest("own reviews can not be reported")
+	}
+
+	_, err = s.repo.ReportReview(userId, reviewId, input, s.moderation.reportThreshold)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("review")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return errors_handler.Conflict("review already reported")
+	}
+	return err
+}
+
+func (s *ReviewService) checkProduct(productId int) error {
+	_, err := s.productRepo.GetById(productId)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("product")
+	}
+	return err
+}
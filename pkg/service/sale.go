@@ -0,0 +1,61 @@
+package service
+
+import (
+	"time"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+type SaleService struct {
+	repo repository.Sale
+}
+
+func newSaleService(repo repository.Sale) *SaleService {
+	return &SaleService{repo}
+}
+
+func (s *SaleService) GetAll(page domain.PageRequest, filter domain.PriceScheduleFilterParams) ([]domain.PriceSchedule, domain.Pagination, error) {
+	return s.repo.GetAll(page, filter)
+}
+
+func (s *SaleService) GetById(id int) (domain.PriceSchedule, error) {
+	schedule, err := s.repo.GetById(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return schedule, errors_handler.NotFound("price schedule")
+	}
+	return schedule, err
+}
+
+// CreateSchedule schedules a sale. A sale already due is started right away
+// rather than on the next run of the scheduler.
+func (s *SaleService) CreateSchedule(input domain.PriceScheduleInput) (int, error) {
+	now := time.Now()
+	if !input.EndsAt.After(now) {
+		return 0, errors_handler.BadRequest("ends_at: must be in the future")
+	}
+
+	id, err := s.repo.CreateSchedule(input)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return id, errors_handler.BadRequest("provided product_id or category_id does not correspond to an existing product or category")
+	}
+	if err != nil || input.StartsAt.After(now) {
+		return id, err
+	}
+	_, err = s.repo.Run(now)
+	return id, err
+}
+
+func (s *SaleService) CancelSchedule(id int) error {
+	err := s.repo.CancelSchedule(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("price schedule")
+	}
+	return err
+}
+
+// Run starts and ends the sales due.
+func (s *SaleService) Run() (domain.PriceScheduleRun, error) {
+	return s.repo.Run(time.Now())
+}
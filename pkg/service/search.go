@@ -0,0 +1,76 @@
+package service
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+// defaultSuggestionsLimit is the number of suggestions of each kind returned
+// when the request does not set one.
+const defaultSuggestionsLimit = 5
+
+// minRecordedQueryLength is the length under which searches are not worth
+// suggesting to others.
+const minRecordedQueryLength = 2
+
+// defaultSuggestedQueryMinCount and defaultSuggestedQueryMaxAge keep the
+// searches of a single customer, an email address or an order number, from
+// being suggested to others.
+const (
+	defaultSuggestedQueryMinCount = 5
+	defaultSuggestedQueryMaxAge   = 30 * 24 * time.Hour
+)
+
+type SearchService struct {
+	repo repository.Search
+	// queryMinCount is the number of times a query must have been searched
+	// to be suggested, set with SEARCH_SUGGESTED_QUERY_MIN_COUNT, and
+	// queryMaxAge how recently, set with SEARCH_SUGGESTED_QUERY_MAX_AGE.
+	queryMinCount int
+	queryMaxAge   time.Duration
+}
+
+func newSearchService(repo repository.Search) *SearchService {
+	s := &SearchService{
+		repo:          repo,
+		queryMinCount: defaultSuggestedQueryMinCount,
+		queryMaxAge:   defaultSuggestedQueryMaxAge,
+	}
+	if value, err := strconv.Atoi(os.Getenv("SEARCH_SUGGESTED_QUERY_MIN_COUNT")); err == nil && value > 0 {
+		s.queryMinCount = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("SEARCH_SUGGESTED_QUERY_MAX_AGE")); err == nil && value > 0 {
+		s.queryMaxAge = value
+	}
+	return s
+}
+
+func (s *SearchService) Suggest(params domain.SuggestParams) (domain.Suggestions, error) {
+	prefix := domain.NormalizeQuery(params.Query)
+	if prefix == "" {
+		return domain.Suggestions{
+			Products:   make([]domain.ProductSuggestion, 0),
+			Categories: make([]domain.CategorySuggestion, 0),
+			Queries:    make([]string, 0),
+		}, nil
+	}
+
+	limit := params.Limit
+	if limit == 0 {
+		limit = defaultSuggestionsLimit
+	}
+	return s.repo.Suggest(prefix, limit, s.queryMinCount, time.Now().Add(-s.queryMaxAge))
+}
+
+// RecordQuery counts a search so it can be suggested to others.
+func (s *SearchService) RecordQuery(query string) error {
+	query = domain.NormalizeQuery(query)
+	if len([]rune(query)) < minRecordedQueryLength {
+		return nil
+	}
+	return s.repo.RecordQuery(query)
+}
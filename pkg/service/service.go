@@ -1,6 +1,7 @@
 package service
 
 import (
+	"io"
 	"mime/multipart"
 
 	"github.com/renlin-code/mock-shop-api/pkg/domain"
@@ -14,47 +15,219 @@ type Authorization interface {
 	ParseAuthToken(token string) (int, error)
 	RecoveryPassword(email string) error
 	UpdatePassword(token, password string) error
+	IsAdmin(userId int) (bool, error)
+	RegisterUser(input domain.CreateUserInput) (int, error)
+	SetUserDisabled(email string, disabled bool) error
+	GrantAdmin(email string) error
+	IssueAuthToken(email string) (string, error)
+	GetUserByEmail(email string) (domain.User, error)
 }
 
 type Category interface {
-	GetAll(limit, offset int, search string) ([]domain.Category, error)
+	GetAll(page domain.PageRequest, search string) ([]domain.Category, domain.Pagination, error)
+	GetTree() ([]domain.CategoryNode, error)
 	GetById(id int) (domain.Category, error)
+	AdminGetAll(page domain.PageRequest, filter domain.AdminCategoryFilterParams) ([]domain.Category, domain.Pagination, error)
+	AdminGetById(id int) (domain.Category, error)
+	GetIdByName(name string) (int, error)
 	GetFilePath(categoryId int, fileName string) string
-	GetProducts(categoryId, limit, offset int, search string) ([]domain.Product, error)
+	GetProducts(categoryId int, page domain.PageRequest, filter domain.ProductFilter) ([]domain.Product, domain.Pagination, error)
 	CreateCategory(input domain.CreateCategoryInput, file multipart.File) (int, error)
 	UpdateCategory(id int, input domain.UpdateCategoryInput, file multipart.File) error
+	ArchiveCategory(id int) error
+	RestoreCategory(id int) error
+	DeleteCategory(id int) error
 }
 
 type Product interface {
-	GetAll(limit, offset int, search string) ([]domain.Product, error)
+	GetAll(page domain.PageRequest, filter domain.ProductFilter) ([]domain.Product, domain.Pagination, error)
+	GetFacets(categoryId int, filter domain.ProductFilter) (domain.Facets, error)
 	GetById(id int) (domain.Product, error)
+	AdminGetAll(page domain.PageRequest, filter domain.AdminProductFilterParams) ([]domain.Product, domain.Pagination, error)
+	AdminGetById(id int) (domain.Product, error)
+	GetIdByName(categoryId int, name string) (int, error)
+	SetCategories(productId int, input domain.SetProductCategoriesInput) error
 	GetFilePath(productId int, fileName string) string
 	CreateProduct(input domain.CreateProductInput, file multipart.File) (int, error)
 	UpdateProduct(id int, input domain.UpdateProductInput, file multipart.File) error
+	ArchiveProduct(id int) error
+	RestoreProduct(id int) error
+	DeleteProduct(id int) error
+	BulkUpdate(input domain.BulkUpdateProductsInput) (domain.BulkUpdateResult, error)
+}
+
+type Attribute interface {
+	GetAttributes(categoryId int) ([]domain.Attribute, error)
+	CreateAttribute(categoryId int, input domain.CreateAttributeInput) (int, error)
+	UpdateAttribute(categoryId, attributeId int, input domain.UpdateAttributeInput) error
+	DeleteAttribute(categoryId, attributeId int) error
+	SetProductAttributes(productId int, input domain.SetProductAttributesInput) error
+}
+
+type ProductImage interface {
+	AddImage(productId int, input domain.AddProductImageInput, file multipart.File) (int, error)
+	UpdateImage(productId, imageId int, input domain.UpdateProductImageInput) error
+	ReorderImages(productId int, input domain.ReorderProductImagesInput) error
+	SetPrimaryImage(productId, imageId int) error
+	DeleteImage(productId, imageId int) error
+}
+
+type Variant interface {
+	SetOptions(productId int, input domain.SetProductOptionsInput) error
+	GetFilePath(variantId int, fileName string) string
+	CreateVariant(productId int, input domain.CreateVariantInput, file multipart.File) (int, error)
+	UpdateVariant(productId, variantId int, input domain.UpdateVariantInput, file multipart.File) error
+	DeleteVariant(productId, variantId int) error
 }
 
 type Profile interface {
 	GetProfile(userId int) (domain.User, error)
 	GetFilePath(userId int, fileName string) string
 	UpdateProfile(userId int, input domain.UpdateProfileInput, file multipart.File) error
-	CreateOrder(userId int, products []domain.CreateOrderInputProduct) (int, error)
-	GetAllOrders(userId, limit, offset int) ([]domain.Order, error)
+	CreateOrder(userId int, input domain.CreateOrderInput) (int, error)
+	GetAllOrders(userId int, page domain.PageRequest) ([]domain.Order, domain.Pagination, error)
 	GetOrderById(userId, orderId int) (domain.Order, error)
 	DeleteProfile(userId int, password string) error
 }
 
+type Collection interface {
+	GetAll(page domain.PageRequest) ([]domain.Collection, domain.Pagination, error)
+	GetById(id int) (domain.Collection, error)
+	GetProducts(collectionId int, page domain.PageRequest) ([]domain.Product, domain.Pagination, error)
+	CreateCollection(input domain.CreateCollectionInput) (int, error)
+	UpdateCollection(id int, input domain.UpdateCollectionInput) error
+	DeleteCollection(id int) error
+	SetProducts(collectionId int, input domain.SetCollectionProductsInput) error
+}
+
+type Review interface {
+	GetProductReviews(productId int, page domain.PageRequest) ([]domain.Review, domain.Pagination, error)
+	GetUserReviews(userId int, page domain.PageRequest) ([]domain.Review, domain.Pagination, error)
+	AdminGetAll(page domain.PageRequest, filter domain.AdminReviewFilterParams) ([]domain.Review, domain.Pagination, error)
+	CreateReview(userId int, input domain.CreateReviewInput) (int, error)
+	UpdateReview(userId, reviewId int, input domain.UpdateReviewInput) error
+	DeleteReview(userId, reviewId int) error
+	ApproveReview(reviewId int) error
+	RejectReview(reviewId int) error
+	ReportReview(userId, reviewId int, input domain.ReportReviewInput) error
+}
+
+type Wishlist interface {
+	GetItems(userId int, page domain.PageRequest) ([]domain.WishlistItem, domain.Pagination, error)
+	AddItem(userId int, input domain.AddWishlistItemInput) error
+	RemoveItem(userId, productId int) error
+	CreateOrder(userId int, input domain.CreateOrderInput) (int, error)
+	NotifyChanges() (int, error)
+}
+
+type Related interface {
+	Refresh() (int, error)
+	GetRelated(productId int) ([]domain.Product, error)
+}
+
+type Currency interface {
+	GetRates() ([]domain.ExchangeRate, error)
+	GetRate(currency string) (domain.ExchangeRate, error)
+	SetRates(input domain.SetExchangeRatesInput) error
+	ImportRates(file io.Reader) error
+	DeleteRate(currency string) error
+}
+
+type Tax interface {
+	GetClasses() ([]domain.TaxClass, error)
+	CreateClass(input domain.TaxClassInput) (int, error)
+	UpdateClass(id int, input domain.TaxClassInput) error
+	DeleteClass(id int) error
+	SetProductTaxClass(productId int, input domain.SetTaxClassInput) error
+	SetCategoryTaxClass(categoryId int, input domain.SetTaxClassInput) error
+}
+
+type Promo interface {
+	GetAll(page domain.PageRequest) ([]domain.PromoCode, domain.Pagination, error)
+	GetById(id int) (domain.PromoCode, error)
+	CreatePromoCode(input domain.PromoCodeInput) (int, error)
+	UpdatePromoCode(id int, input domain.PromoCodeInput) error
+	DeletePromoCode(id int) error
+}
+
+type Sale interface {
+	GetAll(page domain.PageRequest, filter domain.PriceScheduleFilterParams) ([]domain.PriceSchedule, domain.Pagination, error)
+	GetById(id int) (domain.PriceSchedule, error)
+	CreateSchedule(input domain.PriceScheduleInput) (int, error)
+	CancelSchedule(id int) error
+	Run() (domain.PriceScheduleRun, error)
+}
+
+type Search interface {
+	Suggest(params domain.SuggestParams) (domain.Suggestions, error)
+	RecordQuery(query string) error
+}
+
+type Import interface {
+	ImportProducts(input domain.ImportProductsInput, file multipart.File) (int, error)
+	GetJob(id int) (domain.ImportJob, error)
+	ExpireJobs() (int, error)
+	ExportProducts(format string, w io.Writer) error
+}
+
+type Media interface {
+	CollectGarbage(dryRun bool) ([]string, error)
+	ResetData() error
+}
+
+type Health interface {
+	Check() (domain.Health, error)
+}
+
 type Service struct {
 	Authorization
 	Category
 	Product
+	Attribute
+	ProductImage
+	Variant
 	Profile
+	Collection
+	Review
+	Wishlist
+	Related
+	Currency
+	Tax
+	Promo
+	Sale
+	Search
+	Import
+	Media
+	Health
 }
 
-func NewService(repos *repository.Repository) *Service {
+// NewService wires the services, it fails when their configuration can not
+// be loaded.
+func NewService(repos *repository.Repository) (*Service, error) {
+	review, err := newReviewService(repos.Review, repos.Product)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Service{
 		Authorization: newAuthService(repos.Authorization),
 		Category:      newCategoryService(repos.Category),
-		Product:       newProductService(repos.Product),
-		Profile:       newProfileService(repos.Profile),
-	}
+		Product:       newProductService(repos.Product, repos.Category, repos.Attribute, repos.ProductImage, repos.Variant),
+		Attribute:     newAttributeService(repos.Attribute),
+		ProductImage:  newProductImageService(repos.ProductImage),
+		Variant:       newVariantService(repos.Variant),
+		Profile:       newProfileService(repos.Profile, repos.Currency),
+		Collection:    newCollectionService(repos.Collection),
+		Review:        review,
+		Wishlist:      newWishlistService(repos.Wishlist, repos.Currency),
+		Related:       newRelatedService(repos.Related),
+		Currency:      newCurrencyService(repos.Currency),
+		Tax:           newTaxService(repos.Tax),
+		Promo:         newPromoService(repos.Promo),
+		Sale:          newSaleService(repos.Sale),
+		Search:        newSearchService(repos.Search),
+		Import:        newImportService(repos.Import),
+		Media:         newMediaService(repos.Media),
+		Health:        newHealthService(repos.Schema),
+	}, nil
 }
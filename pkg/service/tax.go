@@ -0,0 +1,93 @@
+package service
+
+import (
+	"os"
+	"strings"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+// pricesIncludeTax tells whether the catalog prices include the tax, set
+// with TAX_PRICES_INCLUDE_TAX. Otherwise the tax is added to them.
+func pricesIncludeTax() bool {
+	return os.Getenv("TAX_PRICES_INCLUDE_TAX") == "true"
+}
+
+// newCheckout prices an order: it is converted to the currency of the input,
+// discounted by its promo code and taxed for its region, the region set with
+// TAX_DEFAULT_REGION when the input has none.
+func newCheckout(currencyRepo repository.Currency, input domain.CreateOrderInput) (domain.Checkout, error) {
+	rate, err := exchangeRate(currencyRepo, input.Currency)
+	if err != nil {
+		return domain.Checkout{}, err
+	}
+	region := input.TaxRegion
+	if region == "" {
+		region = strings.ToUpper(strings.TrimSpace(os.Getenv("TAX_DEFAULT_REGION")))
+	}
+	return domain.Checkout{
+		ExchangeRate:     rate,
+		TaxRegion:        region,
+		PricesIncludeTax: pricesIncludeTax(),
+		PromoCode:        normalizePromoCode(input.PromoCode),
+	}, nil
+}
+
+type TaxService struct {
+	repo repository.Tax
+}
+
+func newTaxService(repo repository.Tax) *TaxService {
+	return &TaxService{repo}
+}
+
+func (s *TaxService) GetClasses() ([]domain.TaxClass, error) {
+	return s.repo.GetClasses()
+}
+
+func (s *TaxService) CreateClass(input domain.TaxClassInput) (int, error) {
+	id, err := s.repo.CreateClass(input)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return id, errors_handler.BadRequest("tax class with such name already exists")
+	}
+	return id, err
+}
+
+func (s *TaxService) UpdateClass(id int, input domain.TaxClassInput) error {
+	err := s.repo.UpdateClass(id, input)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("tax class")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return errors_handler.BadRequest("tax class with such name already exists")
+	}
+	return err
+}
+
+func (s *TaxService) DeleteClass(id int) error {
+	err := s.repo.DeleteClass(id)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("tax class")
+	}
+	return err
+}
+
+func (s *TaxService) SetProductTaxClass(productId int, input domain.SetTaxClassInput) error {
+	return taxClassAssignmentError(s.repo.SetProductTaxClass(productId, input.TaxClassId), "product")
+}
+
+func (s *TaxService) SetCategoryTaxClass(categoryId int, input domain.SetTaxClassInput) error {
+	return taxClassAssignmentError(s.repo.SetCategoryTaxClass(categoryId, input.TaxClassId), "category")
+}
+
+func taxClassAssignmentError(err error, entity string) error {
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound(entity)
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return errors_handler.BadRequest("provided tax_class_id does not correspond to an existing tax class")
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"mime/multipart"
+	"strings"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+)
+
+type VariantService struct {
+	repo repository.Variant
+}
+
+func newVariantService(repo repository.Variant) *VariantService {
+	return &VariantService{repo}
+}
+
+// SetOptions replaces the option types of a product. It is refused while the
+// product has variants that do not match the new option types.
+func (s *VariantService) SetOptions(productId int, input domain.SetProductOptionsInput) error {
+	variants, err := s.repo.GetVariants(productId, false)
+	if err != nil {
+		return err
+	}
+	for _, variant := range variants {
+		if !variant.Options.Matches(input.Options) {
+			return errors_handler.BadRequest(fmt.Sprintf("variant %s does not match the new options", variant.Sku))
+		}
+	}
+
+	err = s.repo.SetOptions(productId, input.Options)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("product")
+	}
+	return err
+}
+
+func (s *VariantService) GetFilePath(variantId int, fileName string) string {
+	return s.repo.GetFilePath(variantId, fileName)
+}
+
+func (s *VariantService) CreateVariant(productId int, input domain.CreateVariantInput, file multipart.File) (int, error) {
+	if err := s.checkOptions(productId, input.Options); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.CreateVariant(productId, input, file)
+	return id, variantError(err)
+}
+
+func (s *VariantService) UpdateVariant(productId, variantId int, input domain.UpdateVariantInput, file multipart.File) error {
+	if input.Options != nil {
+		if err := s.checkOptions(productId, input.Options); err != nil {
+			return err
+		}
+	}
+
+	err := s.repo.UpdateVariant(productId, variantId, input, file)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("variant")
+	}
+	return variantError(err)
+}
+
+func (s *VariantService) DeleteVariant(productId, variantId int) error {
+	err := s.repo.DeleteVariant(productId, variantId)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("variant")
+	}
+	return err
+}
+
+// checkOptions verifies that the options have a value for each option type
+// of the product.
+func (s *VariantService) checkOptions(productId int, options domain.VariantOptions) error {
+	optionTypes, err := s.repo.GetOptions(productId)
+	if err != nil {
+		return err
+	}
+	if len(optionTypes) == 0 {
+		return errors_handler.BadRequest("the product has no options, set them before adding variants")
+	}
+	if !options.Matches(optionTypes) {
+		return errors_handler.BadRequest(fmt.Sprintf("options must have a value for each of: %s", strings.Join(optionTypes, ", ")))
+	}
+	return nil
+}
+
+func variantError(err error) error {
+	if errors_handler.ErrorIsType(err, errors_handler.TypeAlreadyExists) {
+		return errors_handler.BadRequest("variant with such sku already exists")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeForeignKeyViolation) {
+		return errors_handler.NotFound("product")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeConstrainViolation) {
+		return errors_handler.BadRequest("undiscounted_price must not be lower than price")
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/renlin-code/mock-shop-api/pkg/domain"
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+	"github.com/renlin-code/mock-shop-api/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type WishlistService struct {
+	repo         repository.Wishlist
+	currencyRepo repository.Currency
+}
+
+func newWishlistService(repo repository.Wishlist, currencyRepo repository.Currency) *WishlistService {
+	return &WishlistService{repo: repo, currencyRepo: currencyRepo}
+}
+
+func (s *WishlistService) GetItems(userId int, page domain.PageRequest) ([]domain.WishlistItem, domain.Pagination, error) {
+	return s.repo.GetItems(userId, page)
+}
+
+func (s *WishlistService) AddItem(userId int, input domain.AddWishlistItemInput) error {
+	err := s.repo.AddItem(userId, input)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("product")
+	}
+	return err
+}
+
+func (s *WishlistService) RemoveItem(userId, productId int) error {
+	err := s.repo.RemoveItem(userId, productId)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return errors_handler.NotFound("wishlist item")
+	}
+	return err
+}
+
+// CreateOrder places an order in the currency of the input, its prices are
+// converted with the current exchange rate and taxed for its region.
+func (s *WishlistService) CreateOrder(userId int, input domain.CreateOrderInput) (int, error) {
+	checkout, err := newCheckout(s.currencyRepo, input)
+	if err != nil {
+		return 0, err
+	}
+	id, err := s.repo.CreateOrder(userId, checkout, input.Products)
+	if errors_handler.ErrorIsType(err, errors_handler.TypeNoRows) {
+		return id, errors_handler.NotFound("product or variant")
+	}
+	if errors_handler.ErrorIsType(err, errors_handler.TypeConstrainViolation) {
+		return id, errors_handler.BadRequest("quantity exceeds the stock")
+	}
+	return id, err
+}
+
+// NotifyChanges emails the users the price drops and back in stock changes
+// of their wishlisted products, one email per user. A failed email is
+// logged and not sent again. It returns the number of emails sent.
+func (s *WishlistService) NotifyChanges() (int, error) {
+	alerts, err := s.repo.TakeAlerts()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for start := 0; start < len(alerts); {
+		end := start
+		for end < len(alerts) && alerts[end].UserId == alerts[start].UserId {
+			end++
+		}
+		userAlerts := alerts[start:end]
+		start = end
+
+		if err := sendWishlistMail(userAlerts); err != nil {
+			logrus.Errorf("failed to send wishlist alerts to user %d: %s", userAlerts[0].UserId, err.Error())
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func sendWishlistMail(alerts []domain.WishlistAlert) error {
+	const emailSubject = "News about your wishlist"
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi %s, some products of your wishlist changed:\n\n", alerts[0].UserName)
+	for _, alert := range alerts {
+		switch {
+		case alert.PriceDropped() && alert.BackInStock:
+			fmt.Fprintf(&body, "- %s is back in stock and its price dropped from %s to %s\n", alert.ProductName, alert.PreviousPrice, alert.Price)
+		case alert.PriceDropped():
+			fmt.Fprintf(&body, "- %s price dropped from %s to %s\n", alert.ProductName, alert.PreviousPrice, alert.Price)
+		default:
+			fmt.Fprintf(&body, "- %s is back in stock\n", alert.ProductName)
+		}
+	}
+	if clientUrl := os.Getenv("CLIENT_WISHLIST_PAGE"); clientUrl != "" {
+		fmt.Fprintf(&body, "\nSee your wishlist: %s", clientUrl)
+	}
+
+	return sendMail([]string{alerts[0].UserEmail}, emailSubject, body.String())
+}
@@ -17,4 +17,15 @@ const (
 	usersDirectory      = "users"
 	categoriesDirectory = "categories"
 	productsDirectory   = "products"
+	variantsDirectory   = "variants"
+)
+
+// MediaKind is the name of a media directory holding one sub-directory per owner.
+type MediaKind string
+
+const (
+	UsersMedia      MediaKind = usersDirectory
+	CategoriesMedia MediaKind = categoriesDirectory
+	ProductsMedia   MediaKind = productsDirectory
+	VariantsMedia   MediaKind = variantsDirectory
 )
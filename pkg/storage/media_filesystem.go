@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+type MediaFileSystem struct {
+	FileSystem *FileSystemStorage
+}
+
+func newMediaFileSystem(fs *FileSystemStorage) *MediaFileSystem {
+	return &MediaFileSystem{FileSystem: fs}
+}
+
+func (s *MediaFileSystem) ListOwners(kind MediaKind) ([]int, error) {
+	entries, err := os.ReadDir(fmt.Sprintf(".%s/%s", basePath, kind))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors_handler.StorageError("error reading directory")
+	}
+
+	ids := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		id, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *MediaFileSystem) DeleteOwner(kind MediaKind, ownerId int) error {
+	ownerDir := fmt.Sprintf("%s/%s/%d/", basePath, kind, ownerId)
+
+	err := os.RemoveAll("." + ownerDir)
+	if err != nil {
+		return errors_handler.StorageError("error deleting directory")
+	}
+	return nil
+}
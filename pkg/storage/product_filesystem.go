@@ -5,6 +5,7 @@ import (
 	"io"
 	"mime/multipart"
 	"os"
+	"time"
 
 	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
 )
@@ -17,20 +18,42 @@ func newProductFileSystem(fs *FileSystemStorage) *ProductFileSystem {
 	return &ProductFileSystem{FileSystem: fs}
 }
 
+// UploadProductImage stores the primary image of a product. The other images
+// of the product gallery are kept.
 func (s *ProductFileSystem) UploadProductImage(productId int, handler *multipart.FileHeader, file multipart.File) (string, error) {
-	productDir := fmt.Sprintf("%s/%s/%d/", basePath, productsDirectory, productId)
+	return s.writeProductImage(productId, handler.Filename, file)
+}
 
-	err := os.RemoveAll("." + productDir)
-	if err != nil {
-		return "", errors_handler.StorageError("error deleting directory")
+// AddProductImage stores a gallery image under a unique name, so images with
+// the same file name do not overwrite each other. It returns the stored file
+// name and its url.
+func (s *ProductFileSystem) AddProductImage(productId int, handler *multipart.FileHeader, file multipart.File) (string, string, error) {
+	fileName := fmt.Sprintf("%x-%s", time.Now().UnixNano(), handler.Filename)
+	url, err := s.writeProductImage(productId, fileName, file)
+	return fileName, url, err
+}
+
+func (s *ProductFileSystem) DeleteProductImage(productId int, fileName string) error {
+	err := os.Remove("." + s.GetFilePath(productId, fileName))
+	if err != nil && !os.IsNotExist(err) {
+		return errors_handler.StorageError("error deleting file")
 	}
+	return nil
+}
+
+func (s *ProductFileSystem) GetFilePath(productId int, fileName string) string {
+	return fmt.Sprintf("%s/%s/%d/%s", basePath, productsDirectory, productId, fileName)
+}
+
+func (s *ProductFileSystem) writeProductImage(productId int, fileName string, file multipart.File) (string, error) {
+	productDir := fmt.Sprintf("%s/%s/%d/", basePath, productsDirectory, productId)
 
-	err = os.MkdirAll("."+productDir, os.ModePerm)
+	err := os.MkdirAll("."+productDir, os.ModePerm)
 	if err != nil {
 		return "", errors_handler.StorageError("error creating directory")
 	}
 
-	path := productDir + handler.Filename
+	path := productDir + fileName
 	f, err := os.Create("." + path)
 	if err != nil {
 		return "", errors_handler.StorageError("error creating file")
@@ -39,9 +62,5 @@ func (s *ProductFileSystem) UploadProductImage(productId int, handler *multipart
 
 	io.Copy(f, file)
 
-	return fmt.Sprintf("%s/%s/%d/%s", s.FileSystem.config.MediaBaseUrl, productsDirectory, productId, handler.Filename), nil
-}
-
-func (s *ProductFileSystem) GetFilePath(productId int, fileName string) string {
-	return fmt.Sprintf("%s/%s/%d/%s", basePath, productsDirectory, productId, fileName)
+	return fmt.Sprintf("%s/%s/%d/%s", s.FileSystem.config.MediaBaseUrl, productsDirectory, productId, fileName), nil
 }
@@ -17,13 +17,27 @@ type Category interface {
 
 type Product interface {
 	UploadProductImage(productId int, handler *multipart.FileHeader, file multipart.File) (string, error)
+	AddProductImage(productId int, handler *multipart.FileHeader, file multipart.File) (string, string, error)
+	DeleteProductImage(productId int, fileName string) error
 	GetFilePath(productId int, fileName string) string
 }
 
+type Variant interface {
+	UploadVariantImage(variantId int, handler *multipart.FileHeader, file multipart.File) (string, error)
+	GetFilePath(variantId int, fileName string) string
+}
+
+type Media interface {
+	ListOwners(kind MediaKind) ([]int, error)
+	DeleteOwner(kind MediaKind, ownerId int) error
+}
+
 type Storage struct {
 	Profile
 	Category
 	Product
+	Variant
+	Media
 }
 
 func NewStorage(fs *FileSystemStorage) *Storage {
@@ -31,5 +45,7 @@ func NewStorage(fs *FileSystemStorage) *Storage {
 		Profile:  newProfileFileSystem(fs),
 		Category: newCategoryFileSystem(fs),
 		Product:  newProductFileSystem(fs),
+		Variant:  newVariantFileSystem(fs),
+		Media:    newMediaFileSystem(fs),
 	}
 }
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+
+	"github.com/renlin-code/mock-shop-api/pkg/errors_handler"
+)
+
+type VariantFileSystem struct {
+	FileSystem *FileSystemStorage
+}
+
+func newVariantFileSystem(fs *FileSystemStorage) *VariantFileSystem {
+	return &VariantFileSystem{FileSystem: fs}
+}
+
+func (s *VariantFileSystem) UploadVariantImage(variantId int, handler *multipart.FileHeader, file multipart.File) (string, error) {
+	variantDir := fmt.Sprintf("%s/%s/%d/", basePath, variantsDirectory, variantId)
+
+	err := os.RemoveAll("." + variantDir)
+	if err != nil {
+		return "", errors_handler.StorageError("error deleting directory")
+	}
+
+	err = os.MkdirAll("."+variantDir, os.ModePerm)
+	if err != nil {
+		return "", errors_handler.StorageError("error creating directory")
+	}
+
+	path := variantDir + handler.Filename
+	f, err := os.Create("." + path)
+	if err != nil {
+		return "", errors_handler.StorageError("error creating file")
+	}
+	defer f.Close()
+
+	io.Copy(f, file)
+
+	return fmt.Sprintf("%s/%s/%d/%s", s.FileSystem.config.MediaBaseUrl, variantsDirectory, variantId, handler.Filename), nil
+}
+
+func (s *VariantFileSystem) GetFilePath(variantId int, fileName string) string {
+	return fmt.Sprintf("%s/%s/%d/%s", basePath, variantsDirectory, variantId, fileName)
+}
@@ -0,0 +1,9 @@
+ALTER TABLE ordered_products DROP COLUMN IF EXISTS variant_options;
+
+ALTER TABLE ordered_products DROP COLUMN IF EXISTS sku;
+
+ALTER TABLE ordered_products DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS product_variants;
+
+DROP TABLE IF EXISTS product_options;
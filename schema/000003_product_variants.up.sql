@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS product_options (
+    id SERIAL NOT NULL UNIQUE,
+    product_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    position INT NOT NULL,
+    UNIQUE (product_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL NOT NULL UNIQUE,
+    product_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    options JSONB NOT NULL,
+    price NUMERIC(12, 2) NOT NULL CHECK (price >= 0),
+    undiscounted_price NUMERIC(12, 2) NOT NULL CHECK (undiscounted_price >= price),
+    image_url VARCHAR(255) NOT NULL,
+    available BOOLEAN NOT NULL,
+    stock INT NOT NULL CHECK (stock >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS product_variants_product_id_idx ON product_variants (product_id);
+
+ALTER TABLE ordered_products ADD COLUMN IF NOT EXISTS variant_id INT REFERENCES product_variants(id) ON DELETE SET NULL;
+
+ALTER TABLE ordered_products ADD COLUMN IF NOT EXISTS sku VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE ordered_products ADD COLUMN IF NOT EXISTS variant_options JSONB NOT NULL DEFAULT '{}';
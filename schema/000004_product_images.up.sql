@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS product_images (
+    id SERIAL NOT NULL UNIQUE,
+    product_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    alt_text VARCHAR(255) NOT NULL DEFAULT '',
+    position INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS product_images_product_id_idx ON product_images (product_id, position);
+
+INSERT INTO product_images (product_id, file_name, url, position)
+    SELECT id, regexp_replace(image_url, '^.*/', ''), image_url, 0
+    FROM products
+    WHERE image_url <> '';
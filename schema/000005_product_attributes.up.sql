@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS attributes (
+    id SERIAL NOT NULL UNIQUE,
+    category_id INT REFERENCES categories(id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('text', 'number', 'boolean')),
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    UNIQUE (category_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS product_attributes (
+    product_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    attribute_id INT REFERENCES attributes(id) ON DELETE CASCADE NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    PRIMARY KEY (product_id, attribute_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_attributes_attribute_id_value_idx ON product_attributes (attribute_id, value);
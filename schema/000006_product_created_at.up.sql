@@ -0,0 +1,5 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS products_price_idx ON products (price);
+
+CREATE INDEX IF NOT EXISTS products_created_at_idx ON products (created_at);
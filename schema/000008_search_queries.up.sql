@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS search_queries (
+    query VARCHAR(100) PRIMARY KEY,
+    count INT NOT NULL DEFAULT 1,
+    searched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS search_queries_query_prefix_idx ON search_queries (query text_pattern_ops);
@@ -0,0 +1,5 @@
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES categories(id) ON DELETE SET NULL;
+
+ALTER TABLE categories ADD CONSTRAINT categories_parent_id_check CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS collection_products;
+
+DROP TABLE IF EXISTS collections;
+
+DROP TABLE IF EXISTS product_categories;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    category_id INT REFERENCES categories(id) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_categories_category_id_idx ON product_categories (category_id);
+
+CREATE TABLE IF NOT EXISTS collections (
+    id SERIAL NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    available BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS collection_products (
+    collection_id INT REFERENCES collections(id) ON DELETE CASCADE NOT NULL,
+    product_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    position INT NOT NULL,
+    PRIMARY KEY (collection_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS collection_products_position_idx ON collection_products (collection_id, position);
@@ -0,0 +1,6 @@
+ALTER TABLE ordered_products DROP CONSTRAINT IF EXISTS ordered_products_product_id_fkey;
+ALTER TABLE ordered_products ADD CONSTRAINT ordered_products_product_id_fkey FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+
+ALTER TABLE products DROP COLUMN IF EXISTS archived_at;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,6 @@
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE ordered_products DROP CONSTRAINT IF EXISTS ordered_products_product_id_fkey;
+ALTER TABLE ordered_products ADD CONSTRAINT ordered_products_product_id_fkey FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT;
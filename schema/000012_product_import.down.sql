@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS import_jobs;
+
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
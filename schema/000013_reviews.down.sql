@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS products_rating_average_idx;
+
+ALTER TABLE products DROP COLUMN IF EXISTS rating_count;
+
+ALTER TABLE products DROP COLUMN IF EXISTS rating_average;
+
+DROP TABLE IF EXISTS reviews;
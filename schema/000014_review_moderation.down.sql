@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS review_reports;
+
+DROP INDEX IF EXISTS reviews_status_idx;
+
+ALTER TABLE reviews DROP COLUMN IF EXISTS moderated_at;
+
+ALTER TABLE reviews DROP COLUMN IF EXISTS status;
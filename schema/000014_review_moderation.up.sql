@@ -0,0 +1,13 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'approved' CHECK (status IN ('pending', 'approved', 'rejected'));
+
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS reviews_status_idx ON reviews (status, id);
+
+CREATE TABLE IF NOT EXISTS review_reports (
+    review_id INT REFERENCES reviews(id) ON DELETE CASCADE NOT NULL,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    reason VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (review_id, user_id)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    product_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    notify BOOLEAN NOT NULL DEFAULT false,
+    notified_price NUMERIC(12, 2) NOT NULL,
+    notified_in_stock BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS wishlist_items_product_id_idx ON wishlist_items (product_id) WHERE notify;
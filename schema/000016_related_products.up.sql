@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS related_products (
+    product_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    related_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    score INT NOT NULL CHECK (score > 0),
+    PRIMARY KEY (product_id, related_id)
+);
+
+CREATE INDEX IF NOT EXISTS related_products_product_id_score_idx ON related_products (product_id, score DESC);
@@ -0,0 +1,5 @@
+-- The orders placed before this migration are assumed to be in USD, the
+-- default store currency. The default only backfills them: the currency of a
+-- new order is always written by the app, from APP_CURRENCY.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE orders ALTER COLUMN currency DROP DEFAULT;
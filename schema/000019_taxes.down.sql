@@ -0,0 +1,14 @@
+ALTER TABLE ordered_products DROP COLUMN IF EXISTS net_amount;
+ALTER TABLE ordered_products DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE ordered_products DROP COLUMN IF EXISTS tax_rate;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS prices_include_tax;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_region;
+
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class_id;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_class_id;
+
+DROP TABLE IF EXISTS tax_rates;
+
+DROP TABLE IF EXISTS tax_classes;
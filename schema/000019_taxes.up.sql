@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS tax_classes (
+    id SERIAL NOT NULL UNIQUE,
+    name VARCHAR(50) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS tax_rates (
+    tax_class_id INT REFERENCES tax_classes(id) ON DELETE CASCADE NOT NULL,
+    region VARCHAR(6) NOT NULL,
+    rate NUMERIC(7, 4) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    PRIMARY KEY (tax_class_id, region)
+);
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_class_id INT REFERENCES tax_classes(id) ON DELETE SET NULL;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class_id INT REFERENCES tax_classes(id) ON DELETE SET NULL;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_region VARCHAR(6) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS prices_include_tax BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE ordered_products ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(7, 4) NOT NULL DEFAULT 0;
+ALTER TABLE ordered_products ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE ordered_products ADD COLUMN IF NOT EXISTS net_amount NUMERIC(12, 2);
+UPDATE ordered_products SET net_amount = price * quantity WHERE net_amount IS NULL;
+ALTER TABLE ordered_products ALTER COLUMN net_amount SET NOT NULL;
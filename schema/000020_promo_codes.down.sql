@@ -0,0 +1,13 @@
+ALTER TABLE ordered_products DROP COLUMN IF EXISTS discount_amount;
+
+DROP INDEX IF EXISTS orders_promo_code_id_idx;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS promo_code;
+ALTER TABLE orders DROP COLUMN IF EXISTS promo_code_id;
+
+DROP TABLE IF EXISTS promo_code_categories;
+
+DROP TABLE IF EXISTS promo_code_products;
+
+DROP TABLE IF EXISTS promo_codes;
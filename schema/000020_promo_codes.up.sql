@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id SERIAL NOT NULL UNIQUE,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    percent INT CHECK (percent > 0 AND percent <= 100),
+    amount NUMERIC(12, 2) CHECK (amount > 0),
+    min_order_value NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    usage_limit INT,
+    per_user_limit INT,
+    used_count INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CHECK ((percent IS NULL) <> (amount IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS promo_code_products (
+    promo_code_id INT REFERENCES promo_codes(id) ON DELETE CASCADE NOT NULL,
+    product_id INT REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (promo_code_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS promo_code_categories (
+    promo_code_id INT REFERENCES promo_codes(id) ON DELETE CASCADE NOT NULL,
+    category_id INT REFERENCES categories(id) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (promo_code_id, category_id)
+);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promo_code_id INT REFERENCES promo_codes(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promo_code VARCHAR(50);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS orders_promo_code_id_idx ON orders (promo_code_id, user_id) WHERE promo_code_id IS NOT NULL;
+
+ALTER TABLE ordered_products ADD COLUMN IF NOT EXISTS discount_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+UPDATE product_variants SET price = regular_price, undiscounted_price = GREATEST(undiscounted_price, regular_price)
+    WHERE regular_price IS NOT NULL AND price = sale_price;
+UPDATE products SET price = regular_price, undiscounted_price = GREATEST(undiscounted_price, regular_price)
+    WHERE regular_price IS NOT NULL AND price = sale_price;
+
+DROP INDEX IF EXISTS product_variants_sale_id_idx;
+DROP INDEX IF EXISTS products_sale_id_idx;
+
+ALTER TABLE product_variants DROP COLUMN IF EXISTS sale_price;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS regular_price;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS sale_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS sale_price;
+ALTER TABLE products DROP COLUMN IF EXISTS regular_price;
+ALTER TABLE products DROP COLUMN IF EXISTS sale_id;
+
+DROP INDEX IF EXISTS price_schedules_status_idx;
+
+DROP TABLE IF EXISTS price_schedules;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS price_schedules (
+    id SERIAL NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    product_id INT REFERENCES products(id) ON DELETE CASCADE,
+    category_id INT REFERENCES categories(id) ON DELETE CASCADE,
+    percent INT NOT NULL CHECK (percent > 0 AND percent < 100),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CHECK ((product_id IS NULL) <> (category_id IS NULL)),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS price_schedules_status_idx ON price_schedules (status, starts_at);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_id INT REFERENCES price_schedules(id) ON DELETE SET NULL;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS regular_price NUMERIC(12, 2);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_price NUMERIC(12, 2);
+
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS sale_id INT REFERENCES price_schedules(id) ON DELETE SET NULL;
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS regular_price NUMERIC(12, 2);
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS sale_price NUMERIC(12, 2);
+
+CREATE INDEX IF NOT EXISTS products_sale_id_idx ON products (sale_id) WHERE regular_price IS NOT NULL;
+CREATE INDEX IF NOT EXISTS product_variants_sale_id_idx ON product_variants (sale_id) WHERE regular_price IS NOT NULL;
@@ -0,0 +1,9 @@
+// Package schema embeds the database migrations into the binary.
+package schema
+
+import "embed"
+
+// Migrations holds the *.up.sql and *.down.sql migration files.
+//
+//go:embed *.sql
+var Migrations embed.FS